@@ -0,0 +1,330 @@
+// Package advisory matches dependencies against a local copy of an OSV advisory database
+// (https://ossf.github.io/osv-schema/), such as a directory of OSV JSON files or the all.zip
+// export of an ecosystem from osv.dev. Nothing is fetched over the network.
+package advisory
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	EcosystemGo       = "Go"
+	EcosystemRubyGems = "RubyGems"
+)
+
+var ErrUnsupportedDatabase = errors.New("advisory database must be a directory or a .zip file")
+
+// Advisory is an advisory affecting the version of a dependency.
+type Advisory struct {
+	ID            string
+	Severity      string   // e.g. HIGH, from the database or the CVSS v3 vector; empty when unknown
+	FixedVersions []string // the versions that fix it, none when there is no fix yet
+}
+
+// Database holds the affected packages of the loaded advisories by ecosystem and package name.
+type Database struct {
+	packages map[packageKey][]affectedPackage
+}
+
+type packageKey struct {
+	ecosystem string
+	name      string
+}
+
+type affectedPackage struct {
+	id       string
+	severity string
+	ranges   []osvRange
+	versions []string
+}
+
+type osvEntry struct {
+	ID               string        `json:"id"`
+	Withdrawn        string        `json:"withdrawn"`
+	Severity         []osvSeverity `json:"severity"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Severity []osvSeverity `json:"severity"`
+	Ranges   []osvRange    `json:"ranges"`
+	Versions []string      `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"` // SEMVER, ECOSYSTEM or GIT
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+// Load reads the OSV JSON files of a directory, searched recursively, or of a zip export.
+// Only the packages of the given ecosystems are kept. Withdrawn advisories and files that are
+// not OSV entries are left out.
+func Load(path string, ecosystems ...string) (*Database, error) {
+	database := &Database{packages: map[packageKey][]affectedPackage{}}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open advisory database: %w", err)
+	}
+
+	switch {
+	case info.IsDir():
+		err = database.loadDir(path, ecosystems)
+	case strings.EqualFold(filepath.Ext(path), ".zip"):
+		err = database.loadZip(path, ecosystems)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatabase, path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	utils.DebugPrintln(fmt.Sprintf("Loaded advisories for %d packages from %s", len(database.packages), path))
+
+	return database, nil
+}
+
+func (d *Database) loadDir(dir string, ecosystems []string) error {
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open advisory %s: %w", path, err)
+		}
+		defer file.Close()
+
+		d.add(path, file, ecosystems)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read advisory database: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Database) loadZip(path string, ecosystems []string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open advisory database: %w", err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || filepath.Ext(file.Name) != ".json" {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read advisory %s: %w", file.Name, err)
+		}
+
+		d.add(file.Name, reader, ecosystems)
+		reader.Close()
+	}
+
+	return nil
+}
+
+// add indexes the affected packages of one OSV file.
+func (d *Database) add(name string, reader io.Reader, ecosystems []string) {
+	var entry osvEntry
+
+	err := json.NewDecoder(reader).Decode(&entry)
+	if err != nil || entry.ID == "" {
+		utils.DebugPrintln("Skipping " + name + ": not an OSV advisory")
+
+		return
+	}
+
+	if entry.Withdrawn != "" {
+		return
+	}
+
+	for _, affected := range entry.Affected {
+		if !slices.Contains(ecosystems, affected.Package.Ecosystem) {
+			continue
+		}
+
+		key := packageKey{ecosystem: affected.Package.Ecosystem, name: affected.Package.Name}
+		d.packages[key] = append(d.packages[key], affectedPackage{
+			id:       entry.ID,
+			severity: severity(entry, affected),
+			ranges:   affected.Ranges,
+			versions: affected.Versions,
+		})
+	}
+}
+
+// Match returns the advisories affecting version of the package, sorted by ID. It is empty, not nil,
+// when none does.
+func (d *Database) Match(ecosystem, name, version string) []Advisory {
+	compare := versionComparer(ecosystem)
+	advisories := []Advisory{}
+
+	for _, affected := range d.packages[packageKey{ecosystem: ecosystem, name: name}] {
+		if !affected.affects(version, compare) ||
+			slices.ContainsFunc(advisories, func(a Advisory) bool { return a.ID == affected.id }) {
+			continue
+		}
+
+		advisories = append(advisories, Advisory{
+			ID:            affected.id,
+			Severity:      affected.severity,
+			FixedVersions: affected.fixedVersions(ecosystem),
+		})
+	}
+
+	slices.SortFunc(advisories, func(a, b Advisory) int { return strings.Compare(a.ID, b.ID) })
+
+	return advisories
+}
+
+// affects tells whether version is listed or falls in a range of the package. Git ranges name
+// commits, not versions, and are left out.
+func (p affectedPackage) affects(version string, compare func(a, b string) int) bool {
+	if slices.ContainsFunc(p.versions, func(listed string) bool { return compare(listed, version) == 0 }) {
+		return true
+	}
+
+	for _, versionRange := range p.ranges {
+		switch versionRange.Type {
+		case "SEMVER":
+			if inRange(version, versionRange.Events, compareSemver) {
+				return true
+			}
+		case "ECOSYSTEM":
+			if inRange(version, versionRange.Events, compare) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inRange evaluates the events of a range in version order as the OSV schema describes:
+// the last event at or below version decides whether it is affected.
+func inRange(version string, events []osvEvent, compare func(a, b string) int) bool {
+	sorted := slices.SortedFunc(slices.Values(events), func(a, b osvEvent) int {
+		return compareEventVersions(a.version(), b.version(), compare)
+	})
+
+	affected := false
+
+	for _, event := range sorted {
+		switch {
+		case event.Introduced != "":
+			if compareEventVersions(version, event.Introduced, compare) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if compare(version, event.Fixed) >= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if compare(version, event.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+
+	return affected
+}
+
+func (e osvEvent) version() string {
+	return e.Introduced + e.Fixed + e.LastAffected
+}
+
+// compareEventVersions orders "0", the introduced event of every version, before all others.
+func compareEventVersions(a, b string, compare func(a, b string) int) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "0":
+		return -1
+	case b == "0":
+		return 1
+	default:
+		return compare(a, b)
+	}
+}
+
+func (p affectedPackage) fixedVersions(ecosystem string) []string {
+	var fixed []string
+
+	for _, versionRange := range p.ranges {
+		for _, event := range versionRange.Events {
+			if event.Fixed == "" {
+				continue
+			}
+
+			version := event.Fixed
+			// the Go vulnerability database leaves out the v of module versions
+			if ecosystem == EcosystemGo && !strings.HasPrefix(version, "v") {
+				version = "v" + version
+			}
+
+			if !slices.Contains(fixed, version) {
+				fixed = append(fixed, version)
+			}
+		}
+	}
+
+	return fixed
+}
+
+// severity prefers the rating of the database, like GitHub's LOW, MODERATE, HIGH and CRITICAL,
+// and otherwise rates the CVSS v3 base score of the advisory or the affected package.
+func severity(entry osvEntry, affected osvAffected) string {
+	if entry.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(entry.DatabaseSpecific.Severity)
+	}
+
+	for _, score := range slices.Concat(entry.Severity, affected.Severity) {
+		if score.Type != "CVSS_V3" {
+			continue
+		}
+
+		if rating, ok := cvss3Rating(score.Score); ok {
+			return rating
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,149 @@
+package advisory_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/advisory"
+)
+
+var osvFiles = map[string]string{
+	// Go vulnerability database: SEMVER ranges without the v, no severity
+	"GO-2024-0001.json": `{
+		"id": "GO-2024-0001",
+		"affected": [{
+			"package": {"ecosystem": "Go", "name": "github.com/example/lib"},
+			"ranges": [{"type": "SEMVER", "events": [
+				{"introduced": "0"}, {"fixed": "1.2.4"}, {"introduced": "1.3.0"}, {"fixed": "1.3.2"}
+			]}]
+		}]
+	}`,
+	// GitHub advisory: rated by the database, never fixed
+	"GHSA-aaaa-bbbb-cccc.json": `{
+		"id": "GHSA-aaaa-bbbb-cccc",
+		"database_specific": {"severity": "MODERATE"},
+		"affected": [{
+			"package": {"ecosystem": "Go", "name": "github.com/example/lib"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}]}]
+		}]
+	}`,
+	// RubyGems: ECOSYSTEM ranges in Gem::Version order, rated from the CVSS vector
+	"nested/GHSA-rack-0000-0001.json": `{
+		"id": "GHSA-rack-0000-0001",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+		"affected": [{
+			"package": {"ecosystem": "RubyGems", "name": "rack"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0.0.beta1"}, {"last_affected": "2.2.8"}]}]
+		}]
+	}`,
+	"GHSA-withdrawn.json": `{
+		"id": "GHSA-withdrawn", "withdrawn": "2024-01-01T00:00:00Z",
+		"affected": [{"package": {"ecosystem": "RubyGems", "name": "rack"}, "versions": ["2.2.3"]}]
+	}`,
+	"PYSEC-2024-1.json": `{
+		"id": "PYSEC-2024-1",
+		"affected": [{"package": {"ecosystem": "PyPI", "name": "rack"}, "versions": ["2.2.3"]}]
+	}`,
+	"README.json": `not an advisory`,
+}
+
+func writeDatabase(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range osvFiles {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func assertMatches(t *testing.T, database *advisory.Database) {
+	t.Helper()
+
+	assert.Equal(t, []advisory.Advisory{
+		{ID: "GHSA-aaaa-bbbb-cccc", Severity: "MODERATE", FixedVersions: nil},
+		{ID: "GO-2024-0001", Severity: "", FixedVersions: []string{"v1.2.4", "v1.3.2"}},
+	}, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v1.2.3"))
+
+	assert.Len(t, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v1.2.4"), 1)
+	assert.Len(t, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v1.3.1"), 2)
+	assert.Len(t, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v1.3.2"), 1)
+	assert.Len(t, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v0.9.0"), 1)
+	assert.Equal(t, []advisory.Advisory{}, database.Match(advisory.EcosystemGo, "github.com/example/other", "v1.0.0"))
+
+	rack := database.Match(advisory.EcosystemRubyGems, "rack", "2.2.3")
+	require.Len(t, rack, 1)
+	assert.Equal(t, advisory.Advisory{ID: "GHSA-rack-0000-0001", Severity: "CRITICAL", FixedVersions: nil}, rack[0])
+
+	assert.Len(t, database.Match(advisory.EcosystemRubyGems, "rack", "2.0.0.rc1"), 1)
+	assert.Empty(t, database.Match(advisory.EcosystemRubyGems, "rack", "2.0.0.alpha"))
+	assert.Empty(t, database.Match(advisory.EcosystemRubyGems, "rack", "2.2.9"))
+	assert.Empty(t, database.Match(advisory.EcosystemRubyGems, "rack", "1.6.13"))
+}
+
+func TestLoad_Directory(t *testing.T) {
+	t.Parallel()
+
+	database, err := advisory.Load(writeDatabase(t), advisory.EcosystemGo, advisory.EcosystemRubyGems)
+	require.NoError(t, err)
+
+	assertMatches(t, database)
+}
+
+func TestLoad_Zip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "all.zip")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	archive := zip.NewWriter(file)
+
+	for name, content := range osvFiles {
+		writer, err := archive.Create(name)
+		require.NoError(t, err)
+
+		_, err = writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+
+	database, err := advisory.Load(path, advisory.EcosystemGo, advisory.EcosystemRubyGems)
+	require.NoError(t, err)
+
+	assertMatches(t, database)
+}
+
+func TestLoad_OnlyRequestedEcosystems(t *testing.T) {
+	t.Parallel()
+
+	database, err := advisory.Load(writeDatabase(t), advisory.EcosystemRubyGems)
+	require.NoError(t, err)
+
+	assert.Empty(t, database.Match(advisory.EcosystemGo, "github.com/example/lib", "v1.2.3"))
+	assert.Len(t, database.Match(advisory.EcosystemRubyGems, "rack", "2.2.3"), 1)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := advisory.Load(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "advisories.tar.gz")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	_, err = advisory.Load(path)
+	require.ErrorIs(t, err, advisory.ErrUnsupportedDatabase)
+}
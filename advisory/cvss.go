@@ -0,0 +1,110 @@
+package advisory
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	cvssMaxScore       = 10
+	cvssScopeChanged   = 1.08
+	cvssRoundingFactor = 100000
+)
+
+// cvssWeights are the CVSS v3 base metric values, by metric and value.
+var cvssWeights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// privilegesWhenScopeChanged replaces the PR values for a changed scope.
+var privilegesWhenScopeChanged = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+
+// cvss3Rating rates the base score of a CVSS v3.0 or v3.1 vector like
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H as NONE, LOW, MEDIUM, HIGH or CRITICAL.
+func cvss3Rating(vector string) (string, bool) {
+	score, ok := cvss3BaseScore(vector)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case score == 0:
+		return "NONE", true
+	case score < 4:
+		return "LOW", true
+	case score < 7:
+		return "MEDIUM", true
+	case score < 9:
+		return "HIGH", true
+	default:
+		return "CRITICAL", true
+	}
+}
+
+// cvss3BaseScore computes the base score as the CVSS v3.1 specification defines it.
+func cvss3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, false
+	}
+
+	metrics := map[string]string{}
+
+	for _, part := range parts[1:] {
+		metric, value, found := strings.Cut(part, ":")
+		if found {
+			metrics[metric] = value
+		}
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	values := map[string]float64{}
+
+	for metric, weights := range cvssWeights {
+		value, ok := weights[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+
+		values[metric] = value
+	}
+
+	if scopeChanged {
+		values["PR"] = privilegesWhenScopeChanged[metrics["PR"]]
+	}
+
+	impactSubScore := 1 - (1-values["C"])*(1-values["I"])*(1-values["A"])
+
+	impact := 6.42 * impactSubScore
+	if scopeChanged {
+		impact = 7.52*(impactSubScore-0.029) - 3.25*math.Pow(impactSubScore-0.02, 15)
+	}
+
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * values["AV"] * values["AC"] * values["PR"] * values["UI"]
+
+	if scopeChanged {
+		return roundUp(math.Min(cvssScopeChanged*(impact+exploitability), cvssMaxScore)), true
+	}
+
+	return roundUp(math.Min(impact+exploitability, cvssMaxScore)), true
+}
+
+// roundUp rounds up to one decimal, avoiding the floating point errors as CVSS v3.1 specifies.
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * cvssRoundingFactor))
+	if scaled%10000 == 0 {
+		return float64(scaled) / cvssRoundingFactor
+	}
+
+	return float64(scaled/10000+1) / 10
+}
@@ -0,0 +1,98 @@
+package advisory
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+var gemSegmentRegex = regexp.MustCompile(`[0-9]+|[A-Za-z]+`)
+
+// versionComparer returns how the ECOSYSTEM ranges of an ecosystem order versions.
+func versionComparer(ecosystem string) func(a, b string) int {
+	if ecosystem == EcosystemRubyGems {
+		return compareGemVersions
+	}
+
+	return compareSemver
+}
+
+// compareSemver compares semantic versions with or without the v of Go module versions.
+func compareSemver(a, b string) int {
+	return semver.Compare("v"+strings.TrimPrefix(a, "v"), "v"+strings.TrimPrefix(b, "v"))
+}
+
+// compareGemVersions orders versions like Gem::Version: numbers compare as numbers, and a segment
+// with letters marks a prerelease, which sorts before any number: 1.0.a < 1.0 < 1.0.1.
+// Missing segments count as 0, so 1.0 equals 1.0.0.
+func compareGemVersions(a, b string) int {
+	left, right := gemSegments(a), gemSegments(b)
+
+	for i := range max(len(left), len(right)) {
+		leftSegment, rightSegment := segmentAt(left, i), segmentAt(right, i)
+		leftNumber, rightNumber := isNumber(leftSegment), isNumber(rightSegment)
+
+		switch {
+		case leftNumber && rightNumber:
+			if order := compareNumbers(leftSegment, rightSegment); order != 0 {
+				return order
+			}
+		case leftNumber:
+			return 1
+		case rightNumber:
+			return -1
+		case leftSegment != rightSegment:
+			return strings.Compare(leftSegment, rightSegment)
+		}
+	}
+
+	return 0
+}
+
+// gemSegments splits a version into its canonical segments: digits and letters apart, without
+// the zeros that end its release part or its prerelease part.
+func gemSegments(version string) []string {
+	segments := gemSegmentRegex.FindAllString(version, -1)
+
+	prerelease := len(segments)
+	for i, segment := range segments {
+		if !isNumber(segment) {
+			prerelease = i
+
+			break
+		}
+	}
+
+	return slices.Concat(trimZeros(segments[:prerelease]), trimZeros(segments[prerelease:]))
+}
+
+func trimZeros(segments []string) []string {
+	for len(segments) > 0 && isNumber(segments[len(segments)-1]) &&
+		strings.TrimLeft(segments[len(segments)-1], "0") == "" {
+		segments = segments[:len(segments)-1]
+	}
+
+	return segments
+}
+
+func segmentAt(segments []string, index int) string {
+	if index < len(segments) {
+		return segments[index]
+	}
+
+	return "0"
+}
+
+func isNumber(segment string) bool {
+	return segment != "" && strings.Trim(segment, "0123456789") == ""
+}
+
+// compareNumbers compares digit strings of any length.
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+
+	return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+}
@@ -0,0 +1,50 @@
+//nolint:testpackage // Tests unexported version ordering
+package advisory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareGemVersions(t *testing.T) {
+	t.Parallel()
+
+	ordered := []string{"1.0.a", "1.0.b1", "1.0", "1.0.1", "1.2", "1.10", "2.0.0.beta1", "2.0.0.rc1", "2.0.0"}
+
+	for i := range len(ordered) - 1 {
+		assert.Equal(t, -1, compareGemVersions(ordered[i], ordered[i+1]), ordered[i]+" < "+ordered[i+1])
+		assert.Equal(t, 1, compareGemVersions(ordered[i+1], ordered[i]), ordered[i+1]+" > "+ordered[i])
+	}
+
+	assert.Equal(t, 0, compareGemVersions("1.0", "1.0.0"))
+	assert.Equal(t, 0, compareGemVersions("1.01", "1.1"))
+	assert.Equal(t, 0, compareGemVersions("1.0.a", "1.a"))
+	assert.Equal(t, 1, compareGemVersions("20250101000000000000", "9"))
+}
+
+func TestCVSS3Rating(t *testing.T) {
+	t.Parallel()
+
+	for vector, want := range map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H": 9.9,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	} {
+		score, ok := cvss3BaseScore(vector)
+		assert.True(t, ok, vector)
+		assert.InDelta(t, want, score, 0.001, vector)
+	}
+
+	rating, ok := cvss3Rating("CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N")
+	assert.True(t, ok)
+	assert.Equal(t, "MEDIUM", rating)
+
+	_, ok = cvss3Rating("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N")
+	assert.False(t, ok)
+
+	_, ok = cvss3Rating("CVSS:3.1/AV:N")
+	assert.False(t, ok)
+}
@@ -0,0 +1,251 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+// DefaultRetryBudget is how long a request waits in total for rate limits and temporary failures
+// unless WithRetryBudget says otherwise.
+const DefaultRetryBudget = 2 * time.Minute
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+	// how long a request waits in total for statistics GitHub is still computing, apart from
+	// the retry budget: they may take minutes, and a later run finds them ready
+	statisticsBudget = 15 * time.Second
+)
+
+var (
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+	ErrTemporaryFailure     = errors.New("temporary failure")
+	ErrStatisticsPending    = errors.New("statistics are still being computed")
+)
+
+// apiClient sends requests to a forge API. Rate-limited and temporarily failing
+// requests are retried, waiting as long as the forge asks (Retry-After, X-RateLimit-Reset)
+// or with exponential backoff and jitter, until the waits would exceed retryBudget.
+// Statistics that are still being computed are only waited for up to statisticsBudget.
+type apiClient struct {
+	httpClient       *http.Client
+	retryBudget      time.Duration
+	statisticsBudget time.Duration
+	sleep            func(ctx context.Context, duration time.Duration) error
+}
+
+// newAPIClient caches the responses of the forge under source.
+func newAPIClient(o options, source utils.CacheSource) *apiClient {
+	return &apiClient{
+		httpClient:       o.cache.Client(source),
+		retryBudget:      o.retryBudget,
+		statisticsBudget: statisticsBudget,
+		sleep:            sleepContext,
+	}
+}
+
+// send returns the body of a 200 response. Errors after which a later run may succeed
+// wrap ErrRetryBudgetExhausted; other non-200 responses wrap ErrUnexpectedStatusCode.
+func (c *apiClient) send(
+	ctx context.Context,
+	method, url string,
+	headers map[string]string,
+	body []byte,
+) ([]byte, error) {
+	responseBody, _, err := c.sendWithHeader(ctx, method, url, headers, body)
+
+	return responseBody, err
+}
+
+// sendWithHeader is send for callers that also read the response headers, like pagination totals.
+func (c *apiClient) sendWithHeader(
+	ctx context.Context,
+	method, url string,
+	headers map[string]string,
+	body []byte,
+) ([]byte, http.Header, error) {
+	var waited, pending time.Duration
+
+	for attempt := 0; ; attempt++ {
+		responseBody, header, wait, err := c.attempt(ctx, method, url, headers, body, attempt)
+		if err == nil {
+			return responseBody, header, nil
+		}
+
+		if !errors.Is(err, ErrTemporaryFailure) || ctx.Err() != nil {
+			return nil, nil, err
+		}
+
+		if waited+wait > c.retryBudget {
+			return nil, nil, fmt.Errorf("%w after waiting %s: %w", ErrRetryBudgetExhausted, waited, err)
+		}
+
+		statistics := errors.Is(err, ErrStatisticsPending)
+		if statistics && pending+wait > c.statisticsBudget {
+			return nil, nil, fmt.Errorf("gave up after waiting %s: %w", pending, err)
+		}
+
+		utils.DebugPrintln(fmt.Sprintf("Retrying %s in %s: %v", url, wait.Round(time.Millisecond), err))
+
+		err = c.sleep(ctx, wait)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		waited += wait
+
+		if statistics {
+			pending += wait
+		}
+	}
+}
+
+// attempt sends the request once. For a temporary failure it also returns how long to wait.
+func (c *apiClient) attempt(
+	ctx context.Context,
+	method, url string,
+	headers map[string]string,
+	body []byte,
+	attempt int,
+) ([]byte, http.Header, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOutSec*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to create new HTTP request for URL %s: %w", url, err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if errors.Is(err, utils.ErrNotInOfflineSnapshot) {
+		return nil, nil, 0, fmt.Errorf("failed to execute HTTP request for URL %s: %w", url, err)
+	}
+
+	if err != nil {
+		return nil, nil, backoff(attempt), fmt.Errorf("%w: failed to execute HTTP request for URL %s: %w",
+			ErrTemporaryFailure, url, err)
+	}
+	defer resp.Body.Close()
+
+	logQuota(resp.Header)
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, backoff(attempt), fmt.Errorf("%w: failed to read response for URL %s: %w",
+			ErrTemporaryFailure, url, err)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return responseBody, resp.Header, 0, nil
+	}
+
+	// GitHub answers 202 to the statistics endpoints until it has computed them in the background
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, nil, backoff(attempt), fmt.Errorf("%w: %w for URL %s", ErrTemporaryFailure, ErrStatisticsPending, url)
+	}
+
+	statusErr := fmt.Errorf("%w: %d for URL %s", ErrUnexpectedStatusCode, resp.StatusCode, url)
+
+	if !isRetryable(resp) {
+		return nil, nil, 0, statusErr
+	}
+
+	return nil, nil, retryWait(resp.Header, attempt), fmt.Errorf("%w: %w", ErrTemporaryFailure, statusErr)
+}
+
+// isRetryable tells rate limits and server-side hiccups apart from permanent errors.
+// GitHub answers a rate limit with 429, or with 403 and either no remaining quota or a Retry-After.
+// GitLab always answers 429.
+func isRetryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+// retryWait prefers what GitHub asks for: Retry-After, or the reset time of an
+// exhausted quota. Otherwise it backs off exponentially.
+func retryWait(header http.Header, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0))+time.Second, 0)
+		}
+	}
+
+	return backoff(attempt)
+}
+
+// backoff doubles with each attempt up to maxBackoff, keeping a random half as jitter
+// so that concurrent workers do not retry in lockstep.
+func backoff(attempt int) time.Duration {
+	wait := maxBackoff
+	// 1s << 6 already exceeds maxBackoff
+	if attempt < 6 {
+		wait = min(initialBackoff<<attempt, maxBackoff)
+	}
+
+	return wait/2 + rand.N(wait/2+1) //nolint:gosec // jitter does not need a secure random source
+}
+
+func logQuota(header http.Header) {
+	remaining := header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+
+	resetAt := header.Get("X-RateLimit-Reset")
+	if reset, err := strconv.ParseInt(resetAt, 10, 64); err == nil {
+		resetAt = time.Unix(reset, 0).Format(time.RFC3339)
+	}
+
+	utils.DebugPrintln("API quota: " + remaining + "/" + header.Get("X-RateLimit-Limit") +
+		" remaining, resets at " + resetAt)
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// skipReason tells a run worth repeating later apart from a repository that cannot be fetched,
+// and both from one missing in offline mode.
+func skipReason(forge, repoURL string, err error) string {
+	if errors.Is(err, utils.ErrNotInOfflineSnapshot) {
+		return "Not in offline snapshot"
+	}
+
+	if errors.Is(err, ErrRetryBudgetExhausted) {
+		return "Rate limited or temporarily unavailable while fetching " + repoURL + " from " + forge + ", retry later"
+	}
+
+	return "Failed fetching " + repoURL + " from " + forge
+}
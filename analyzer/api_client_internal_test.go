@@ -0,0 +1,185 @@
+//nolint:testpackage // Tests unexported client internals
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient records the waits instead of sleeping.
+func newTestClient(budget time.Duration, waits *[]time.Duration) *apiClient {
+	return &apiClient{
+		httpClient:       &http.Client{},
+		retryBudget:      budget,
+		statisticsBudget: budget,
+		sleep: func(_ context.Context, duration time.Duration) error {
+			*waits = append(*waits, duration)
+
+			return nil
+		},
+	}
+}
+
+// failingServer answers with the given responses in order, then with 200.
+func failingServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		call := int(calls.Add(1)) - 1
+		if call < len(responses) {
+			responses[call](w)
+
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestAPIClient_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	server, calls := failingServer(t, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var waits []time.Duration
+
+	body, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ok": true}`, string(body))
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []time.Duration{7 * time.Second}, waits)
+}
+
+func TestAPIClient_PrimaryRateLimitWaitsForReset(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(30 * time.Second).Unix()
+
+	server, _ := failingServer(t, func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var waits []time.Duration
+
+	_, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, waits, 1)
+	assert.InDelta(t, 30, waits[0].Seconds(), 2)
+}
+
+func TestAPIClient_ServerErrorsBackOffExponentially(t *testing.T) {
+	t.Parallel()
+
+	unavailable := func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }
+	server, calls := failingServer(t, unavailable, unavailable, unavailable)
+
+	var waits []time.Duration
+
+	_, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+	require.Len(t, waits, 3)
+
+	for attempt, wait := range waits {
+		limit := initialBackoff << attempt
+		assert.GreaterOrEqual(t, wait, limit/2)
+		assert.LessOrEqual(t, wait, limit)
+	}
+}
+
+func TestAPIClient_GivesUpAfterBudget(t *testing.T) {
+	t.Parallel()
+
+	server, calls := failingServer(t, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	var waits []time.Duration
+
+	_, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Empty(t, waits)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Contains(t, skipReason(ForgeGitHub, "https://github.com/o/r", err), "retry later")
+}
+
+func TestAPIClient_PermanentFailureIsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	server, calls := failingServer(t, func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) })
+
+	var waits []time.Duration
+
+	_, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.ErrorIs(t, err, ErrUnexpectedStatusCode)
+	require.NotErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, "Failed fetching https://github.com/o/r from GitHub",
+		skipReason(ForgeGitHub, "https://github.com/o/r", err))
+}
+
+func TestAPIClient_StatisticsPendingIsRetried(t *testing.T) {
+	t.Parallel()
+
+	accepted := func(w http.ResponseWriter) { w.WriteHeader(http.StatusAccepted) }
+	server, calls := failingServer(t, accepted, accepted)
+
+	var waits []time.Duration
+
+	body, err := newTestClient(time.Minute, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ok": true}`, string(body))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Len(t, waits, 2)
+
+	server, _ = failingServer(t, accepted)
+
+	_, err = newTestClient(0, &waits).send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	require.ErrorIs(t, err, ErrStatisticsPending)
+}
+
+func TestAPIClient_StatisticsPendingHasItsOwnBudget(t *testing.T) {
+	t.Parallel()
+
+	accepted := func(w http.ResponseWriter) { w.WriteHeader(http.StatusAccepted) }
+	server, calls := failingServer(t, accepted, accepted, accepted, accepted, accepted, accepted)
+
+	var waits []time.Duration
+
+	client := newTestClient(time.Hour, &waits)
+	client.statisticsBudget = 5 * time.Second
+
+	// backoffs of at least 0.5s, 1s, 2s and 4s exceed 5s by the fourth retry
+	_, err := client.send(context.Background(), http.MethodGet, server.URL, nil, nil)
+	require.ErrorIs(t, err, ErrStatisticsPending)
+	require.NotErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Less(t, calls.Load(), int32(5))
+
+	var total time.Duration
+	for _, wait := range waits {
+		total += wait
+	}
+
+	assert.LessOrEqual(t, total, client.statisticsBudget)
+}
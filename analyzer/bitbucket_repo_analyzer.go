@@ -0,0 +1,170 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const defaultBitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketRepository is the part of GET /repositories/{workspace}/{repo_slug} that is used.
+type bitbucketRepository struct {
+	Slug       string `json:"slug"`
+	HasIssues  bool   `json:"has_issues"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"` //nolint:tagliatelle // Bitbucket API field name
+}
+
+// bitbucketPage is a paginated Bitbucket answer; size is the number of items on all pages.
+type bitbucketPage struct {
+	Size int `json:"size"`
+}
+
+type bitbucketCommits struct {
+	Values []struct {
+		Date string `json:"date"`
+	} `json:"values"`
+}
+
+// BitbucketRepoAnalyzer analyzes Bitbucket Cloud repositories with the Bitbucket REST API 2.0.
+// Bitbucket has neither stars nor archived repositories; both are left out of the score.
+type BitbucketRepoAnalyzer struct {
+	bitbucketToken string
+	weights        ParameterWeights
+	options        options
+}
+
+// NewBitbucketRepoAnalyzer uses token, a repository or workspace access token, as a bearer token.
+// Public repositories can be read without one.
+func NewBitbucketRepoAnalyzer(token string, weights ParameterWeights, opts ...Option) *BitbucketRepoAnalyzer {
+	return &BitbucketRepoAnalyzer{
+		bitbucketToken: token,
+		weights:        weights,
+		options:        newOptions(opts),
+	}
+}
+
+// FetchRepoInfo fetches the repositories with a bounded number of workers. Open issues and open
+// pull requests are counted apart; the issues only when the built-in tracker is enabled.
+func (b *BitbucketRepoAnalyzer) FetchRepoInfo(
+	ctx context.Context,
+	repositoryUrls []string,
+) []RepoInfo {
+	client := newAPIClient(b.options, utils.CacheSourceBitbucket)
+
+	return fetchEach(ctx, b.options, ForgeBitbucket, repositoryUrls, func(ctx context.Context, repoURL string) (
+		*RepoInfo, error,
+	) {
+		return b.getBitbucketInfo(ctx, client, repoURL)
+	})
+}
+
+func (b *BitbucketRepoAnalyzer) getBitbucketInfo(
+	ctx context.Context,
+	client *apiClient,
+	repoURL string,
+) (*RepoInfo, error) {
+	workspace, slug, err := ownerAndRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+
+	if b.bitbucketToken != "" {
+		headers["Authorization"] = "Bearer " + b.bitbucketToken
+	}
+
+	repoAPIURL := b.options.api(defaultBitbucketAPIURL) + "/repositories/" + workspace + "/" + slug
+
+	var repository bitbucketRepository
+
+	err = fetchJSONData(ctx, client, repoAPIURL, headers, &repository)
+	if err != nil {
+		return nil, err
+	}
+
+	watchers, err := bitbucketSize(ctx, client, repoAPIURL+"/watchers?pagelen=1", headers)
+	if err != nil {
+		return nil, err
+	}
+
+	forks, err := bitbucketSize(ctx, client, repoAPIURL+"/forks?pagelen=1", headers)
+	if err != nil {
+		return nil, err
+	}
+
+	openPullRequests, err := bitbucketSize(ctx, client, repoAPIURL+"/pullrequests?state=OPEN&pagelen=1", headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// without the built-in issue tracker the issues are elsewhere, so they are not counted as none
+	var openIssues *int
+
+	if repository.HasIssues {
+		issuesURL := repoAPIURL + "/issues?pagelen=1&q=" + url.QueryEscape(`state="new" OR state="open"`)
+
+		issues, err := bitbucketSize(ctx, client, issuesURL, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		openIssues = &issues
+	}
+
+	lastCommitDate, err := bitbucketLastCommitDate(ctx, client, repoAPIURL, repository.MainBranch.Name, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	repoInfo := &RepoInfo{
+		RepositoryName:   repository.Slug,
+		Watchers:         Ptr(watchers),
+		Forks:            Ptr(forks),
+		OpenIssues:       openIssues,
+		OpenPullRequests: Ptr(openPullRequests),
+		LastCommitDate:   Ptr(lastCommitDate),
+	}
+
+	CalcScore(repoInfo, &b.weights)
+
+	return repoInfo, nil
+}
+
+// bitbucketSize reads the total number of items of a paginated list from its first page.
+func bitbucketSize(ctx context.Context, client *apiClient, pageURL string, headers map[string]string) (int, error) {
+	var page bitbucketPage
+
+	err := fetchJSONData(ctx, client, pageURL, headers, &page)
+	if err != nil {
+		return 0, err
+	}
+
+	return page.Size, nil
+}
+
+func bitbucketLastCommitDate(
+	ctx context.Context,
+	client *apiClient,
+	repoAPIURL, branch string,
+	headers map[string]string,
+) (string, error) {
+	var commits bitbucketCommits
+
+	err := fetchJSONData(ctx, client, repoAPIURL+"/commits/"+url.PathEscape(branch)+"?pagelen=1", headers, &commits)
+	if err != nil {
+		return "", err
+	}
+
+	if len(commits.Values) == 0 {
+		return "", fmt.Errorf("%w: no commits on %s", ErrNoCommits, branch)
+	}
+
+	return utcDate(commits.Values[0].Date, time.RFC3339), nil
+}
@@ -0,0 +1,72 @@
+package analyzer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+)
+
+func TestBitbucketRepoAnalyzer_FetchRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer bb-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		const repo = "/2.0/repositories/atlassian/sdk"
+
+		switch r.URL.Path {
+		case repo:
+			_, _ = w.Write([]byte(`{"slug": "sdk", "has_issues": true, "mainbranch": {"name": "master"}}`))
+		case repo + "/watchers":
+			_, _ = w.Write([]byte(`{"size": 7, "values": []}`))
+		case repo + "/forks":
+			_, _ = w.Write([]byte(`{"size": 3, "values": []}`))
+		case repo + "/pullrequests":
+			assert.Equal(t, "OPEN", r.URL.Query().Get("state"))
+			_, _ = w.Write([]byte(`{"size": 2, "values": []}`))
+		case repo + "/issues":
+			assert.Equal(t, `state="new" OR state="open"`, r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(`{"size": 4, "values": []}`))
+		case repo + "/commits/master":
+			_, _ = w.Write([]byte(`{"values": [{"date": "2024-03-04T10:00:00+01:00"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repoAnalyzer := analyzer.NewBitbucketRepoAnalyzer("bb-token", analyzer.ParameterWeights{Watchers: 1, Stars: 1000},
+		analyzer.WithAPIURL(server.URL+"/2.0"), analyzer.WithRetryBudget(0))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://bitbucket.org/atlassian/sdk/src/master/",
+		"https://bitbucket.org/atlassian",
+	})
+
+	require.Len(t, repoInfos, 2)
+
+	info := repoInfos[0]
+	assert.False(t, info.Skip, info.SkipReason)
+	assert.Equal(t, analyzer.ForgeBitbucket, info.Forge)
+	assert.Equal(t, "sdk", info.RepositoryName)
+	assert.Equal(t, analyzer.Ptr(7), info.Watchers)
+	assert.Equal(t, analyzer.Ptr(3), info.Forks)
+	assert.Equal(t, analyzer.Ptr(4), info.OpenIssues)
+	assert.Equal(t, analyzer.Ptr(2), info.OpenPullRequests)
+	assert.Equal(t, analyzer.Ptr("2024-03-04T09:00:00Z"), info.LastCommitDate)
+	assert.Equal(t, 7, info.Score)
+	assert.Nil(t, info.Stars)
+	assert.Nil(t, info.Archived)
+
+	assert.True(t, repoInfos[1].Skip)
+	assert.Equal(t, "Failed fetching https://bitbucket.org/atlassian from Bitbucket", repoInfos[1].SkipReason)
+}
@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"context"
+)
+
+// gitHubCommitWeek is one week of stats/commit_activity, which covers the last 52 weeks, the oldest first.
+type gitHubCommitWeek struct {
+	Total int `json:"total"`
+}
+
+// fetchCommitActivity reads the number of commits to the default branch in each of the last 52 weeks.
+// Like the contributor statistics they are computed in the background, and the error wraps
+// ErrStatisticsPending when they are still not ready after the retry budget.
+func fetchCommitActivity(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
+	headers map[string]string,
+) ([]int, error) {
+	var weeks []gitHubCommitWeek
+
+	err := fetchJSONData(ctx, client, apiBaseURL+"/repos/"+owner+"/"+repo+"/stats/commit_activity", headers, &weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	weeklyCommits := make([]int, len(weeks))
+	for i, week := range weeks {
+		weeklyCommits[i] = week.Total
+	}
+
+	return weeklyCommits, nil
+}
+
+// setCommitActivity fills the weekly commits of repoInfo and counts the weeks with any commit,
+// which a single commit after months of silence barely moves, unlike the last commit date.
+func setCommitActivity(repoInfo *RepoInfo, weeklyCommits []int) {
+	activeWeeks := 0
+
+	for _, commits := range weeklyCommits {
+		if commits > 0 {
+			activeWeeks++
+		}
+	}
+
+	repoInfo.WeeklyCommits = weeklyCommits
+	repoInfo.ActiveWeeksLastYear = Ptr(activeWeeks)
+}
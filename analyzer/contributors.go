@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const (
+	recentCommitterDays = 90
+	percent             = 100
+)
+
+// gitHubContributorStats is one author of stats/contributors, which lists the weekly commits
+// of the 100 authors with the most commits to the default branch.
+type gitHubContributorStats struct {
+	Weeks []gitHubContributorWeek `json:"weeks"`
+}
+
+type gitHubContributorWeek struct {
+	Start   int64 `json:"w"` // Unix time of the Sunday the week starts
+	Commits int   `json:"c"`
+}
+
+// fetchContributorStats reads the commit statistics per author. GitHub computes them in the
+// background and answers 202 until they are ready; when they are still pending after the retry
+// budget, the error wraps ErrStatisticsPending.
+func fetchContributorStats(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
+	headers map[string]string,
+) ([]gitHubContributorStats, error) {
+	var contributors []gitHubContributorStats
+
+	err := fetchJSONData(ctx, client, apiBaseURL+"/repos/"+owner+"/"+repo+"/stats/contributors", headers, &contributors)
+	if err != nil {
+		return nil, err
+	}
+
+	return contributors, nil
+}
+
+// setContributorActivity fills the committer counts of the last 90 and 365 days and the share
+// of the last year's commits made by its most active committer. The share is left unknown for
+// repositories without commits in the last year.
+func setContributorActivity(repoInfo *RepoInfo, contributors []gitHubContributorStats, now time.Time) {
+	recentSince := now.AddDate(0, 0, -recentCommitterDays).Unix()
+	yearSince := now.AddDate(0, 0, -daysOfYear).Unix()
+
+	recentCommitters, yearCommitters := 0, 0
+	commitsLastYear := make([]int, 0, len(contributors))
+
+	for _, contributor := range contributors {
+		recent, lastYear := 0, 0
+
+		for _, week := range contributor.Weeks {
+			if week.Start >= recentSince {
+				recent += week.Commits
+			}
+
+			if week.Start >= yearSince {
+				lastYear += week.Commits
+			}
+		}
+
+		if recent > 0 {
+			recentCommitters++
+		}
+
+		if lastYear > 0 {
+			yearCommitters++
+		}
+
+		commitsLastYear = append(commitsLastYear, lastYear)
+	}
+
+	repoInfo.CommittersLast90Days = Ptr(recentCommitters)
+	repoInfo.CommittersLastYear = Ptr(yearCommitters)
+
+	total := 0
+	for _, commits := range commitsLastYear {
+		total += commits
+	}
+
+	if total == 0 {
+		return
+	}
+
+	repoInfo.TopContributorShare = Ptr(slices.Max(commitsLastYear) * percent / total)
+}
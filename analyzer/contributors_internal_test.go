@@ -0,0 +1,42 @@
+//nolint:testpackage // Tests unexported contributor metrics
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetContributorActivity(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	weeksAgo := func(weeks int) int64 { return now.AddDate(0, 0, -7*weeks).Unix() }
+
+	info := &RepoInfo{}
+	setContributorActivity(info, []gitHubContributorStats{
+		{Weeks: []gitHubContributorWeek{{Start: weeksAgo(2), Commits: 30}, {Start: weeksAgo(100), Commits: 500}}},
+		{Weeks: []gitHubContributorWeek{{Start: weeksAgo(30), Commits: 10}}},
+		{Weeks: []gitHubContributorWeek{{Start: weeksAgo(1), Commits: 0}}},
+	}, now)
+
+	assert.Equal(t, Ptr(1), info.CommittersLast90Days)
+	assert.Equal(t, Ptr(2), info.CommittersLastYear)
+	assert.Equal(t, Ptr(75), info.TopContributorShare) // older commits do not count
+}
+
+func TestSetContributorActivity_NoRecentCommits(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	info := &RepoInfo{}
+	setContributorActivity(info, []gitHubContributorStats{
+		{Weeks: []gitHubContributorWeek{{Start: now.AddDate(-3, 0, 0).Unix(), Commits: 12}}},
+	}, now)
+
+	assert.Equal(t, Ptr(0), info.CommittersLast90Days)
+	assert.Equal(t, Ptr(0), info.CommittersLastYear)
+	assert.Nil(t, info.TopContributorShare)
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+// Forges a repository can be analyzed on, as shown in the Forge column.
+const (
+	ForgeGitHub    = "GitHub"
+	ForgeGitLab    = "GitLab"
+	ForgeBitbucket = "Bitbucket"
+	ForgeGitea     = "Gitea"
+	ForgeSourceHut = "SourceHut"
+)
+
+var (
+	ErrInvalidRepositoryURL = errors.New("invalid repository URL")
+	ErrNoCommits            = errors.New("repository has no commits")
+)
+
+// RepoInfo is the analysis of one repository. A metric is nil when the forge does not provide it;
+// it is then left out of the score and shown as N/A. Incomplete
+// says why metrics the forge does provide are nil this time; such repositories are not scored.
+type RepoInfo struct {
+	RepositoryName           string
+	RepositoryURL            string
+	Forge                    string // ForgeGitHub, ForgeGitLab, ...
+	Watchers                 *int
+	Stars                    *int
+	Forks                    *int
+	OpenIssues               *int // issues only, without pull requests
+	OpenPullRequests         *int
+	LastCommitDate           *string // UTC, in the layout of gitHubDateLayout
+	CreatedDate              *string // the release age of a repository that never released counts from it
+	LastReleaseDate          *string // of the latest release, or tag when there are none
+	ReleasesLastYear         *int    // 0 with no LastReleaseDate when the repository never released
+	MedianReleaseGapDays     *int
+	CommittersLast90Days     *int // distinct commit authors, like CommittersLastYear
+	CommittersLastYear       *int
+	TopContributorShare      *int  // percent of the last year's commits made by the most active committer
+	MedianIssueResponseHours *int  // until a maintainer first answers an issue opened in the lookback window
+	MedianMergeDays          *int  // of the pull requests merged in the lookback window
+	IssueCloseRatio          *int  // issues closed per 100 opened in the lookback window
+	ActiveWeeksLastYear      *int  // of the last 52 weeks, those with at least one commit
+	WeeklyCommits            []int // commits in each of the last 52 weeks, the oldest first; nil when unknown
+	Archived                 *bool
+	Incomplete               []string // e.g. "contributor statistics pending" or a history cut short
+	Score                    int
+	Skip                     bool   // スキップするかどうかのフラグ
+	SkipReason               string // スキップ理由
+}
+
+// RepoAnalyzer fetches repositories, returning one result per URL in the order of repositoryUrls.
+// The analyzers of every forge and the Registry implement it, as can analyzers outside this module.
+type RepoAnalyzer interface {
+	FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo
+}
+
+// Ptr returns a pointer to value, for filling the optional metrics of a RepoInfo.
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+// fetchEach runs fetch for every repository with a bounded number of workers and tags the
+// results with forge. The results are in the order of repositoryUrls; when ctx is canceled,
+// the repositories not fetched yet are returned as skipped.
+func fetchEach(
+	ctx context.Context,
+	o options,
+	forge string,
+	repositoryUrls []string,
+	fetch func(ctx context.Context, repoURL string) (*RepoInfo, error),
+) []RepoInfo {
+	libraryInfoList := make([]RepoInfo, len(repositoryUrls))
+
+	for i, repoURL := range repositoryUrls {
+		libraryInfoList[i] = RepoInfo{
+			RepositoryURL: repoURL,
+			Skip:          true,
+			SkipReason:    "Canceled before fetching " + repoURL,
+		}
+	}
+
+	err := utils.ForEachIndex(ctx, o.concurrency, len(repositoryUrls), func(i int) {
+		repoURL := repositoryUrls[i]
+		utils.DebugPrintln("Fetching: " + repoURL)
+
+		libraryInfo, err := fetch(ctx, repoURL)
+		if err != nil {
+			libraryInfo = &RepoInfo{
+				Skip:       true,
+				SkipReason: skipReason(forge, repoURL, err),
+			}
+
+			utils.StdErrorPrintln("Failed fetching %s, error details: %v", repoURL, err)
+		}
+
+		libraryInfo.RepositoryURL = repoURL
+		libraryInfoList[i] = *libraryInfo
+	})
+	if err != nil {
+		utils.StdErrorPrintln("Fetching from %s was interrupted: %v", forge, err)
+	}
+
+	tagForge(libraryInfoList, forge)
+
+	return libraryInfoList
+}
+
+// tagForge marks the results of an analyzer with its forge.
+func tagForge(results []RepoInfo, forge string) {
+	for i := range results {
+		results[i].Forge = forge
+	}
+}
+
+// ownerAndRepo reads https://<host>/<owner>/<repo>[/...] into owner and repo,
+// the layout of every forge but GitLab, whose groups nest.
+func ownerAndRepo(repoURL string) (string, string, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidRepositoryURL, err)
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if parsed.Host == "" || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepositoryURL, repoURL)
+	}
+
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// utcDate converts the dates of other forges (2024-01-02T03:04:05.678+09:00) to the UTC,
+// second precision dates GitHub returns and daysSince reads.
+func utcDate(date string, layout string) string {
+	parsed, err := time.Parse(layout, date)
+	if err != nil {
+		// CalcScore reports it as a date format error
+		return date
+	}
+
+	return parsed.UTC().Format(gitHubDateLayout)
+}
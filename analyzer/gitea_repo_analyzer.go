@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const defaultGiteaAPIURL = "https://codeberg.org/api/v1"
+
+// giteaRepository is the part of GET /repos/{owner}/{repo} that is scored.
+type giteaRepository struct {
+	Name            string `json:"name"`
+	WatchersCount   int    `json:"watchers_count"`
+	StarsCount      int    `json:"stars_count"`
+	ForksCount      int    `json:"forks_count"`
+	OpenIssuesCount int    `json:"open_issues_count"`
+	OpenPRCounter   int    `json:"open_pr_counter"`
+	Archived        bool   `json:"archived"`
+	DefaultBranch   string `json:"default_branch"`
+}
+
+type giteaBranch struct {
+	Commit struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"commit"`
+}
+
+// GiteaRepoAnalyzer analyzes repositories of a Gitea or Forgejo instance, Codeberg unless
+// WithAPIURL points it at another one. Gitea has every metric GitHub has.
+type GiteaRepoAnalyzer struct {
+	giteaToken string
+	weights    ParameterWeights
+	options    options
+}
+
+func NewGiteaRepoAnalyzer(token string, weights ParameterWeights, opts ...Option) *GiteaRepoAnalyzer {
+	return &GiteaRepoAnalyzer{
+		giteaToken: token,
+		weights:    weights,
+		options:    newOptions(opts),
+	}
+}
+
+// FetchRepoInfo fetches the repositories with a bounded number of workers.
+// Open issues and open pull requests are counted apart, as on GitHub.
+func (g *GiteaRepoAnalyzer) FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo {
+	client := newAPIClient(g.options, utils.CacheSourceGitea)
+
+	return fetchEach(ctx, g.options, ForgeGitea, repositoryUrls, func(ctx context.Context, repoURL string) (
+		*RepoInfo, error,
+	) {
+		return g.getGiteaInfo(ctx, client, repoURL)
+	})
+}
+
+func (g *GiteaRepoAnalyzer) getGiteaInfo(
+	ctx context.Context,
+	client *apiClient,
+	repoURL string,
+) (*RepoInfo, error) {
+	owner, repo, err := ownerAndRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+
+	if g.giteaToken != "" {
+		headers["Authorization"] = "token " + g.giteaToken
+	}
+
+	repoAPIURL := g.options.api(defaultGiteaAPIURL) + "/repos/" + owner + "/" + repo
+
+	var repository giteaRepository
+
+	err = fetchJSONData(ctx, client, repoAPIURL, headers, &repository)
+	if err != nil {
+		return nil, err
+	}
+
+	var branch giteaBranch
+
+	err = fetchJSONData(ctx, client, repoAPIURL+"/branches/"+url.PathEscape(repository.DefaultBranch), headers, &branch)
+	if err != nil {
+		return nil, err
+	}
+
+	repoInfo := &RepoInfo{
+		RepositoryName:   repository.Name,
+		Watchers:         Ptr(repository.WatchersCount),
+		Stars:            Ptr(repository.StarsCount),
+		Forks:            Ptr(repository.ForksCount),
+		OpenIssues:       Ptr(repository.OpenIssuesCount),
+		OpenPullRequests: Ptr(repository.OpenPRCounter),
+		LastCommitDate:   Ptr(utcDate(branch.Commit.Timestamp, time.RFC3339)),
+		Archived:         Ptr(repository.Archived),
+	}
+
+	CalcScore(repoInfo, &g.weights)
+
+	return repoInfo, nil
+}
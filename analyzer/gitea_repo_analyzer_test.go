@@ -0,0 +1,56 @@
+package analyzer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+)
+
+func TestGiteaRepoAnalyzer_FetchRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/forgejo/lib":
+			_, _ = w.Write([]byte(`{
+				"name": "lib", "watchers_count": 5, "stars_count": 40, "forks_count": 6,
+				"open_issues_count": 3, "open_pr_counter": 2, "archived": true, "default_branch": "forgejo"
+			}`))
+		case "/api/v1/repos/forgejo/lib/branches/forgejo":
+			_, _ = w.Write([]byte(`{"name": "forgejo", "commit": {"timestamp": "2024-05-06T07:08:09+02:00"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	weights := analyzer.ParameterWeights{
+		Watchers: 1, Stars: 10, Forks: 100, OpenIssues: 1000, OpenPullRequests: 100000, Archived: 10000,
+	}
+
+	repoAnalyzer := analyzer.NewGiteaRepoAnalyzer("", weights,
+		analyzer.WithAPIURL(server.URL+"/api/v1"), analyzer.WithRetryBudget(0))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://codeberg.org/forgejo/lib/src/branch/forgejo",
+	})
+
+	require.Len(t, repoInfos, 1)
+
+	info := repoInfos[0]
+	assert.False(t, info.Skip, info.SkipReason)
+	assert.Equal(t, analyzer.ForgeGitea, info.Forge)
+	assert.Equal(t, "lib", info.RepositoryName)
+	assert.Equal(t, analyzer.Ptr(3), info.OpenIssues)
+	assert.Equal(t, analyzer.Ptr(2), info.OpenPullRequests)
+	assert.Equal(t, analyzer.Ptr(true), info.Archived)
+	assert.Equal(t, analyzer.Ptr("2024-05-06T05:08:09Z"), info.LastCommitDate)
+	assert.Equal(t, 5+400+600+3000+200000+10000, info.Score)
+	assert.NotNil(t, info.Watchers)
+	assert.NotNil(t, info.Stars)
+}
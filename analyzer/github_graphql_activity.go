@@ -0,0 +1,590 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	historyPerPage  = 100
+	maxHistoryPages = 10 // pages of the default branch history read for the committers of the last year
+	weeksOfYear     = 52
+	daysOfWeek      = 7
+)
+
+var ErrGraphQLQueryFailed = errors.New("GraphQL query failed")
+
+// historySelection asks a repository for a page of the commits of the default branch since a date,
+// the newest first.
+var historySelection = fmt.Sprintf(`    defaultBranchRef {
+      target {
+        ... on Commit {
+          history(first: %d, after: $cursor, since: $since) {
+            pageInfo { hasNextPage endCursor }
+            nodes { committedDate author { email user { login } } }
+          }
+        }
+      }
+    }`, historyPerPage)
+
+// issuesSelection asks a repository for a page of the issues updated since a date, like the REST
+// issues list, which also has the pull requests, while GraphQL keeps them apart.
+var issuesSelection = fmt.Sprintf(
+	`    issues(first: %d, after: $cursor, filterBy: {since: $since}, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { number createdAt closedAt authorAssociation comments { totalCount } }
+    }`, lookbackPerPage)
+
+// closedPullsSelection asks a repository for a page of the closed and merged pull requests, the
+// most recently updated first.
+var closedPullsSelection = fmt.Sprintf(
+	`    pullRequests(first: %d, after: $cursor, states: [CLOSED, MERGED], orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { createdAt updatedAt mergedAt }
+    }`, lookbackPerPage)
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLConnection[T any] struct {
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+	Nodes    []T             `json:"nodes"`
+}
+
+// graphQLActivityRepository is the answer about a repository to the selections about its activity,
+// each filling one of the connections.
+//
+//nolint:tagliatelle // GraphQL field names
+type graphQLActivityRepository struct {
+	DefaultBranchRef *struct {
+		Target struct {
+			History graphQLConnection[graphQLCommit] `json:"history"`
+		} `json:"target"`
+	} `json:"defaultBranchRef"`
+	Issues       graphQLConnection[graphQLIssue] `json:"issues"`
+	PullRequests graphQLConnection[graphQLPull]  `json:"pullRequests"`
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLIssue struct {
+	Number            int          `json:"number"`
+	CreatedAt         string       `json:"createdAt"`
+	ClosedAt          string       `json:"closedAt"`
+	AuthorAssociation string       `json:"authorAssociation"`
+	Comments          graphQLCount `json:"comments"`
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLPull struct {
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	MergedAt  string `json:"mergedAt"`
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLComment struct {
+	CreatedAt         string `json:"createdAt"`
+	AuthorAssociation string `json:"authorAssociation"`
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLCommit struct {
+	CommittedDate string `json:"committedDate"`
+	Author        struct {
+		Email string `json:"email"`
+		User  *struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"author"`
+}
+
+// authorKey tells the authors apart by their GitHub account, like stats/contributors,
+// or by their email when the commit is not linked to an account.
+func (c graphQLCommit) authorKey() string {
+	if c.Author.User != nil {
+		return "login:" + c.Author.User.Login
+	}
+
+	return "email:" + c.Author.Email
+}
+
+// graphQLActivityBatch reads the activity of the repositories a batch found. Each query asks for
+// all of them under the aliases r<index>, like the batch query, so that a batch takes as many
+// queries as a single repository would. A repository whose query fails keeps its error in errs
+// and is left out of the following queries.
+type graphQLActivityBatch struct {
+	client   *apiClient
+	endpoint string
+	token    string
+	owners   []string
+	names    []string
+	errs     []error
+}
+
+func newGraphQLActivityBatch(client *apiClient, endpoint, token string, repoURLs []string) *graphQLActivityBatch {
+	batch := &graphQLActivityBatch{
+		client:   client,
+		endpoint: endpoint,
+		token:    token,
+		owners:   make([]string, len(repoURLs)),
+		names:    make([]string, len(repoURLs)),
+		errs:     make([]error, len(repoURLs)),
+	}
+
+	for i, repoURL := range repoURLs {
+		batch.owners[i], batch.names[i], batch.errs[i] = ownerAndRepo(repoURL)
+	}
+
+	return batch
+}
+
+// pending are the indexes of the repositories without an error.
+func (b *graphQLActivityBatch) pending() []int {
+	var indexes []int
+
+	for index, err := range b.errs {
+		if err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return indexes
+}
+
+// queryError is the error about the repository at index in errs, either under its alias or about
+// the whole query.
+func (b *graphQLActivityBatch) queryError(errs []graphQLError, index int) error {
+	for _, graphQLErr := range errs {
+		if len(graphQLErr.Path) == 0 || graphQLErr.Path[0] == repositoryAlias(index) {
+			return fmt.Errorf("%w for %s/%s: %s", ErrGraphQLQueryFailed, b.owners[index], b.names[index],
+				graphQLErr.Message)
+		}
+	}
+
+	return nil
+}
+
+// queryActivity asks every repository at indexes for its selection in one query and decodes the
+// answer about each, nil for a repository that is not there. declarations and variables are
+// shared by all of them. An error about one repository fails it alone, while a failed request
+// fails all of them; the failed ones are missing from the answers.
+func queryActivity[T any](
+	ctx context.Context,
+	b *graphQLActivityBatch,
+	indexes []int,
+	declarations []string,
+	variables map[string]string,
+	selection func(index int) string,
+) map[int]*T {
+	request := graphQLRequest{Query: "", Variables: map[string]string{}}
+	maps.Copy(request.Variables, variables)
+
+	declarations = slices.Clone(declarations)
+	selections := make([]string, 0, len(indexes))
+
+	for _, index := range indexes {
+		alias := repositoryAlias(index)
+
+		declarations = append(declarations, fmt.Sprintf("$%sOwner: String!, $%sName: String!", alias, alias))
+		selections = append(selections, fmt.Sprintf("  %s: repository(owner: $%sOwner, name: $%sName) {\n%s\n  }",
+			alias, alias, alias, selection(index)))
+		request.Variables[alias+"Owner"] = b.owners[index]
+		request.Variables[alias+"Name"] = b.names[index]
+	}
+
+	request.Query = "query(" + strings.Join(declarations, ", ") + ") {\n" + strings.Join(selections, "\n") + "\n}"
+
+	headers := map[string]string{
+		"Authorization": "bearer " + b.token,
+	}
+
+	var response struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []graphQLError             `json:"errors"`
+	}
+
+	err := postJSONData(ctx, b.client, b.endpoint, headers, request, &response)
+
+	answers := make(map[int]*T, len(indexes))
+
+	for _, index := range indexes {
+		if err != nil {
+			b.errs[index] = err
+
+			continue
+		}
+
+		b.errs[index] = b.queryError(response.Errors, index)
+		if b.errs[index] != nil {
+			continue
+		}
+
+		var answer *T
+
+		data := response.Data[repositoryAlias(index)]
+		if len(data) > 0 {
+			decodeErr := json.Unmarshal(data, &answer)
+			if decodeErr != nil {
+				b.errs[index] = fmt.Errorf("failed to decode GraphQL data for %s/%s: %w",
+					b.owners[index], b.names[index], decodeErr)
+
+				continue
+			}
+		}
+
+		answers[index] = answer
+	}
+
+	return answers
+}
+
+// fetchConnections pages through the connection of selection, in which $cursor stands for the cursor
+// of each repository, for every repository at indexes. Each query asks for the next page of all
+// those with pages left, for at most maxPages pages, and a repository stops early once stop, when
+// given, reports that the rest is not needed. truncated tells which repositories had pages left.
+// A nil connection, like the history of an empty repository, has no items.
+func fetchConnections[T any](
+	ctx context.Context,
+	b *graphQLActivityBatch,
+	indexes []int,
+	selection string,
+	declarations []string,
+	variables map[string]string,
+	maxPages int,
+	at func(repository *graphQLActivityRepository) *graphQLConnection[T],
+	stop func(page []T) bool,
+) ([][]T, []bool) {
+	items := make([][]T, len(b.errs))
+	truncated := make([]bool, len(b.errs))
+	pageVariables := map[string]string{}
+	maps.Copy(pageVariables, variables)
+
+	for range maxPages {
+		if len(indexes) == 0 {
+			break
+		}
+
+		pageDeclarations := slices.Clone(declarations)
+		for _, index := range indexes {
+			pageDeclarations = append(pageDeclarations, "$"+repositoryAlias(index)+"Cursor: String")
+		}
+
+		repositories := queryActivity[graphQLActivityRepository](ctx, b, indexes, pageDeclarations, pageVariables,
+			func(index int) string {
+				return strings.ReplaceAll(selection, "$cursor", "$"+repositoryAlias(index)+"Cursor")
+			})
+
+		var next []int
+
+		for _, index := range indexes {
+			repository, ok := repositories[index]
+			if !ok || repository == nil {
+				continue
+			}
+
+			connection := at(repository)
+			if connection == nil {
+				continue
+			}
+
+			items[index] = append(items[index], connection.Nodes...)
+
+			if connection.PageInfo.HasNextPage && (stop == nil || !stop(connection.Nodes)) {
+				pageVariables[repositoryAlias(index)+"Cursor"] = connection.PageInfo.EndCursor
+				next = append(next, index)
+			}
+		}
+
+		indexes = next
+	}
+
+	for _, index := range indexes {
+		truncated[index] = true
+	}
+
+	return items, truncated
+}
+
+// fetchHistory reads the commits to the default branch since since, the newest first.
+func (b *graphQLActivityBatch) fetchHistory(ctx context.Context, since time.Time) ([][]graphQLCommit, []bool) {
+	variables := map[string]string{"since": since.UTC().Format(gitHubDateLayout)}
+
+	return fetchConnections(ctx, b, b.pending(), historySelection, []string{"$since: GitTimestamp!"}, variables,
+		maxHistoryPages, func(repository *graphQLActivityRepository) *graphQLConnection[graphQLCommit] {
+			if repository.DefaultBranchRef == nil {
+				return nil
+			}
+
+			return &repository.DefaultBranchRef.Target.History
+		}, nil)
+}
+
+// fetchWeeklyCommits counts the commits to the default branch in each of the 52 weeks ending with
+// the current one, the oldest first, like stats/commit_activity. One query asks for all of them as
+// the aliases w0 to w51, so busy repositories cost no more than quiet ones. An empty repository
+// has 52 weeks without commits; the counts are never pending, unlike the statistics.
+func (b *graphQLActivityBatch) fetchWeeklyCommits(ctx context.Context, now time.Time) map[int][]int {
+	first := weekStart(now).AddDate(0, 0, -(weeksOfYear-1)*daysOfWeek)
+	selections := make([]string, weeksOfYear)
+
+	for week := range weeksOfYear {
+		start := first.AddDate(0, 0, week*daysOfWeek)
+		selections[week] = fmt.Sprintf(`          w%d: history(since: "%s", until: "%s") { totalCount }`, week,
+			start.Format(gitHubDateLayout), start.AddDate(0, 0, daysOfWeek).Add(-time.Second).Format(gitHubDateLayout))
+	}
+
+	selection := "    defaultBranchRef {\n      target {\n        ... on Commit {\n" + strings.Join(selections, "\n") +
+		"\n        }\n      }\n    }"
+
+	type weeklyCounts struct {
+		DefaultBranchRef *struct {
+			Target map[string]graphQLCount `json:"target"`
+		} `json:"defaultBranchRef"` //nolint:tagliatelle // GraphQL field name
+	}
+
+	repositories := queryActivity[weeklyCounts](ctx, b, b.pending(), nil, nil,
+		func(int) string { return selection })
+
+	weeklyCommits := make(map[int][]int, len(repositories))
+
+	for index, repository := range repositories {
+		weeks := make([]int, weeksOfYear)
+
+		if repository != nil && repository.DefaultBranchRef != nil {
+			for week := range weeks {
+				weeks[week] = repository.DefaultBranchRef.Target["w"+strconv.Itoa(week)].TotalCount
+			}
+		}
+
+		weeklyCommits[index] = weeks
+	}
+
+	return weeklyCommits
+}
+
+// fetchIssueActivity reads the issues and pull requests of the lookback window starting at since
+// into the issueActivity of the REST analyzer, within the same limits: maxLookbackPages pages of
+// each, and the comments of the maxResponseSamples newest issues, read with one more query.
+func (b *graphQLActivityBatch) fetchIssueActivity(ctx context.Context, since time.Time) map[int]*issueActivity {
+	variables := map[string]string{"since": since.UTC().Format(gitHubDateLayout)}
+
+	issues, issuesTruncated := fetchConnections(ctx, b, b.pending(), issuesSelection, []string{"$since: DateTime!"},
+		variables, maxLookbackPages, func(repository *graphQLActivityRepository) *graphQLConnection[graphQLIssue] {
+			return &repository.Issues
+		}, nil)
+
+	pulls, _ := fetchConnections(ctx, b, b.pending(), closedPullsSelection, nil, nil, maxLookbackPages,
+		func(repository *graphQLActivityRepository) *graphQLConnection[graphQLPull] {
+			return &repository.PullRequests
+		}, func(page []graphQLPull) bool {
+			updatedAt, ok := parseGitHubTime(page[len(page)-1].UpdatedAt)
+
+			return ok && updatedAt.Before(since)
+		})
+
+	activities := map[int]*issueActivity{}
+
+	for _, index := range b.pending() {
+		activity := &issueActivity{
+			issues:          nil,
+			issuesTruncated: issuesTruncated[index],
+			pulls:           nil,
+			firstResponses:  map[int]time.Time{},
+		}
+
+		for _, issue := range issues[index] {
+			activity.issues = append(activity.issues, gitHubIssue{
+				Number:            issue.Number,
+				CreatedAt:         issue.CreatedAt,
+				ClosedAt:          issue.ClosedAt,
+				Comments:          issue.Comments.TotalCount,
+				AuthorAssociation: issue.AuthorAssociation,
+				PullRequest:       nil,
+			})
+		}
+
+		for _, pull := range pulls[index] {
+			activity.pulls = append(activity.pulls, gitHubPull(pull))
+		}
+
+		activities[index] = activity
+	}
+
+	b.fetchFirstResponses(ctx, activities, since)
+
+	for index := range activities {
+		if b.errs[index] != nil {
+			delete(activities, index)
+		}
+	}
+
+	return activities
+}
+
+// fetchFirstResponses reads the comments of the sampled issues of every repository with one query,
+// aliased i<number> for each issue, and keeps the first one by a maintainer.
+func (b *graphQLActivityBatch) fetchFirstResponses(
+	ctx context.Context,
+	activities map[int]*issueActivity,
+	since time.Time,
+) {
+	selections := map[int]string{}
+
+	for index, activity := range activities {
+		var issueSelections []string
+
+		for _, issue := range responseSample(activity.issues, since) {
+			if issue.Comments > 0 {
+				issueSelections = append(issueSelections, fmt.Sprintf(
+					"    i%d: issue(number: %d) { comments(first: %d) { nodes { createdAt authorAssociation } } }",
+					issue.Number, issue.Number, lookbackPerPage))
+			}
+		}
+
+		if len(issueSelections) > 0 {
+			selections[index] = strings.Join(issueSelections, "\n")
+		}
+	}
+
+	if len(selections) == 0 {
+		return
+	}
+
+	type issueComments map[string]*struct {
+		Comments graphQLConnection[graphQLComment] `json:"comments"`
+	}
+
+	indexes := slices.Sorted(maps.Keys(selections))
+	repositories := queryActivity[issueComments](ctx, b, indexes, nil, nil,
+		func(index int) string { return selections[index] })
+
+	for _, index := range indexes {
+		if b.errs[index] != nil {
+			b.errs[index] = fmt.Errorf("failed to read the comments of the issues: %w", b.errs[index])
+		}
+	}
+
+	for index, repository := range repositories {
+		if repository == nil {
+			continue
+		}
+
+		for alias, issue := range *repository {
+			number, err := strconv.Atoi(strings.TrimPrefix(alias, "i"))
+			if err != nil || issue == nil {
+				continue
+			}
+
+			for _, comment := range issue.Comments.Nodes {
+				respondedAt, ok := parseGitHubTime(comment.CreatedAt)
+				if ok && slices.Contains(maintainerAssociations, comment.AuthorAssociation) {
+					activities[index].firstResponses[number] = respondedAt
+
+					break
+				}
+			}
+		}
+	}
+}
+
+// addActivity fills the metrics the REST analyzer reads from the statistics of GitHub for the
+// repositories of batch, computing them from the commit history instead. A history cut at
+// maxHistoryPages leaves the metrics of the last year unknown, and those of the last 90 days too
+// unless the pages reach back that far. The weekly commits are counted by the API and have no such
+// limit. The responsiveness metrics are read like the REST analyzer does, unless the lookback is 0.
+// repoInfos are in the order of the batch; those whose activity cannot be read have an error in
+// batch.errs.
+func (g *GitHubGraphQLAnalyzer) addActivity(
+	ctx context.Context,
+	batch *graphQLActivityBatch,
+	repoInfos []*RepoInfo,
+	now time.Time,
+) {
+	commits, truncated := batch.fetchHistory(ctx, now.AddDate(0, 0, -daysOfYear))
+
+	for _, index := range batch.pending() {
+		repoInfo := repoInfos[index]
+		setContributorActivity(repoInfo, contributorStats(commits[index]), now)
+
+		if !truncated[index] {
+			continue
+		}
+
+		repoInfo.CommittersLastYear = nil
+		repoInfo.TopContributorShare = nil
+
+		oldest, ok := parseGitHubTime(commits[index][len(commits[index])-1].CommittedDate)
+		if !ok || !oldest.Before(now.AddDate(0, 0, -recentCommitterDays)) {
+			repoInfo.CommittersLast90Days = nil
+		}
+
+		repoInfo.Incomplete = append(repoInfo.Incomplete,
+			"more than "+strconv.Itoa(historyPerPage*maxHistoryPages)+" commits in the last year")
+	}
+
+	for index, weeklyCommits := range batch.fetchWeeklyCommits(ctx, now) {
+		setCommitActivity(repoInfos[index], weeklyCommits)
+	}
+
+	if g.options.lookbackMonths > 0 {
+		since := g.options.lookbackSince(now)
+
+		for index, activity := range batch.fetchIssueActivity(ctx, since) {
+			setResponsiveness(repoInfos[index], activity, since, now)
+		}
+	}
+}
+
+// contributorStats groups commits by author into the weekly counts of stats/contributors.
+func contributorStats(commits []graphQLCommit) []gitHubContributorStats {
+	var authors []string
+
+	weeks := map[string]map[int64]int{}
+
+	for _, commit := range commits {
+		committedAt, ok := parseGitHubTime(commit.CommittedDate)
+		if !ok {
+			continue
+		}
+
+		author := commit.authorKey()
+		if weeks[author] == nil {
+			weeks[author] = map[int64]int{}
+			authors = append(authors, author)
+		}
+
+		weeks[author][weekStart(committedAt).Unix()]++
+	}
+
+	contributors := make([]gitHubContributorStats, 0, len(authors))
+
+	for _, author := range authors {
+		var contributor gitHubContributorStats
+
+		for start, count := range weeks[author] {
+			contributor.Weeks = append(contributor.Weeks, gitHubContributorWeek{Start: start, Commits: count})
+		}
+
+		contributors = append(contributors, contributor)
+	}
+
+	return contributors
+}
+
+// weekStart is the Sunday, 00:00 UTC, starting the week of t, where GitHub's statistics start their weeks.
+func weekStart(t time.Time) time.Time {
+	day := t.UTC().Truncate(hoursOfDay * time.Hour)
+
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
@@ -0,0 +1,393 @@
+package analyzer_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+type activityCommit struct {
+	login string
+	date  time.Time
+}
+
+type activityIssue struct {
+	number      int
+	association string
+	createdAt   time.Time
+	closedAt    *time.Time
+	comments    []activityComment
+}
+
+type activityComment struct {
+	association string
+	createdAt   time.Time
+}
+
+type activityPull struct {
+	createdAt, updatedAt time.Time
+	mergedAt             *time.Time
+}
+
+// activityRepository serves team/lib from the REST and the GraphQL API of a GitHub Enterprise
+// host alike, so that both analyzers can be compared on the same repository.
+type activityRepository struct {
+	commits        []activityCommit // the newest first
+	issues         []activityIssue  // all updated in the lookback window
+	pulls          []activityPull   // closed ones, the most recently updated first
+	endless        bool             // answers every history page with one more to follow
+	historyQueries atomic.Int32
+}
+
+func formatTime(date *time.Time) any {
+	if date == nil {
+		return nil
+	}
+
+	return date.Format(time.RFC3339)
+}
+
+func (a *activityRepository) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/graphql" {
+		a.serveGraphQL(w, r)
+
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v3/repos/team/lib":
+		_, _ = w.Write([]byte(`{"name": "lib", "subscribers_count": 2, "stargazers_count": 5, "forks_count": 1,
+			"default_branch": "main"}`))
+	case "/api/v3/repos/team/lib/releases", "/api/v3/repos/team/lib/tags":
+		_, _ = w.Write([]byte(`[]`))
+	case "/api/v3/repos/team/lib/stats/commit_activity":
+		_ = json.NewEncoder(w).Encode(a.commitActivity())
+	case "/api/v3/repos/team/lib/pulls":
+		a.servePulls(w, r)
+	case "/api/v3/repos/team/lib/issues":
+		var issues []map[string]any
+
+		for _, issue := range a.issues {
+			issues = append(issues, map[string]any{
+				"number": issue.number, "created_at": issue.createdAt.Format(time.RFC3339),
+				"closed_at": formatTime(issue.closedAt), "comments": len(issue.comments),
+				"author_association": issue.association,
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(issues)
+	case "/api/v3/repos/team/lib/commits/main":
+		_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "` + a.commits[0].date.Format(time.RFC3339) + `"}}}`))
+	case "/api/v3/repos/team/lib/stats/contributors":
+		_ = json.NewEncoder(w).Encode(a.contributorStats())
+	default:
+		for _, issue := range a.issues {
+			if r.URL.Path == "/api/v3/repos/team/lib/issues/"+strconv.Itoa(issue.number)+"/comments" {
+				_ = json.NewEncoder(w).Encode(issue.commentNodes())
+
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (a *activityRepository) servePulls(w http.ResponseWriter, r *http.Request) {
+	pulls := []map[string]any{}
+
+	// open ones are only counted
+	if r.URL.Query().Get("state") == "closed" {
+		for _, pull := range a.pulls {
+			pulls = append(pulls, map[string]any{
+				"created_at": pull.createdAt.Format(time.RFC3339), "updated_at": pull.updatedAt.Format(time.RFC3339),
+				"merged_at": formatTime(pull.mergedAt),
+			})
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(pulls)
+}
+
+// commentNodes are the comments of issue, with the same fields in REST (snake_case, which
+// the analyzer reads) and GraphQL (camelCase).
+func (issue activityIssue) commentNodes() []map[string]any {
+	var comments []map[string]any
+
+	for _, comment := range issue.comments {
+		createdAt := comment.createdAt.Format(time.RFC3339)
+		comments = append(comments, map[string]any{
+			"created_at": createdAt, "author_association": comment.association,
+			"createdAt": createdAt, "authorAssociation": comment.association,
+		})
+	}
+
+	return comments
+}
+
+// weekStart is the Sunday starting the week of date, where the statistics of GitHub start their weeks.
+func weekStart(date time.Time) time.Time {
+	day := date.Truncate(24 * time.Hour)
+
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// commitActivity counts the commits like stats/commit_activity, in the 52 weeks up to the current one.
+func (a *activityRepository) commitActivity() []map[string]int {
+	current := weekStart(time.Now().UTC())
+	weeks := make([]map[string]int, 52)
+
+	for i := range weeks {
+		weeks[i] = map[string]int{"total": 0}
+	}
+
+	for _, commit := range a.commits {
+		week := 51 - int(current.Sub(weekStart(commit.date)).Hours()/24/7)
+		if week >= 0 {
+			weeks[week]["total"]++
+		}
+	}
+
+	return weeks
+}
+
+// contributorStats groups the commits like stats/contributors, into weeks starting on Sunday.
+func (a *activityRepository) contributorStats() []map[string]any {
+	var stats []map[string]any
+
+	weeks := map[string]map[int64]int{}
+
+	for _, commit := range a.commits {
+		start := weekStart(commit.date).Unix()
+
+		if weeks[commit.login] == nil {
+			weeks[commit.login] = map[int64]int{}
+		}
+
+		weeks[commit.login][start]++
+	}
+
+	for _, counts := range weeks {
+		var contributorWeeks []map[string]int64
+
+		for start, count := range counts {
+			contributorWeeks = append(contributorWeeks, map[string]int64{"w": start, "c": int64(count)})
+		}
+
+		stats = append(stats, map[string]any{"weeks": contributorWeeks})
+	}
+
+	return stats
+}
+
+var weekPattern = regexp.MustCompile(`(w\d+): history\(since: "([^"]+)", until: "([^"]+)"\)`)
+
+func (a *activityRepository) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string            `json:"query"`
+		Variables map[string]string `json:"variables"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&request)
+
+	var data map[string]any
+
+	switch {
+	case strings.Contains(request.Query, "stargazerCount"):
+		data = map[string]any{"r0": map[string]any{
+			"name": "lib", "watchers": map[string]int{"totalCount": 2}, "stargazerCount": 5, "forkCount": 1,
+			"issues": map[string]int{"totalCount": 0}, "pullRequests": map[string]int{"totalCount": 0},
+			"defaultBranchRef": map[string]any{
+				"name": "main", "target": map[string]string{"committedDate": a.commits[0].date.Format(time.RFC3339)},
+			},
+		}}
+	case strings.Contains(request.Query, "history(first:"):
+		a.historyQueries.Add(1)
+
+		since, _ := time.Parse(time.RFC3339, request.Variables["since"])
+
+		var nodes []map[string]any
+
+		for _, commit := range a.commits {
+			if !commit.date.Before(since) {
+				author := map[string]any{"email": commit.login + "@example.com", "user": map[string]string{"login": commit.login}}
+				nodes = append(nodes, map[string]any{"committedDate": commit.date.Format(time.RFC3339), "author": author})
+			}
+		}
+
+		history := map[string]any{"pageInfo": map[string]any{"hasNextPage": a.endless, "endCursor": "next"}, "nodes": nodes}
+		data = map[string]any{"r0": map[string]any{"defaultBranchRef": map[string]any{
+			"target": map[string]any{"history": history},
+		}}}
+	case strings.Contains(request.Query, "w0: history("):
+		weeks := map[string]any{}
+
+		for _, match := range weekPattern.FindAllStringSubmatch(request.Query, -1) {
+			since, _ := time.Parse(time.RFC3339, match[2])
+			until, _ := time.Parse(time.RFC3339, match[3])
+
+			count := 0
+
+			for _, commit := range a.commits {
+				if !commit.date.Before(since) && !commit.date.After(until) {
+					count++
+				}
+			}
+
+			weeks[match[1]] = map[string]int{"totalCount": count}
+		}
+
+		data = map[string]any{"r0": map[string]any{"defaultBranchRef": map[string]any{"target": weeks}}}
+	case strings.Contains(request.Query, "issues(first:"):
+		var nodes []map[string]any
+
+		for _, issue := range a.issues {
+			nodes = append(nodes, map[string]any{
+				"number": issue.number, "createdAt": issue.createdAt.Format(time.RFC3339),
+				"closedAt": formatTime(issue.closedAt), "comments": map[string]int{"totalCount": len(issue.comments)},
+				"authorAssociation": issue.association,
+			})
+		}
+
+		data = map[string]any{"r0": map[string]any{"issues": map[string]any{"nodes": nodes}}}
+	case strings.Contains(request.Query, "pullRequests(first:"):
+		var nodes []map[string]any
+
+		for _, pull := range a.pulls {
+			nodes = append(nodes, map[string]any{
+				"createdAt": pull.createdAt.Format(time.RFC3339), "updatedAt": pull.updatedAt.Format(time.RFC3339),
+				"mergedAt": formatTime(pull.mergedAt),
+			})
+		}
+
+		data = map[string]any{"r0": map[string]any{"pullRequests": map[string]any{"nodes": nodes}}}
+	case strings.Contains(request.Query, "issue(number:"):
+		issues := map[string]any{}
+
+		for _, issue := range a.issues {
+			if strings.Contains(request.Query, fmt.Sprintf("issue(number: %d)", issue.number)) {
+				issues["i"+strconv.Itoa(issue.number)] = map[string]any{"comments": map[string]any{"nodes": issue.commentNodes()}}
+			}
+		}
+
+		data = map[string]any{"r0": issues}
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func newActivityAnalyzers(
+	t *testing.T,
+	repository *activityRepository,
+	weights analyzer.ParameterWeights,
+) (analyzer.RepoAnalyzer, analyzer.RepoAnalyzer) {
+	t.Helper()
+
+	server := httptest.NewServer(repository)
+	t.Cleanup(server.Close)
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com="+server.URL+"/api/v3"))
+	require.NoError(t, hosts.SetTokens("ghe.example.com=token"))
+
+	options := []analyzer.Option{
+		analyzer.WithGitHubHosts(hosts), analyzer.WithRetryBudget(0), analyzer.WithLookbackMonths(6),
+	}
+
+	return analyzer.NewGitHubRepoAnalyzer("", weights, options...),
+		analyzer.NewGitHubGraphQLAnalyzer("", weights, options...)
+}
+
+func TestGitHubGraphQLAnalyzer_MatchesREST(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	daysAgoPtr := func(days int) *time.Time { return analyzer.Ptr(daysAgo(days)) }
+
+	repository := &activityRepository{commits: []activityCommit{
+		{"alice", daysAgo(3)},
+		{"alice", daysAgo(10)},
+		{"bob", daysAgo(40)},
+		{"alice", daysAgo(120)},
+		{"carol", daysAgo(200)},
+		{"carol", daysAgo(400)}, // before the last year
+	}, issues: []activityIssue{
+		{number: 4, association: "OWNER", createdAt: daysAgo(5), closedAt: daysAgoPtr(4)},
+		{number: 3, association: "NONE", createdAt: daysAgo(10)},
+		{number: 2, association: "NONE", createdAt: daysAgo(20), closedAt: daysAgoPtr(15), comments: []activityComment{
+			{"NONE", daysAgo(20).Add(time.Hour)}, {"MEMBER", daysAgo(19)},
+		}},
+		{number: 1, association: "NONE", createdAt: daysAgo(300), closedAt: daysAgoPtr(30)}, // opened before the window
+	}, pulls: []activityPull{
+		{createdAt: daysAgo(12), updatedAt: daysAgo(9), mergedAt: daysAgoPtr(9)},
+		{createdAt: daysAgo(34), updatedAt: daysAgo(28), mergedAt: daysAgoPtr(30)},
+		{createdAt: daysAgo(60), updatedAt: daysAgo(50)}, // closed without merging
+	}}
+
+	weights := analyzer.ParameterWeights{
+		Stars: 1, CommittersLast90Days: 10, CommittersLastYear: 5, TopContributorShare: -1,
+		MedianIssueResponse: -1, MedianMergeTime: -2, IssueCloseRatio: 1, ActiveWeeks: 2,
+	}
+	restAnalyzer, graphQLAnalyzer := newActivityAnalyzers(t, repository, weights)
+
+	repoURLs := []string{"https://ghe.example.com/team/lib"}
+	rest := restAnalyzer.FetchRepoInfo(context.Background(), repoURLs)[0]
+	graphQL := graphQLAnalyzer.FetchRepoInfo(context.Background(), repoURLs)[0]
+
+	require.False(t, rest.Skip, rest.SkipReason)
+	require.False(t, graphQL.Skip, graphQL.SkipReason)
+
+	assert.Equal(t, analyzer.Ptr(2), rest.CommittersLast90Days)
+	assert.Equal(t, analyzer.Ptr(3), rest.CommittersLastYear)
+	assert.Equal(t, analyzer.Ptr(60), rest.TopContributorShare)
+
+	assert.Equal(t, analyzer.Ptr(5), rest.ActiveWeeksLastYear)
+	assert.Len(t, rest.WeeklyCommits, 52)
+	assert.Equal(t, analyzer.Ptr(100), rest.IssueCloseRatio)
+	assert.Equal(t, analyzer.Ptr((24+240)/2), rest.MedianIssueResponseHours)
+	assert.Equal(t, analyzer.Ptr((3+4)/2), rest.MedianMergeDays)
+
+	assert.Equal(t, rest.CommittersLast90Days, graphQL.CommittersLast90Days)
+	assert.Equal(t, rest.CommittersLastYear, graphQL.CommittersLastYear)
+	assert.Equal(t, rest.TopContributorShare, graphQL.TopContributorShare)
+	assert.Equal(t, rest.ActiveWeeksLastYear, graphQL.ActiveWeeksLastYear)
+	assert.Equal(t, rest.WeeklyCommits, graphQL.WeeklyCommits)
+	assert.Equal(t, rest.IssueCloseRatio, graphQL.IssueCloseRatio)
+	assert.Equal(t, rest.MedianIssueResponseHours, graphQL.MedianIssueResponseHours)
+	assert.Equal(t, rest.MedianMergeDays, graphQL.MedianMergeDays)
+	assert.Equal(t, rest.Score, graphQL.Score)
+	assert.Empty(t, graphQL.Incomplete)
+}
+
+func TestGitHubGraphQLAnalyzer_TruncatedHistory(t *testing.T) {
+	t.Parallel()
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	repository := &activityRepository{commits: []activityCommit{{"alice", yesterday}}, endless: true}
+
+	_, graphQLAnalyzer := newActivityAnalyzers(t, repository, analyzer.ParameterWeights{CommittersLastYear: 1})
+
+	repoInfo := graphQLAnalyzer.FetchRepoInfo(context.Background(), []string{"https://ghe.example.com/team/lib"})[0]
+
+	require.False(t, repoInfo.Skip, repoInfo.SkipReason)
+	assert.Equal(t, int32(10), repository.historyQueries.Load())
+	assert.Nil(t, repoInfo.CommittersLastYear)
+	assert.Nil(t, repoInfo.TopContributorShare)
+	assert.Nil(t, repoInfo.CommittersLast90Days, "the pages do not reach back 90 days")
+	assert.Equal(t, []string{"more than 1000 commits in the last year"}, repoInfo.Incomplete)
+}
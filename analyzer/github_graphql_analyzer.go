@@ -0,0 +1,424 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+var ErrGraphQLRepositoryNotFound = errors.New("repository not returned by the GraphQL API")
+
+// repositoryFields are the fields asked for each aliased repository. The tags are ordered by
+// the date of their commit, which is read directly or through the annotated tag.
+var repositoryFields = fmt.Sprintf(`{
+    name
+    watchers { totalCount }
+    stargazerCount
+    forkCount
+    issues(states: OPEN) { totalCount }
+    pullRequests(states: OPEN) { totalCount }
+    isArchived
+    createdAt
+    defaultBranchRef { name target { ... on Commit { committedDate } } }
+    releases(first: %d, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { publishedAt isDraft } }
+    refs(refPrefix: "refs/tags/", first: %d, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) {
+      nodes { target { ... on Commit { committedDate } ... on Tag { target { ... on Commit { committedDate } } } } }
+    }
+  }`, releasesPerPage, maxTagDates)
+
+type graphQLRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path"` // aliases and field names, or list indexes
+}
+
+type graphQLResponse struct {
+	Data   map[string]*graphQLRepository `json:"data"`
+	Errors []graphQLError                `json:"errors"`
+}
+
+type graphQLCount struct {
+	TotalCount int `json:"totalCount"` //nolint:tagliatelle // GraphQL field name
+}
+
+//nolint:tagliatelle // GraphQL field names
+type graphQLRepository struct {
+	Name           string       `json:"name"`
+	Watchers       graphQLCount `json:"watchers"`
+	StargazerCount int          `json:"stargazerCount"`
+	ForkCount      int          `json:"forkCount"`
+	Issues         graphQLCount `json:"issues"`
+	PullRequests   graphQLCount `json:"pullRequests"`
+	IsArchived     bool         `json:"isArchived"`
+	CreatedAt      string       `json:"createdAt"`
+
+	DefaultBranchRef *struct {
+		Name   string `json:"name"`
+		Target struct {
+			CommittedDate string `json:"committedDate"`
+		} `json:"target"`
+	} `json:"defaultBranchRef"`
+
+	Releases struct {
+		Nodes []struct {
+			PublishedAt string `json:"publishedAt"`
+			IsDraft     bool   `json:"isDraft"`
+		} `json:"nodes"`
+	} `json:"releases"`
+
+	Refs struct {
+		Nodes []struct {
+			Target graphQLTagTarget `json:"target"`
+		} `json:"nodes"`
+	} `json:"refs"`
+}
+
+// graphQLTagTarget is the commit of a lightweight tag, or an annotated tag pointing to one.
+//
+//nolint:tagliatelle // GraphQL field names
+type graphQLTagTarget struct {
+	CommittedDate string `json:"committedDate"`
+	Target        *struct {
+		CommittedDate string `json:"committedDate"`
+	} `json:"target"`
+}
+
+// releaseDates are the dates of the published releases, or of the tags when there are none,
+// like fetchReleaseDates reads them from the REST API.
+func (r *graphQLRepository) releaseDates() []string {
+	var dates []string
+
+	for _, release := range r.Releases.Nodes {
+		if !release.IsDraft && release.PublishedAt != "" {
+			dates = append(dates, release.PublishedAt)
+		}
+	}
+
+	if len(dates) > 0 {
+		return dates
+	}
+
+	for _, ref := range r.Refs.Nodes {
+		switch {
+		case ref.Target.CommittedDate != "":
+			dates = append(dates, ref.Target.CommittedDate)
+		case ref.Target.Target != nil:
+			dates = append(dates, ref.Target.Target.CommittedDate)
+		}
+	}
+
+	return dates
+}
+
+// GitHubGraphQLAnalyzer fetches many repositories per request with one aliased GraphQL query,
+// instead of the several REST calls GitHubRepoAnalyzer makes for every repository. Their activity
+// takes a few more queries per batch, each about all of them, see addActivity.
+type GitHubGraphQLAnalyzer struct {
+	githubToken string
+	weights     ParameterWeights
+	options     options
+}
+
+func NewGitHubGraphQLAnalyzer(token string, weights ParameterWeights, opts ...Option) *GitHubGraphQLAnalyzer {
+	return &GitHubGraphQLAnalyzer{
+		githubToken: token,
+		weights:     weights,
+		options:     newOptions(opts),
+	}
+}
+
+// graphQLBatch is up to graphQLBatchSize repositories on the same GitHub host.
+type graphQLBatch struct {
+	host    utils.GitHubHost
+	indexes []int // positions in the repository list
+}
+
+// FetchRepoInfo splits the repositories into batches per GitHub host and fetches each
+// batch with one query, then the activity of the repositories it found. The results are in
+// the order of repositoryUrls, and a repository missing from an answer is skipped alone.
+func (g *GitHubGraphQLAnalyzer) FetchRepoInfo(
+	ctx context.Context,
+	repositoryUrls []string,
+) []RepoInfo {
+	libraryInfoList := make([]RepoInfo, len(repositoryUrls))
+	client := newAPIClient(g.options, utils.CacheSourceGitHub)
+
+	for i, repoURL := range repositoryUrls {
+		libraryInfoList[i] = RepoInfo{
+			RepositoryURL: repoURL,
+			Skip:          true,
+			SkipReason:    "Canceled before fetching " + repoURL,
+		}
+	}
+
+	batches := g.makeBatches(repositoryUrls, libraryInfoList)
+
+	err := utils.ForEachIndex(ctx, g.options.concurrency, len(batches), func(batch int) {
+		g.fetchBatch(ctx, client, batches[batch], repositoryUrls, libraryInfoList)
+		g.fetchActivities(ctx, client, batches[batch], libraryInfoList)
+	})
+	if err != nil {
+		utils.StdErrorPrintln("Fetching from GitHub was interrupted: %v", err)
+	}
+
+	tagForge(libraryInfoList, ForgeGitHub)
+
+	return libraryInfoList
+}
+
+// fetchActivities adds the activity of the repositories batch found and scores them.
+// Repositories whose activity cannot be read are skipped, like by the REST analyzer.
+func (g *GitHubGraphQLAnalyzer) fetchActivities(
+	ctx context.Context,
+	client *apiClient,
+	batch graphQLBatch,
+	results []RepoInfo,
+) {
+	var (
+		repoURLs  []string
+		repoInfos []*RepoInfo
+	)
+
+	for _, index := range batch.indexes {
+		if !results[index].Skip {
+			repoURLs = append(repoURLs, results[index].RepositoryURL)
+			repoInfos = append(repoInfos, &results[index])
+		}
+	}
+
+	if len(repoInfos) == 0 {
+		return
+	}
+
+	activityBatch := newGraphQLActivityBatch(client, g.endpoint(batch.host), batch.host.Token, repoURLs)
+	g.addActivity(ctx, activityBatch, repoInfos, time.Now())
+
+	for i, repoInfo := range repoInfos {
+		err := activityBatch.errs[i]
+		if err != nil {
+			repoURL := repoInfo.RepositoryURL
+			*repoInfo = RepoInfo{RepositoryURL: repoURL, Skip: true, SkipReason: skipReason(ForgeGitHub, repoURL, err)}
+
+			utils.StdErrorPrintln("Failed fetching %s, error details: %v", repoURL, err)
+
+			continue
+		}
+
+		CalcScore(repoInfo, &g.weights)
+	}
+}
+
+// makeBatches groups the repositories by host, keeping their order within a host.
+// Repositories on no configured host, or whose URL names no repository, are skipped right away.
+func (g *GitHubGraphQLAnalyzer) makeBatches(repositoryUrls []string, results []RepoInfo) []graphQLBatch {
+	var (
+		batches []graphQLBatch
+		open    = map[string]int{} // host name to its last, not yet full batch
+	)
+
+	for i, repoURL := range repositoryUrls {
+		host, err := g.options.host(repoURL, g.githubToken)
+		if err == nil {
+			_, _, err = ownerAndRepo(repoURL)
+		}
+
+		if err != nil {
+			results[i] = RepoInfo{RepositoryURL: repoURL, Skip: true, SkipReason: skipReason(ForgeGitHub, repoURL, err)}
+
+			continue
+		}
+
+		last, ok := open[host.Name]
+		if !ok || len(batches[last].indexes) == g.options.graphQLBatchSize {
+			batches = append(batches, graphQLBatch{host: host, indexes: nil})
+			last = len(batches) - 1
+			open[host.Name] = last
+		}
+
+		batches[last].indexes = append(batches[last].indexes, i)
+	}
+
+	return batches
+}
+
+// fetchBatch writes the result of every repository of batch to its position in results.
+func (g *GitHubGraphQLAnalyzer) fetchBatch(
+	ctx context.Context,
+	client *apiClient,
+	batch graphQLBatch,
+	repositoryUrls []string,
+	results []RepoInfo,
+) {
+	repoURLs := make([]string, len(batch.indexes))
+	for i, index := range batch.indexes {
+		repoURLs[i] = repositoryUrls[index]
+	}
+
+	utils.DebugPrintln("Fetching " + strconv.Itoa(len(repoURLs)) + " repositories from " + batch.host.Name +
+		" with GraphQL")
+
+	repositories, queryErr := g.queryRepositories(ctx, client, batch.host, repoURLs)
+
+	for i, repoURL := range repoURLs {
+		var libraryInfo *RepoInfo
+
+		err := queryErr
+		if err == nil {
+			libraryInfo, err = g.toRepoInfo(repositories, i)
+		}
+
+		if err != nil {
+			libraryInfo = &RepoInfo{
+				Skip:       true,
+				SkipReason: skipReason(ForgeGitHub, repoURL, err),
+			}
+
+			utils.StdErrorPrintln("Failed fetching %s, error details: %v", repoURL, err)
+		}
+
+		libraryInfo.RepositoryURL = repoURL
+		results[batch.indexes[i]] = *libraryInfo
+	}
+}
+
+func (g *GitHubGraphQLAnalyzer) queryRepositories(
+	ctx context.Context,
+	client *apiClient,
+	host utils.GitHubHost,
+	repoURLs []string,
+) (*graphQLResponse, error) {
+	if host.Token == "" {
+		return nil, fmt.Errorf("%w for %s", ErrGitHubTokenNotSet, host.Name)
+	}
+
+	request := buildRepositoriesQuery(repoURLs)
+
+	headers := map[string]string{
+		"Authorization": "bearer " + host.Token,
+	}
+
+	var response graphQLResponse
+
+	err := postJSONData(ctx, client, g.endpoint(host), headers, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// endpoint is the GraphQL API of host. WithGraphQLEndpoint replaces the one of github.com only.
+func (g *GitHubGraphQLAnalyzer) endpoint(host utils.GitHubHost) string {
+	if host.Name == utils.PublicGitHubHost {
+		return g.options.graphQLEndpoint
+	}
+
+	return host.GraphQLURL()
+}
+
+// toRepoInfo reads the repository under the alias of index. It is scored once its activity is added.
+func (g *GitHubGraphQLAnalyzer) toRepoInfo(response *graphQLResponse, index int) (*RepoInfo, error) {
+	alias := repositoryAlias(index)
+
+	repository := response.Data[alias]
+	if repository == nil {
+		for _, graphQLErr := range response.Errors {
+			if len(graphQLErr.Path) > 0 && graphQLErr.Path[0] == alias {
+				return nil, fmt.Errorf("%w: %s", ErrGraphQLRepositoryNotFound, graphQLErr.Message)
+			}
+		}
+
+		return nil, ErrGraphQLRepositoryNotFound
+	}
+
+	repoData := &RepoData{
+		Name:             repository.Name,
+		SubscribersCount: repository.Watchers.TotalCount,
+		StargazersCount:  repository.StargazerCount,
+		ForksCount:       repository.ForkCount,
+		OpenIssuesCount:  repository.Issues.TotalCount + repository.PullRequests.TotalCount, // as in the REST API
+		Archived:         repository.IsArchived,
+		DefaultBranch:    "",
+		CreatedAt:        repository.CreatedAt,
+	}
+
+	lastCommitDate := ""
+	if repository.DefaultBranchRef != nil {
+		repoData.DefaultBranch = repository.DefaultBranchRef.Name
+		lastCommitDate = repository.DefaultBranchRef.Target.CommittedDate
+	}
+
+	repoInfo := createRepoInfo(repoData, repository.PullRequests.TotalCount, lastCommitDate)
+	setReleaseCadence(repoInfo, repository.releaseDates(), time.Now())
+
+	return repoInfo, nil
+}
+
+func repositoryAlias(index int) string {
+	return "r" + strconv.Itoa(index)
+}
+
+// buildRepositoriesQuery asks for every repository under the alias r<index>,
+// passing owners and names as variables.
+func buildRepositoriesQuery(repoURLs []string) graphQLRequest {
+	var (
+		declarations []string
+		selections   []string
+	)
+
+	variables := map[string]string{}
+
+	for i, repoURL := range repoURLs {
+		owner, repo, _ := ownerAndRepo(repoURL) // checked by makeBatches
+		alias := repositoryAlias(i)
+
+		declarations = append(declarations, fmt.Sprintf("$%sOwner: String!, $%sName: String!", alias, alias))
+		selections = append(selections,
+			fmt.Sprintf("  %s: repository(owner: $%sOwner, name: $%sName) %s", alias, alias, alias, repositoryFields))
+		variables[alias+"Owner"] = owner
+		variables[alias+"Name"] = repo
+	}
+
+	query := "query(" + strings.Join(declarations, ", ") + ") {\n" + strings.Join(selections, "\n") + "\n}"
+
+	return graphQLRequest{Query: query, Variables: variables}
+}
+
+func postJSONData(
+	ctx context.Context,
+	client *apiClient,
+	url string,
+	headers map[string]string,
+	body interface{},
+	result interface{},
+) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON request for URL %s: %w", url, err)
+	}
+
+	headers["Content-Type"] = "application/json"
+
+	responseBody, err := client.send(ctx, http.MethodPost, url, headers, payload)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(responseBody, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode JSON response for URL %s: %w", url, err)
+	}
+
+	return nil
+}
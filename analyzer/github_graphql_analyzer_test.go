@@ -0,0 +1,291 @@
+package analyzer_test
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+type graphQLStandIn struct {
+	token           string // expected token, dummy-token when empty
+	mu              sync.Mutex
+	queries         []map[string]any // the batch queries
+	activityQueries []string         // the queries about the activity of the repositories found
+	failActivity    string           // owner/name whose activity is answered with an error
+}
+
+// ServeHTTP answers every aliased repository query from a small in-memory table.
+// Repositories that are not in the table come back as null with an error, like the real API.
+func (s *graphQLStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "bearer "+cmp.Or(s.token, "dummy-token") {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	var request struct {
+		Query     string            `json:"query"`
+		Variables map[string]string `json:"variables"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&request)
+
+	// the queries about the activity find every repository with an empty history
+	if !strings.Contains(request.Query, "stargazerCount") {
+		s.mu.Lock()
+		s.activityQueries = append(s.activityQueries, request.Query)
+		s.mu.Unlock()
+
+		data := map[string]any{}
+
+		var errs []map[string]any
+
+		for key, owner := range request.Variables {
+			alias, found := strings.CutSuffix(key, "Owner")
+			if !found {
+				continue
+			}
+
+			if owner+"/"+request.Variables[alias+"Name"] == s.failActivity {
+				data[alias] = nil
+				errs = append(errs, map[string]any{"path": []string{alias}, "message": "Something went wrong"})
+
+				continue
+			}
+
+			data[alias] = map[string]any{}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "errors": errs})
+
+		return
+	}
+
+	s.mu.Lock()
+	s.queries = append(s.queries, map[string]any{"query": request.Query, "variables": request.Variables})
+	s.mu.Unlock()
+
+	repositories := map[string]map[string]any{
+		"owner/one": {
+			"name": "one", "watchers": map[string]int{"totalCount": 3}, "stargazerCount": 10, "forkCount": 2,
+			"issues": map[string]int{"totalCount": 4}, "pullRequests": map[string]int{"totalCount": 1},
+			"isArchived": false,
+			"defaultBranchRef": map[string]any{
+				"name": "main", "target": map[string]string{"committedDate": "2024-01-01T00:00:00Z"},
+			},
+			"releases": map[string]any{"nodes": []map[string]any{
+				{"publishedAt": "2024-01-01T00:00:00Z", "isDraft": false},
+				{"publishedAt": nil, "isDraft": true},
+				{"publishedAt": "2023-12-02T00:00:00Z", "isDraft": false},
+			}},
+		},
+		"owner/two": {
+			"name": "two", "watchers": map[string]int{"totalCount": 0}, "stargazerCount": 1, "forkCount": 0,
+			"issues": map[string]int{"totalCount": 0}, "pullRequests": map[string]int{"totalCount": 0},
+			"isArchived": true,
+			"defaultBranchRef": map[string]any{
+				"name": "master", "target": map[string]string{"committedDate": "2020-01-01T00:00:00Z"},
+			},
+			"releases": map[string]any{"nodes": []map[string]any{}},
+			"refs": map[string]any{"nodes": []map[string]any{
+				{"target": map[string]any{"committedDate": "2020-01-01T00:00:00Z"}},
+				{"target": map[string]any{"target": map[string]string{"committedDate": "2019-12-02T00:00:00Z"}}},
+			}},
+		},
+	}
+
+	data := map[string]any{}
+
+	var errs []map[string]any
+
+	for key, owner := range request.Variables {
+		alias, found := strings.CutSuffix(key, "Owner")
+		if !found {
+			continue
+		}
+
+		repository, ok := repositories[owner+"/"+request.Variables[alias+"Name"]]
+		if !ok {
+			data[alias] = nil
+			errs = append(errs, map[string]any{"type": "NOT_FOUND", "path": []string{alias}, "message": "Could not resolve"})
+
+			continue
+		}
+
+		data[alias] = repository
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "errors": errs})
+}
+
+func TestGitHubGraphQLAnalyzer_FetchRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	standIn := &graphQLStandIn{}
+	server := httptest.NewServer(standIn)
+
+	defer server.Close()
+
+	repoURLs := []string{
+		"https://github.com/owner/one",
+		"https://github.com/owner/missing",
+		"https://github.com/owner/two.git",
+	}
+
+	repoAnalyzer := analyzer.NewGitHubGraphQLAnalyzer("dummy-token",
+		analyzer.ParameterWeights{Stars: 1, Archived: -100},
+		analyzer.WithGraphQLEndpoint(server.URL),
+		analyzer.WithGraphQLBatchSize(2),
+		analyzer.WithConcurrency(2),
+	)
+
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), repoURLs)
+	require.Len(t, repoInfos, 3)
+
+	one := repoInfos[0]
+	assert.Equal(t, "https://github.com/owner/one", one.RepositoryURL)
+	assert.Equal(t, "one", one.RepositoryName)
+	assert.Equal(t, analyzer.Ptr(3), one.Watchers)
+	assert.Equal(t, analyzer.Ptr(10), one.Stars)
+	assert.Equal(t, analyzer.Ptr(2), one.Forks)
+	assert.Equal(t, analyzer.Ptr(4), one.OpenIssues)
+	assert.Equal(t, analyzer.Ptr(1), one.OpenPullRequests)
+	assert.Equal(t, analyzer.Ptr("2024-01-01T00:00:00Z"), one.LastCommitDate)
+	assert.Equal(t, analyzer.Ptr("2024-01-01T00:00:00Z"), one.LastReleaseDate)
+	assert.Equal(t, analyzer.Ptr(30), one.MedianReleaseGapDays)
+	assert.Equal(t, analyzer.Ptr(0), one.ActiveWeeksLastYear, "an empty history has weeks without commits")
+	assert.Equal(t, 10, one.Score)
+	assert.False(t, one.Skip)
+
+	assert.True(t, repoInfos[1].Skip)
+	assert.Equal(t, "Failed fetching https://github.com/owner/missing from GitHub", repoInfos[1].SkipReason)
+
+	two := repoInfos[2]
+	assert.Equal(t, "two", two.RepositoryName)
+	assert.Equal(t, analyzer.Ptr(true), two.Archived)
+	assert.Equal(t, analyzer.Ptr("2020-01-01T00:00:00Z"), two.LastReleaseDate)
+	assert.Equal(t, analyzer.Ptr(30), two.MedianReleaseGapDays)
+	assert.Equal(t, analyzer.Ptr(0), two.ReleasesLastYear)
+	assert.Equal(t, -99, two.Score)
+
+	// three repositories in batches of two need two queries
+	require.Len(t, standIn.queries, 2)
+
+	for _, query := range standIn.queries {
+		assert.Contains(t, query["query"], "r0: repository(owner: $r0Owner, name: $r0Name)")
+	}
+}
+
+func TestGitHubGraphQLAnalyzer_RequestFailureSkipsBatch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	defer server.Close()
+
+	repoAnalyzer := analyzer.NewGitHubGraphQLAnalyzer("dummy-token", analyzer.NewParameterWeights(),
+		analyzer.WithGraphQLEndpoint(server.URL), analyzer.WithRetryBudget(0))
+
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(),
+		[]string{"https://github.com/owner/one", "https://github.com/owner/two"})
+	require.Len(t, repoInfos, 2)
+
+	for _, repoInfo := range repoInfos {
+		assert.True(t, repoInfo.Skip)
+		assert.Equal(t, "Rate limited or temporarily unavailable while fetching "+repoInfo.RepositoryURL+
+			" from GitHub, retry later", repoInfo.SkipReason)
+	}
+
+	assert.Equal(t, "https://github.com/owner/two", repoInfos[1].RepositoryURL)
+}
+
+func TestGitHubGraphQLAnalyzer_ActivityErrorSkipsRepositoryAlone(t *testing.T) {
+	t.Parallel()
+
+	standIn := &graphQLStandIn{failActivity: "owner/two"}
+	server := httptest.NewServer(standIn)
+
+	defer server.Close()
+
+	repoAnalyzer := analyzer.NewGitHubGraphQLAnalyzer("dummy-token", analyzer.ParameterWeights{Stars: 1},
+		analyzer.WithGraphQLEndpoint(server.URL))
+
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(),
+		[]string{"https://github.com/owner/one", "https://github.com/owner/two"})
+	require.Len(t, repoInfos, 2)
+
+	assert.False(t, repoInfos[0].Skip, repoInfos[0].SkipReason)
+	assert.Equal(t, 10, repoInfos[0].Score)
+
+	assert.True(t, repoInfos[1].Skip)
+	assert.Equal(t, "https://github.com/owner/two", repoInfos[1].RepositoryURL)
+	assert.Equal(t, "Failed fetching https://github.com/owner/two from GitHub", repoInfos[1].SkipReason)
+
+	// the failed repository is left out of the weekly commits query
+	require.Len(t, standIn.activityQueries, 2)
+	assert.NotContains(t, standIn.activityQueries[1], "r1: repository")
+}
+
+func TestGitHubGraphQLAnalyzer_BatchesPerHost(t *testing.T) {
+	t.Parallel()
+
+	public := &graphQLStandIn{}
+	publicServer := httptest.NewServer(public)
+
+	defer publicServer.Close()
+
+	enterprise := &graphQLStandIn{token: "ghe-token"}
+	enterpriseServer := httptest.NewServer(enterprise)
+
+	defer enterpriseServer.Close()
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com="+enterpriseServer.URL+"/api/v3"))
+	require.NoError(t, hosts.SetTokens("ghe.example.com=ghe-token"))
+
+	repoAnalyzer := analyzer.NewGitHubGraphQLAnalyzer("dummy-token", analyzer.ParameterWeights{Stars: 1},
+		analyzer.WithGraphQLEndpoint(publicServer.URL),
+		analyzer.WithGitHubHosts(hosts),
+		analyzer.WithGraphQLBatchSize(2),
+	)
+
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://github.com/owner/one",
+		"https://ghe.example.com/owner/two",
+		"https://github.com/owner/two",
+	})
+	require.Len(t, repoInfos, 3)
+
+	assert.Equal(t, analyzer.Ptr(10), repoInfos[0].Stars)
+	assert.Equal(t, "https://ghe.example.com/owner/two", repoInfos[1].RepositoryURL)
+	assert.Equal(t, "two", repoInfos[1].RepositoryName)
+	assert.Equal(t, "two", repoInfos[2].RepositoryName)
+
+	// both github.com repositories fit in one batch, the Enterprise one needs its own
+	assert.Len(t, public.queries, 1)
+	assert.Len(t, enterprise.queries, 1)
+
+	// the activity of both github.com repositories is read together, with one query for the
+	// history and one for the weekly commits
+	require.Len(t, public.activityQueries, 2)
+
+	for _, query := range public.activityQueries {
+		assert.Contains(t, query, "r0: repository(owner: $r0Owner, name: $r0Name)")
+		assert.Contains(t, query, "r1: repository(owner: $r1Owner, name: $r1Name)")
+	}
+
+	assert.Len(t, enterprise.activityQueries, 2)
+}
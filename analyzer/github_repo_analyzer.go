@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 
 var (
 	ErrGitHubTokenNotSet           = errors.New("GitHub token not set")
+	ErrUnknownGitHubHost           = errors.New("repository is not on a configured GitHub host")
 	ErrFailedToAssertDefaultBranch = errors.New("failed to assert type for default_branch")
 	ErrFailedToAssertDate          = errors.New("failed to assert type for date")
 
@@ -27,8 +30,9 @@ var (
 )
 
 const (
-	hoursOfDay = 24
-	timeOutSec = 5
+	hoursOfDay       = 24
+	timeOutSec       = 5
+	gitHubDateLayout = "2006-01-02T15:04:05Z"
 )
 
 type RepoData struct {
@@ -39,6 +43,7 @@ type RepoData struct {
 	OpenIssuesCount  int    `json:"open_issues_count"`
 	Archived         bool   `json:"archived"`
 	DefaultBranch    string `json:"default_branch"`
+	CreatedAt        string `json:"created_at"`
 }
 
 type CommitData struct {
@@ -49,108 +54,137 @@ type CommitData struct {
 	} `json:"commit"`
 }
 
-type GitHubRepoInfo struct {
-	RepositoryName string
-	Watchers       int
-	Stars          int
-	Forks          int
-	OpenIssues     int
-	LastCommitDate string
-	GithubRepoURL  string
-	Archived       bool
-	Score          int
-	Skip           bool   // スキップするかどうかのフラグ
-	SkipReason     string // スキップ理由
-}
-
 type GitHubRepoAnalyzer struct {
 	githubToken string
 	weights     ParameterWeights
+	options     options
 }
 
-func NewGitHubRepoAnalyzer(token string, weights ParameterWeights) *GitHubRepoAnalyzer {
+func NewGitHubRepoAnalyzer(token string, weights ParameterWeights, opts ...Option) *GitHubRepoAnalyzer {
 	return &GitHubRepoAnalyzer{
 		githubToken: token,
 		weights:     weights,
+		options:     newOptions(opts),
 	}
 }
 
-// FetchInfo fetches information for each repository
-func (g *GitHubRepoAnalyzer) FetchGithubInfo(repositoryUrls []string) []GitHubRepoInfo {
-	libraryInfoList := make([]GitHubRepoInfo, 0, len(repositoryUrls))
-	client := &http.Client{}
-
-	for _, repoURL := range repositoryUrls {
-		utils.DebugPrintln("Fetching: " + repoURL)
-
-		libraryInfo, err := g.getGitHubInfo(client, repoURL)
-		if err != nil {
-			libraryInfo = &GitHubRepoInfo{
-				Skip:       true,
-				SkipReason: "Failed fetching " + repoURL + " from GitHub",
-			}
-
-			utils.StdErrorPrintln("Failed fetching %s, error details: %v", repoURL, err)
-		}
-
-		libraryInfo.GithubRepoURL = repoURL
-		libraryInfoList = append(libraryInfoList, *libraryInfo)
-	}
-
-	return libraryInfoList
+// FetchRepoInfo fetches the repositories with a bounded number of workers.
+// The results are in the order of repositoryUrls. When ctx is canceled, the
+// repositories not fetched yet are returned as skipped.
+func (g *GitHubRepoAnalyzer) FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo {
+	client := newAPIClient(g.options, utils.CacheSourceGitHub)
+
+	return fetchEach(ctx, g.options, ForgeGitHub, repositoryUrls, func(ctx context.Context, repoURL string) (
+		*RepoInfo, error,
+	) {
+		return g.getGitHubInfo(ctx, client, repoURL)
+	})
 }
 
 func (g *GitHubRepoAnalyzer) getGitHubInfo(
-	client *http.Client,
+	ctx context.Context,
+	client *apiClient,
 	repoURL string,
-) (*GitHubRepoInfo, error) {
-	if g.githubToken == "" {
-		return nil, ErrGitHubTokenNotSet
+) (*RepoInfo, error) {
+	host, err := g.options.host(repoURL, g.githubToken)
+	if err != nil {
+		return nil, err
 	}
 
-	owner, repo := parseRepoURL(repoURL)
+	// offline, the responses come from the snapshot and no token is needed
+	if host.Token == "" && !g.options.cache.Offline() {
+		return nil, fmt.Errorf("%w for %s", ErrGitHubTokenNotSet, host.Name)
+	}
+
+	owner, repo, err := ownerAndRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
 
 	headers := map[string]string{
-		"Authorization": "token " + g.githubToken,
+		"Authorization": "token " + host.Token,
 	}
 
-	repoData, err := fetchRepoData(client, owner, repo, headers)
+	repoData, err := fetchRepoData(ctx, client, host.APIBaseURL, owner, repo, headers)
 	if err != nil {
 		return nil, err
 	}
 
-	lastCommitDate, err := fetchLastCommitDate(client, owner, repo, repoData, headers)
+	openPullRequests, err := countOpenPullRequests(ctx, client, host.APIBaseURL, owner, repo, headers)
 	if err != nil {
 		return nil, err
 	}
 
-	repoInfo := createRepoInfo(repoData, lastCommitDate)
+	lastCommitDate, err := fetchLastCommitDate(ctx, client, host.APIBaseURL, owner, repo, repoData, headers)
+	if err != nil {
+		return nil, err
+	}
 
-	calcScore(repoInfo, &g.weights)
+	releaseDates, err := fetchReleaseDates(ctx, client, host.APIBaseURL, owner, repo, headers)
+	if err != nil {
+		return nil, err
+	}
 
-	return repoInfo, nil
-}
+	contributors, statsErr := fetchContributorStats(ctx, client, host.APIBaseURL, owner, repo, headers)
+	if statsErr != nil && !errors.Is(statsErr, ErrStatisticsPending) {
+		return nil, statsErr
+	}
 
-func parseRepoURL(repoURL string) (string, string) {
-	repoURL = strings.TrimSuffix(repoURL, "/")
-	parts := strings.Split(repoURL, "/")
+	weeklyCommits, activityErr := fetchCommitActivity(ctx, client, host.APIBaseURL, owner, repo, headers)
+	if activityErr != nil && !errors.Is(activityErr, ErrStatisticsPending) {
+		return nil, activityErr
+	}
 
-	var owner, repo string
+	now := time.Now()
 
-	owner, repo = parts[3], parts[4]
-	repo = strings.TrimSuffix(repo, ".git")
+	repoInfo := createRepoInfo(repoData, openPullRequests, lastCommitDate)
+	setReleaseCadence(repoInfo, releaseDates, now)
 
-	return owner, repo
+	// statistics GitHub has not finished computing are left unknown rather than skipping the repository
+	if statsErr == nil {
+		setContributorActivity(repoInfo, contributors, now)
+	} else {
+		repoInfo.Incomplete = append(repoInfo.Incomplete, "contributor statistics pending")
+		utils.DebugPrintln(fmt.Sprintf("Contributor statistics of %s: %v", repoURL, statsErr))
+	}
+
+	if activityErr == nil {
+		setCommitActivity(repoInfo, weeklyCommits)
+	} else {
+		repoInfo.Incomplete = append(repoInfo.Incomplete, "commit activity pending")
+		utils.DebugPrintln(fmt.Sprintf("Commit activity of %s: %v", repoURL, activityErr))
+	}
+
+	if g.options.lookbackMonths > 0 {
+		since := g.options.lookbackSince(now)
+
+		activity, err := fetchIssueActivity(ctx, client, host.APIBaseURL, owner, repo, headers, since)
+
+		switch {
+		case err == nil:
+			setResponsiveness(repoInfo, activity, since, now)
+		case errors.Is(err, utils.ErrNotInOfflineSnapshot):
+			// snapshots taken on another day have the issues of another window
+			utils.DebugPrintln(fmt.Sprintf("Responsiveness of %s: %v", repoURL, err))
+		default:
+			return nil, err
+		}
+	}
+
+	CalcScore(repoInfo, &g.weights)
+
+	return repoInfo, nil
 }
 
 func fetchRepoData(
-	client *http.Client,
-	owner, repo string,
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
 	headers map[string]string,
 ) (*RepoData, error) {
 	var repoData RepoData
 
-	err := fetchJSONData(client, fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo), headers, &repoData)
+	err := fetchJSONData(ctx, client, fmt.Sprintf("%s/repos/%s/%s", apiBaseURL, owner, repo), headers, &repoData)
 	if err != nil {
 		return nil, err
 	}
@@ -158,14 +192,112 @@ func fetchRepoData(
 	return &repoData, nil
 }
 
-func fetchLastCommitDate(client *http.Client, owner, repo string,
+// countOpenPullRequests lists the open pull requests one per page and reads their number from
+// the page of the rel="last" link. A single page has no Link header; its entries are counted then.
+func countOpenPullRequests(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
+	headers map[string]string,
+) (int, error) {
+	pullsURL := apiBaseURL + "/repos/" + owner + "/" + repo + "/pulls?state=open&per_page=1"
+
+	body, header, err := client.sendWithHeader(ctx, http.MethodGet, pullsURL, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if page, ok := lastPage(header.Get("Link")); ok {
+		return page, nil
+	}
+
+	var pulls []json.RawMessage
+
+	err = json.Unmarshal(body, &pulls)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode JSON response for URL %s: %w", pullsURL, err)
+	}
+
+	return len(pulls), nil
+}
+
+// lastPage reads the page number of the rel="last" link of a Link header:
+// <https://api.github.com/repositories/1/pulls?per_page=1&page=42>; rel="last".
+func lastPage(link string) (int, bool) {
+	target, found := linkTarget(link, "last")
+	if !found {
+		return 0, false
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return 0, false
+	}
+
+	page, err := strconv.Atoi(parsed.Query().Get("page"))
+
+	return page, err == nil
+}
+
+// fetchPages follows the rel="next" links from pageURL for at most maxPages pages, stopping early
+// once stop, when given, reports that the rest is not needed, e.g. older than the lookback window.
+// truncated tells that pages were left.
+func fetchPages[T any](
+	ctx context.Context,
+	client *apiClient,
+	pageURL string,
+	headers map[string]string,
+	maxPages int,
+	stop func(page []T) bool,
+) ([]T, bool, error) {
+	var items []T
+
+	for range maxPages {
+		body, header, err := client.sendWithHeader(ctx, http.MethodGet, pageURL, headers, nil)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var page []T
+
+		err = json.Unmarshal(body, &page)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decode JSON response for URL %s: %w", pageURL, err)
+		}
+
+		items = append(items, page...)
+
+		next, found := linkTarget(header.Get("Link"), "next")
+		if !found || len(page) == 0 || (stop != nil && stop(page)) {
+			return items, false, nil
+		}
+
+		pageURL = next
+	}
+
+	return items, true, nil
+}
+
+// linkTarget returns the URL of the link with relation rel in a Link header.
+func linkTarget(link, rel string) (string, bool) {
+	for _, part := range strings.Split(link, ",") {
+		target, params, found := strings.Cut(part, ";")
+		if found && strings.Contains(params, `rel="`+rel+`"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>"), true
+		}
+	}
+
+	return "", false
+}
+
+func fetchLastCommitDate(ctx context.Context, client *apiClient, apiBaseURL, owner, repo string,
 	repoData *RepoData, headers map[string]string,
 ) (string, error) {
-	commitURL := "https://api.github.com/repos/" + owner + "/" + repo + "/commits/" + repoData.DefaultBranch
+	commitURL := apiBaseURL + "/repos/" + owner + "/" + repo + "/commits/" + repoData.DefaultBranch
 
 	var commitData CommitData
 
-	err := fetchJSONData(client, commitURL, headers, &commitData)
+	err := fetchJSONData(ctx, client, commitURL, headers, &commitData)
 	if err != nil {
 		return "", err
 	}
@@ -173,50 +305,135 @@ func fetchLastCommitDate(client *http.Client, owner, repo string,
 	return commitData.Commit.Committer.Date, nil
 }
 
+// createRepoInfo separates the open pull requests from open_issues_count, which counts both.
 func createRepoInfo(
 	repoData *RepoData,
+	openPullRequests int,
 	lastCommitDate string,
-) *GitHubRepoInfo {
-	return &GitHubRepoInfo{
-		RepositoryName: repoData.Name,
-		Watchers:       repoData.SubscribersCount,
-		Stars:          repoData.StargazersCount,
-		Forks:          repoData.ForksCount,
-		OpenIssues:     repoData.OpenIssuesCount,
-		LastCommitDate: lastCommitDate,
-		Archived:       repoData.Archived,
-		Skip:           false,
-		SkipReason:     "",
+) *RepoInfo {
+	var createdDate *string
+	if repoData.CreatedAt != "" {
+		createdDate = Ptr(repoData.CreatedAt)
+	}
+
+	return &RepoInfo{
+		RepositoryName:   repoData.Name,
+		Watchers:         Ptr(repoData.SubscribersCount),
+		Stars:            Ptr(repoData.StargazersCount),
+		Forks:            Ptr(repoData.ForksCount),
+		OpenIssues:       Ptr(max(repoData.OpenIssuesCount-openPullRequests, 0)),
+		OpenPullRequests: Ptr(openPullRequests),
+		LastCommitDate:   Ptr(lastCommitDate),
+		CreatedDate:      createdDate,
+		Archived:         Ptr(repoData.Archived),
+		Skip:             false,
+		SkipReason:       "",
 	}
 }
 
-func calcScore(repoInfo *GitHubRepoInfo, weights *ParameterWeights) {
-	days, err := daysSince(repoInfo.LastCommitDate)
-	if err != nil {
-		repoInfo.Skip = true
+// CalcScore sums the weighted metrics into repoInfo.Score. Metrics the forge does not have are
+// left out rather than counted as zero, which would read as e.g. "never committed to" for the
+// last commit date. The metrics are in their own units, stars or days, so scores compare between
+// repositories with the same metrics. Analyzers outside this module call it like the built-in ones.
+// An incomplete repository lacks metrics its forge has, which a later run may read, and is left
+// unscored so that it does not rank by whichever of them were ready.
+func CalcScore(repoInfo *RepoInfo, weights *ParameterWeights) {
+	if len(repoInfo.Incomplete) > 0 {
+		repoInfo.Score = 0
+
+		return
+	}
+
+	score := 0.0
+
+	terms := []struct {
+		value  *int
+		weight float64
+	}{
+		{repoInfo.Watchers, weights.Watchers},
+		{repoInfo.Stars, weights.Stars},
+		{repoInfo.Forks, weights.Forks},
+		{repoInfo.OpenIssues, weights.OpenIssues},
+		{repoInfo.OpenPullRequests, weights.OpenPullRequests},
+		{repoInfo.ReleasesLastYear, weights.ReleasesLastYear},
+		{repoInfo.MedianReleaseGapDays, weights.MedianReleaseGap},
+		{repoInfo.CommittersLast90Days, weights.CommittersLast90Days},
+		{repoInfo.CommittersLastYear, weights.CommittersLastYear},
+		{repoInfo.TopContributorShare, weights.TopContributorShare},
+		{repoInfo.MedianIssueResponseHours, weights.MedianIssueResponse},
+		{repoInfo.MedianMergeDays, weights.MedianMergeTime},
+		{repoInfo.IssueCloseRatio, weights.IssueCloseRatio},
+		{repoInfo.ActiveWeeksLastYear, weights.ActiveWeeks},
+	}
+
+	for _, term := range terms {
+		if term.value != nil {
+			score += float64(*term.value) * term.weight
+		}
+	}
+
+	dateTerms := []struct {
+		date   *string
+		weight float64
+	}{
+		{repoInfo.LastCommitDate, weights.LastCommitDate},
+		{repoInfo.releaseAgeDate(), weights.LastReleaseDate},
+	}
+
+	for _, term := range dateTerms {
+		if term.date == nil {
+			continue
+		}
+
+		days, err := daysSince(*term.date)
+		if err != nil {
+			repoInfo.Skip = true
+
+			repoInfo.SkipReason = "Date Format Error: " + *term.date
 
-		repoInfo.SkipReason = "Date Format Error: " + repoInfo.LastCommitDate
+			utils.StdErrorPrintln("Date Format Error: %v", err)
 
-		utils.StdErrorPrintln("Date Format Error: %v", err)
+			continue
+		}
+
+		score += float64(days) * term.weight
 	}
 
-	score := float64(repoInfo.Watchers) * weights.Watchers
-	score += float64(repoInfo.Stars) * weights.Stars
-	score += float64(repoInfo.Forks) * weights.Forks
-	score += float64(repoInfo.OpenIssues) * weights.OpenIssues
-	score += float64(days) * weights.LastCommitDate
-	intArchived := map[bool]float64{true: 1.0, false: 0.0}[repoInfo.Archived]
-	score += (intArchived) * weights.Archived
+	if repoInfo.Archived != nil && *repoInfo.Archived {
+		score += weights.Archived
+	}
 
 	repoInfo.Score = int(score)
 }
 
+// AddDependencyKindScore adds the direct or indirect weight to an analyzed repository.
+// The analyzer only sees repository URLs, so the caller applies it per dependency.
+func AddDependencyKindScore(repoInfo *RepoInfo, indirect bool, weights *ParameterWeights) {
+	if repoInfo.Skip || len(repoInfo.Incomplete) > 0 {
+		return
+	}
+
+	if indirect {
+		repoInfo.Score += int(weights.Indirect)
+	} else {
+		repoInfo.Score += int(weights.Direct)
+	}
+}
+
+// AddVulnerabilityScore adds the weight of the advisories affecting the pinned version of a
+// dependency, which only the caller knows, like the dependency kind.
+func AddVulnerabilityScore(repoInfo *RepoInfo, unfixedVulnerabilities int, weights *ParameterWeights) {
+	if repoInfo.Skip || len(repoInfo.Incomplete) > 0 {
+		return
+	}
+
+	repoInfo.Score += int(float64(unfixedVulnerabilities) * weights.UnfixedVulnerabilities)
+}
+
 // 日付文字列から現在日までの経過日数を返す関数
 func daysSince(dateStr string) (int, error) {
 	// 入力された日付文字列をパース（UTCフォーマット）
-	layout := "2006-01-02T15:04:05Z"
-
-	parsedTime, err := time.Parse(layout, dateStr)
+	parsedTime, err := time.Parse(gitHubDateLayout, dateStr)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse date '%s': %w", dateStr, err)
 	}
@@ -229,34 +446,18 @@ func daysSince(dateStr string) (int, error) {
 }
 
 func fetchJSONData(
-	client *http.Client,
+	ctx context.Context,
+	client *apiClient,
 	url string,
 	headers map[string]string,
 	result interface{},
 ) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	body, err := client.send(ctx, http.MethodGet, url, headers, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create new HTTP request for URL %s: %w", url, err)
-	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute HTTP request for URL %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: %d for URL %s", ErrUnexpectedStatusCode, resp.StatusCode, url)
+		return err
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(result)
+	err = json.Unmarshal(body, result)
 	if err != nil {
 		return fmt.Errorf("failed to decode JSON response for URL %s: %w", url, err)
 	}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -8,7 +9,7 @@ import (
 	"testing"
 )
 
-func TestParseRepoURL_Variants(t *testing.T) {
+func TestOwnerAndRepo_Variants(t *testing.T) {
 	t.Parallel()
 
 	cases := []struct {
@@ -24,9 +25,16 @@ func TestParseRepoURL_Variants(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		o, r := parseRepoURL(tc.in)
-		if o != tc.owner || r != tc.repo {
-			t.Fatalf("parseRepoURL(%q) => %s/%s, want %s/%s", tc.in, o, r, tc.owner, tc.repo)
+		o, r, err := ownerAndRepo(tc.in)
+		if err != nil || o != tc.owner || r != tc.repo {
+			t.Fatalf("ownerAndRepo(%q) => %s/%s, %v, want %s/%s", tc.in, o, r, err, tc.owner, tc.repo)
+		}
+	}
+
+	// organizations and sponsor pages name no repository
+	for _, in := range []string{"https://github.com/org", "https://github.com/org/", "https://github.com"} {
+		if _, _, err := ownerAndRepo(in); !errors.Is(err, ErrInvalidRepositoryURL) {
+			t.Fatalf("ownerAndRepo(%q) => %v, want ErrInvalidRepositoryURL", in, err)
 		}
 	}
 }
@@ -34,10 +42,10 @@ func TestParseRepoURL_Variants(t *testing.T) {
 func TestCalcScore_InvalidDate_SetsSkip(t *testing.T) {
 	t.Parallel()
 
-	info := &GitHubRepoInfo{LastCommitDate: "invalid-date"}
+	info := &RepoInfo{LastCommitDate: Ptr("invalid-date")}
 	w := &ParameterWeights{}
 
-	calcScore(info, w)
+	CalcScore(info, w)
 
 	if !info.Skip {
 		t.Fatalf("expected Skip=true when date invalid")
@@ -48,18 +56,91 @@ func TestCalcScore_InvalidDate_SetsSkip(t *testing.T) {
 	}
 }
 
+func TestCalcScore_InvalidDate_LeftOutOfScore(t *testing.T) {
+	t.Parallel()
+
+	info := &RepoInfo{Stars: Ptr(5), LastCommitDate: Ptr("invalid-date"), LastReleaseDate: Ptr("2024-01-01")}
+	CalcScore(info, &ParameterWeights{Stars: 1, LastCommitDate: -1, LastReleaseDate: -1})
+
+	if !info.Skip || info.Score != 5 {
+		t.Fatalf("expected the unreadable dates to add nothing to 5 stars, got %+v", info)
+	}
+}
+
+func TestCalcScore_LeavesOutMissingMetrics(t *testing.T) {
+	t.Parallel()
+
+	weights := &ParameterWeights{Watchers: 1, Stars: 1, Forks: 2, LastCommitDate: -1, Archived: -1000}
+
+	// the same repository mirrored on GitHub and on Bitbucket, which has neither stars nor dates here
+	github := &RepoInfo{Watchers: Ptr(10), Stars: Ptr(10), Forks: Ptr(10)}
+	bitbucket := &RepoInfo{Watchers: Ptr(10), Forks: Ptr(10)}
+	noStars := &RepoInfo{Watchers: Ptr(10), Stars: Ptr(0), Forks: Ptr(10), Archived: Ptr(true)}
+
+	CalcScore(github, weights)
+	CalcScore(bitbucket, weights)
+	CalcScore(noStars, weights)
+
+	if github.Score != 40 || github.Skip {
+		t.Fatalf("want 40 on GitHub, got %+v", github)
+	}
+
+	// the stars Bitbucket lacks add nothing, the same as none, and the missing date is not an error
+	if bitbucket.Score != 30 || bitbucket.Skip {
+		t.Fatalf("want 30 on Bitbucket, got %+v", bitbucket)
+	}
+
+	if noStars.Score != 30-1000 {
+		t.Fatalf("want the archived penalty added to 30, got %d", noStars.Score)
+	}
+}
+
+func TestCalcScore_IncompleteLeftUnscored(t *testing.T) {
+	t.Parallel()
+
+	weights := &ParameterWeights{Stars: 1, CommittersLastYear: 10}
+	cold := &RepoInfo{Stars: Ptr(50), Incomplete: []string{"contributor statistics pending"}}
+	warm := &RepoInfo{Stars: Ptr(50), CommittersLastYear: Ptr(3)}
+
+	CalcScore(cold, weights)
+	CalcScore(warm, weights)
+	AddDependencyKindScore(cold, false, &ParameterWeights{Direct: 5})
+
+	if cold.Score != 0 || warm.Score != 80 {
+		t.Fatalf("want the pending run unscored and the ready one 80, got %d and %d", cold.Score, warm.Score)
+	}
+}
+
 func TestCreateRepoInfo_MapsFields(t *testing.T) {
 	t.Parallel()
 
-	rd := &RepoData{Name: "r", SubscribersCount: 1, StargazersCount: 2, ForksCount: 3, OpenIssuesCount: 4, Archived: true}
-	gi := createRepoInfo(rd, "2024-01-01T00:00:00Z")
+	rd := &RepoData{
+		Name: "r", SubscribersCount: 1, StargazersCount: 2, ForksCount: 3, OpenIssuesCount: 4, Archived: true,
+		CreatedAt: "2020-01-01T00:00:00Z",
+	}
+	gi := createRepoInfo(rd, 1, "2024-01-01T00:00:00Z")
 
-	if gi.RepositoryName != "r" || gi.Watchers != 1 || gi.Stars != 2 || gi.Forks != 3 || gi.OpenIssues != 4 ||
-		gi.Archived != true || gi.LastCommitDate != "2024-01-01T00:00:00Z" {
+	if gi.RepositoryName != "r" || *gi.Watchers != 1 || *gi.Stars != 2 || *gi.Forks != 3 || *gi.OpenIssues != 3 ||
+		*gi.OpenPullRequests != 1 || !*gi.Archived || *gi.LastCommitDate != "2024-01-01T00:00:00Z" ||
+		*gi.CreatedDate != "2020-01-01T00:00:00Z" {
 		t.Fatalf("unexpected mapping: %+v", gi)
 	}
 }
 
+func TestLastPage(t *testing.T) {
+	t.Parallel()
+
+	page, ok := lastPage(`<https://api.github.com/repositories/1/pulls?page=2>; rel="next", ` +
+		`<https://api.github.com/repositories/1/pulls?per_page=1&page=42>; rel="last"`)
+	if !ok || page != 42 {
+		t.Fatalf("want 42, got %d %v", page, ok)
+	}
+
+	if _, ok := lastPage(""); ok {
+		t.Fatalf("want no last page without a Link header")
+	}
+}
+
 func TestIndexOf(t *testing.T) {
 	t.Parallel()
 
@@ -89,7 +170,7 @@ func TestFetchJSONData_Non200AndDecodeError(t *testing.T) {
 
 		return &http.Response{StatusCode: http.StatusTeapot, Body: body, Header: hdr}, nil
 	})}
-	err := fetchJSONData(client1, "http://example", nil, &out)
+	err := fetchJSONData(context.Background(), &apiClient{httpClient: client1}, "http://example", nil, &out)
 
 	if !errors.Is(err, ErrUnexpectedStatusCode) {
 		t.Fatalf("expected ErrUnexpectedStatusCode, got %v", err)
@@ -103,17 +184,17 @@ func TestFetchJSONData_Non200AndDecodeError(t *testing.T) {
 		return &http.Response{StatusCode: http.StatusOK, Body: body, Header: hdr}, nil
 	})}
 
-	err = fetchJSONData(client2, "http://example", nil, &out)
+	err = fetchJSONData(context.Background(), &apiClient{httpClient: client2}, "http://example", nil, &out)
 	if err == nil {
 		t.Fatalf("expected decode error")
 	}
 }
 
-func TestFetchGithubInfo_NoToken_SetsSkip(t *testing.T) {
+func TestFetchRepoInfo_NoToken_SetsSkip(t *testing.T) {
 	t.Parallel()
 
 	a := NewGitHubRepoAnalyzer("", NewParameterWeights())
-	infos := a.FetchGithubInfo([]string{"https://github.com/user/repo"})
+	infos := a.FetchRepoInfo(context.Background(), []string{"https://github.com/user/repo"})
 
 	if len(infos) != 1 {
 		t.Fatalf("want 1 info")
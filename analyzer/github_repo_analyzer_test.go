@@ -1,15 +1,67 @@
 package analyzer_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
-func TestFetchGithubInfo(t *testing.T) {
+// registerNoHistory answers the releases, tags, statistics and issues of a repository with empty lists.
+func registerNoHistory(repoAPIURL string) {
+	httpmock.RegisterResponder("GET", repoAPIURL+"/issues", httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/releases", httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/tags", httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/stats/contributors", httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/stats/commit_activity", httpmock.NewStringResponder(200, `[]`))
+}
+
+// registerIssueActivity answers the issues, their comments and the closed pull requests of a
+// repository with activity relative to now.
+func registerIssueActivity(repoAPIURL string) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	at := func(daysAgo, hoursLater int) string {
+		return now.AddDate(0, 0, -daysAgo).Add(time.Duration(hoursLater) * time.Hour).Format(time.RFC3339)
+	}
+
+	// opened in the window: #5, #4 and the maintainer's #3, left out of the response times;
+	// closed in the window: #4, #3 and #1, which is older than the window
+	httpmock.RegisterResponder("GET", repoAPIURL+"/issues",
+		httpmock.NewStringResponder(200, `[
+			{"number": 6, "created_at": "`+at(5, 0)+`", "comments": 0, "author_association": "NONE",
+			 "pull_request": {}},
+			{"number": 5, "created_at": "`+at(10, 0)+`", "comments": 2, "author_association": "NONE"},
+			{"number": 4, "created_at": "`+at(20, 0)+`", "closed_at": "`+at(20, 30)+`", "comments": 0,
+			 "author_association": "CONTRIBUTOR"},
+			{"number": 3, "created_at": "`+at(30, 0)+`", "closed_at": "`+at(29, 0)+`", "comments": 0,
+			 "author_association": "OWNER"},
+			{"number": 1, "created_at": "`+at(400, 0)+`", "closed_at": "`+at(15, 0)+`", "comments": 0,
+			 "author_association": "NONE"}
+		]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/issues/5/comments",
+		httpmock.NewStringResponder(200, `[
+			{"created_at": "`+at(10, 1)+`", "author_association": "NONE"},
+			{"created_at": "`+at(10, 6)+`", "author_association": "MEMBER"}
+		]`))
+	httpmock.RegisterResponder("GET", repoAPIURL+"/pulls?state=closed&sort=updated&direction=desc&per_page=100",
+		httpmock.NewStringResponder(200, `[
+			{"created_at": "`+at(10, 0)+`", "updated_at": "`+at(7, 0)+`", "merged_at": "`+at(7, 0)+`"},
+			{"created_at": "`+at(12, 0)+`", "updated_at": "`+at(11, 0)+`", "merged_at": null},
+			{"created_at": "`+at(40, 0)+`", "updated_at": "`+at(39, 0)+`", "merged_at": "`+at(39, 0)+`"}
+		]`))
+}
+
+func TestFetchRepoInfo(t *testing.T) {
 	t.Parallel()
 	// httpmockを有効化
 	httpmock.Activate()
@@ -27,8 +79,12 @@ func TestFetchGithubInfo(t *testing.T) {
 			"default_branch": "main"
 		}`))
 
+	// one pull request per page, so the last page is the number of open pull requests
 	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/pulls",
-		httpmock.NewStringResponder(200, `[]`)) // 空のプルリクエストリスト
+		httpmock.NewStringResponder(200, `[{"number": 7}]`).HeaderSet(http.Header{"Link": {
+			`<https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=2>; rel="next", ` +
+				`<https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=2>; rel="last"`,
+		}}))
 
 	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/commits/main",
 		httpmock.NewStringResponder(200, `{
@@ -39,28 +95,65 @@ func TestFetchGithubInfo(t *testing.T) {
 			}
 		}`))
 
+	// the draft has no publication date and is left out
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/releases",
+		httpmock.NewStringResponder(200, `[
+			{"tag_name": "v1.2.0", "draft": true, "published_at": null},
+			{"tag_name": "v1.1.0", "draft": false, "published_at": "2023-09-01T00:00:00Z"},
+			{"tag_name": "v1.0.0", "draft": false, "published_at": "2023-06-03T00:00:00Z"},
+			{"tag_name": "v0.9.0", "draft": false, "published_at": "2023-05-04T00:00:00Z"}
+		]`))
+
+	// one author committed last month, the other only half a year ago
+	lastMonth := strconv.FormatInt(time.Now().AddDate(0, -1, 0).Unix(), 10)
+	halfYearAgo := strconv.FormatInt(time.Now().AddDate(0, -6, 0).Unix(), 10)
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/stats/contributors",
+		httpmock.NewStringResponder(200, `[
+			{"total": 9, "author": {"login": "busy"}, "weeks": [{"w": `+lastMonth+`, "c": 6}, {"w": 1000, "c": 3}]},
+			{"total": 2, "author": {"login": "occasional"}, "weeks": [{"w": `+halfYearAgo+`, "c": 2}]}
+		]`))
+
+	// two weeks with commits out of 52
+	weeks := `{"total": 0}` + strings.Repeat(`, {"total": 0}`, 49) + `, {"total": 4}, {"total": 1}`
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/stats/commit_activity",
+		httpmock.NewStringResponder(200, `[`+weeks+`]`))
+
+	registerIssueActivity("https://api.github.com/repos/example-owner/example-repo")
+
 	// テスト用のGitHubRepoAnalyzerを作成
-	analyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.ParameterWeights{
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.ParameterWeights{
 		Forks:          1.0,
 		OpenIssues:     1.0,
 		LastCommitDate: 1.0,
 		Archived:       1.0,
-	})
+	}, analyzer.WithLookbackMonths(6))
 
 	// テスト実行
 	repoURLs := []string{"https://github.com/example-owner/example-repo"}
-	repoInfos := analyzer.FetchGithubInfo(repoURLs)
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), repoURLs)
 
 	assert.Len(t, repoInfos, 1, "Expected 1 repo info")
 
 	repoInfo := repoInfos[0]
 
 	assert.Equal(t, "example-repo", repoInfo.RepositoryName, "RepositoryName mismatch")
-	assert.Equal(t, 10, repoInfo.Watchers, "Watchers mismatch")
-	assert.Equal(t, 50, repoInfo.Stars, "Stars mismatch")
-	assert.Equal(t, 5, repoInfo.Forks, "Forks mismatch")
-	assert.Equal(t, 3, repoInfo.OpenIssues, "OpenIssues mismatch")
-	assert.False(t, repoInfo.Archived, "Archived should be false")
+	assert.Equal(t, analyzer.Ptr(10), repoInfo.Watchers, "Watchers mismatch")
+	assert.Equal(t, analyzer.Ptr(50), repoInfo.Stars, "Stars mismatch")
+	assert.Equal(t, analyzer.Ptr(5), repoInfo.Forks, "Forks mismatch")
+	assert.Equal(t, analyzer.Ptr(1), repoInfo.OpenIssues, "OpenIssues mismatch")
+	assert.Equal(t, analyzer.Ptr(2), repoInfo.OpenPullRequests, "OpenPullRequests mismatch")
+	assert.Equal(t, analyzer.Ptr(false), repoInfo.Archived, "Archived should be false")
+	assert.Equal(t, analyzer.Ptr("2023-09-01T00:00:00Z"), repoInfo.LastReleaseDate)
+	assert.Equal(t, analyzer.Ptr((90+30)/2), repoInfo.MedianReleaseGapDays)
+	assert.Equal(t, analyzer.Ptr(1), repoInfo.CommittersLast90Days)
+	assert.Equal(t, analyzer.Ptr(2), repoInfo.CommittersLastYear)
+	assert.Equal(t, analyzer.Ptr(6*100/8), repoInfo.TopContributorShare)
+	assert.Equal(t, analyzer.Ptr((6+30)/2), repoInfo.MedianIssueResponseHours)
+	assert.Equal(t, analyzer.Ptr((3+1)/2), repoInfo.MedianMergeDays)
+	assert.Equal(t, analyzer.Ptr(100), repoInfo.IssueCloseRatio)
+	assert.Equal(t, analyzer.Ptr(2), repoInfo.ActiveWeeksLastYear)
+	assert.Len(t, repoInfo.WeeklyCommits, 52)
+	assert.Equal(t, 4, repoInfo.WeeklyCommits[50])
 	assert.False(t, repoInfo.Skip, "Skip should be false")
 }
 
@@ -81,6 +174,10 @@ func TestScoreIncludesWatchers(t *testing.T) {
                         "default_branch": "main"
                 }`))
 
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/pulls",
+		httpmock.NewStringResponder(200, `[]`))
+	registerNoHistory("https://api.github.com/repos/example-owner/example-repo")
+
 	httpmock.RegisterResponder("GET", "https://api.github.com/repos/example-owner/example-repo/commits/main",
 		httpmock.NewStringResponder(200, `{
                         "commit": {
@@ -90,7 +187,7 @@ func TestScoreIncludesWatchers(t *testing.T) {
                         }
                 }`))
 
-	analyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.ParameterWeights{
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.ParameterWeights{
 		Watchers:       2.0,
 		Stars:          0.0,
 		Forks:          0.0,
@@ -99,8 +196,260 @@ func TestScoreIncludesWatchers(t *testing.T) {
 		Archived:       0.0,
 	})
 
-	repoInfos := analyzer.FetchGithubInfo([]string{"https://github.com/example-owner/example-repo"})
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(),
+		[]string{"https://github.com/example-owner/example-repo"})
 
 	assert.Len(t, repoInfos, 1)
 	assert.Equal(t, 20, repoInfos[0].Score)
 }
+
+func TestAddDependencyKindScore(t *testing.T) {
+	t.Parallel()
+
+	weights := analyzer.ParameterWeights{Direct: 10, Indirect: -20}
+
+	direct := analyzer.RepoInfo{Score: 100}
+	analyzer.AddDependencyKindScore(&direct, false, &weights)
+	assert.Equal(t, 110, direct.Score)
+
+	indirect := analyzer.RepoInfo{Score: 100}
+	analyzer.AddDependencyKindScore(&indirect, true, &weights)
+	assert.Equal(t, 80, indirect.Score)
+
+	skipped := analyzer.RepoInfo{Score: 0, Skip: true}
+	analyzer.AddDependencyKindScore(&skipped, true, &weights)
+	assert.Equal(t, 0, skipped.Score)
+}
+
+func TestAddVulnerabilityScore(t *testing.T) {
+	t.Parallel()
+
+	weights := analyzer.ParameterWeights{UnfixedVulnerabilities: -15}
+
+	vulnerable := analyzer.RepoInfo{Score: 100}
+	analyzer.AddVulnerabilityScore(&vulnerable, 2, &weights)
+	assert.Equal(t, 70, vulnerable.Score)
+
+	skipped := analyzer.RepoInfo{Score: 0, Skip: true}
+	analyzer.AddVulnerabilityScore(&skipped, 2, &weights)
+	assert.Equal(t, 0, skipped.Score)
+}
+
+//nolint:paralleltest // Uses httpmock which doesn't support parallel tests
+func TestFetchRepoInfo_ConcurrentKeepsOrder(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, name := range []string{"one", "two", "four"} {
+		httpmock.RegisterResponder("GET", "https://api.github.com/repos/owner/"+name,
+			httpmock.NewStringResponder(200, `{"name": "`+name+`", "stargazers_count": 1, "default_branch": "main"}`))
+		httpmock.RegisterResponder("GET", "https://api.github.com/repos/owner/"+name+"/pulls",
+			httpmock.NewStringResponder(200, `[]`))
+		registerNoHistory("https://api.github.com/repos/owner/" + name)
+		httpmock.RegisterResponder("GET", "https://api.github.com/repos/owner/"+name+"/commits/main",
+			httpmock.NewStringResponder(200, `{"commit": {"committer": {"date": "2024-01-01T00:00:00Z"}}}`))
+	}
+
+	httpmock.RegisterResponder("GET", "https://api.github.com/repos/owner/three",
+		httpmock.NewStringResponder(404, `{"message": "Not Found"}`))
+
+	repoURLs := []string{
+		"https://github.com/owner/one",
+		"https://github.com/owner/two",
+		"https://github.com/owner/three",
+		"https://github.com/owner/four",
+	}
+
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.NewParameterWeights(),
+		analyzer.WithConcurrency(3))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), repoURLs)
+
+	require.Len(t, repoInfos, 4)
+
+	for i, repoURL := range repoURLs {
+		assert.Equal(t, repoURL, repoInfos[i].RepositoryURL)
+	}
+
+	assert.Equal(t, "one", repoInfos[0].RepositoryName)
+	assert.Equal(t, "two", repoInfos[1].RepositoryName)
+	assert.True(t, repoInfos[2].Skip)
+	assert.Equal(t, "Failed fetching https://github.com/owner/three from GitHub", repoInfos[2].SkipReason)
+	assert.Equal(t, "four", repoInfos[3].RepositoryName)
+	assert.False(t, repoInfos[3].Skip)
+}
+
+func TestFetchRepoInfo_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.NewParameterWeights(),
+		analyzer.WithConcurrency(2))
+	repoInfos := repoAnalyzer.FetchRepoInfo(ctx, []string{"https://github.com/owner/one"})
+
+	require.Len(t, repoInfos, 1)
+	assert.True(t, repoInfos[0].Skip)
+	assert.Equal(t, "Canceled before fetching https://github.com/owner/one", repoInfos[0].SkipReason)
+	assert.Equal(t, "https://github.com/owner/one", repoInfos[0].RepositoryURL)
+}
+
+func TestFetchRepoInfo_OfflineMissingRepository(t *testing.T) {
+	t.Parallel()
+
+	// offline no token is needed, and nothing is fetched
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("", analyzer.NewParameterWeights(),
+		analyzer.WithCache(utils.NewOfflineCache(t.TempDir())))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{"https://github.com/owner/one"})
+
+	require.Len(t, repoInfos, 1)
+	assert.True(t, repoInfos[0].Skip)
+	assert.Equal(t, "Not in offline snapshot", repoInfos[0].SkipReason)
+}
+
+func TestFetchRepoInfo_OrganizationURLSkipped(t *testing.T) {
+	t.Parallel()
+
+	// an organization names no repository and is skipped before any request
+	for _, repoAnalyzer := range []analyzer.RepoAnalyzer{
+		analyzer.NewGitHubRepoAnalyzer("dummy-token", analyzer.NewParameterWeights()),
+		analyzer.NewGitHubGraphQLAnalyzer("dummy-token", analyzer.NewParameterWeights()),
+	} {
+		repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{"https://github.com/org"})
+
+		require.Len(t, repoInfos, 1)
+		assert.True(t, repoInfos[0].Skip)
+		assert.Equal(t, "Failed fetching https://github.com/org from GitHub", repoInfos[0].SkipReason)
+	}
+}
+
+func TestFetchRepoInfo_EnterpriseHost(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token ghe-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/repos/team/lib":
+			_, _ = w.Write([]byte(`{"name": "lib", "stargazers_count": 3, "default_branch": "main"}`))
+		case "/api/v3/repos/team/lib/pulls", "/api/v3/repos/team/lib/releases", "/api/v3/repos/team/lib/tags",
+			"/api/v3/repos/team/lib/issues":
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/v3/repos/team/lib/stats/contributors", "/api/v3/repos/team/lib/stats/commit_activity":
+			// still computing: with no retry budget the statistics stay unknown
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/v3/repos/team/lib/commits/main":
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "2024-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com="+server.URL+"/api/v3"))
+	require.NoError(t, hosts.SetTokens("ghe.example.com=ghe-token"))
+
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("public-token", analyzer.ParameterWeights{Stars: 1},
+		analyzer.WithGitHubHosts(hosts), analyzer.WithRetryBudget(0))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://ghe.example.com/team/lib",
+		"https://gitlab.com/team/lib",
+	})
+
+	require.Len(t, repoInfos, 2)
+	assert.False(t, repoInfos[0].Skip, repoInfos[0].SkipReason)
+	assert.Equal(t, "lib", repoInfos[0].RepositoryName)
+	assert.Zero(t, repoInfos[0].Score, "a repository missing statistics is not scored from the rest")
+	assert.Equal(t, analyzer.Ptr("2024-01-01T00:00:00Z"), repoInfos[0].LastCommitDate)
+	assert.Nil(t, repoInfos[0].CommittersLastYear)
+	assert.Nil(t, repoInfos[0].TopContributorShare)
+	assert.Nil(t, repoInfos[0].ActiveWeeksLastYear)
+	assert.Nil(t, repoInfos[0].WeeklyCommits)
+	assert.Equal(t, []string{"contributor statistics pending", "commit activity pending"}, repoInfos[0].Incomplete)
+
+	assert.True(t, repoInfos[1].Skip)
+	assert.Equal(t, "Failed fetching https://gitlab.com/team/lib from GitHub", repoInfos[1].SkipReason)
+}
+
+func TestFetchRepoInfo_CommitActivityPendingLeftUnscored(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/team/lib":
+			_, _ = w.Write([]byte(`{"name": "lib", "stargazers_count": 3, "default_branch": "main"}`))
+		case "/api/v3/repos/team/lib/pulls", "/api/v3/repos/team/lib/releases", "/api/v3/repos/team/lib/tags",
+			"/api/v3/repos/team/lib/issues", "/api/v3/repos/team/lib/stats/contributors":
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/v3/repos/team/lib/stats/commit_activity":
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/v3/repos/team/lib/commits/main":
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "2024-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com="+server.URL+"/api/v3"))
+	require.NoError(t, hosts.SetTokens("ghe.example.com=dummy-token"))
+
+	// without the active weeks the repository would score its stars alone, above a complete run
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("dummy-token",
+		analyzer.ParameterWeights{Stars: 1, ActiveWeeks: -1, Direct: 5},
+		analyzer.WithGitHubHosts(hosts), analyzer.WithRetryBudget(0))
+	repoInfo := repoAnalyzer.FetchRepoInfo(context.Background(), []string{"https://ghe.example.com/team/lib"})[0]
+
+	require.False(t, repoInfo.Skip, repoInfo.SkipReason)
+	assert.Nil(t, repoInfo.ActiveWeeksLastYear)
+	assert.Equal(t, []string{"commit activity pending"}, repoInfo.Incomplete)
+	assert.Zero(t, repoInfo.Score)
+}
+
+func TestFetchRepoInfo_ReleaseCadenceFromTags(t *testing.T) {
+	t.Parallel()
+
+	lastWeek := time.Now().AddDate(0, 0, -7).UTC().Format(time.RFC3339)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/team/lib":
+			_, _ = w.Write([]byte(`{"name": "lib", "default_branch": "main"}`))
+		case "/repos/team/lib/pulls", "/repos/team/lib/releases", "/repos/team/lib/stats/contributors",
+			"/repos/team/lib/stats/commit_activity", "/repos/team/lib/issues":
+			_, _ = w.Write([]byte(`[]`))
+		case "/repos/team/lib/tags":
+			// listed by name, so v10.0.0 comes last though it is the newest
+			_, _ = w.Write([]byte(`[{"name": "v9.0.0", "commit": {"sha": "aaa"}},
+				{"name": "v10.0.0", "commit": {"sha": "bbb"}}]`))
+		case "/repos/team/lib/commits/main", "/repos/team/lib/commits/bbb":
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "` + lastWeek + `"}}}`))
+		case "/repos/team/lib/commits/aaa":
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "2020-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com="+server.URL))
+	require.NoError(t, hosts.SetTokens("ghe.example.com=token"))
+
+	repoAnalyzer := analyzer.NewGitHubRepoAnalyzer("", analyzer.ParameterWeights{ReleasesLastYear: 10},
+		analyzer.WithGitHubHosts(hosts), analyzer.WithRetryBudget(0))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{"https://ghe.example.com/team/lib"})
+
+	require.Len(t, repoInfos, 1)
+	assert.False(t, repoInfos[0].Skip, repoInfos[0].SkipReason)
+	assert.Equal(t, analyzer.Ptr(lastWeek), repoInfos[0].LastReleaseDate)
+	assert.Equal(t, analyzer.Ptr(1), repoInfos[0].ReleasesLastYear)
+	assert.NotNil(t, repoInfos[0].MedianReleaseGapDays)
+	assert.Equal(t, 10, repoInfos[0].Score)
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+
+// gitLabProject is the part of GET /projects/:id that is scored.
+type gitLabProject struct {
+	ID                   int    `json:"id"`
+	Path                 string `json:"path"`
+	StarCount            int    `json:"star_count"`
+	ForksCount           int    `json:"forks_count"`
+	OpenIssuesCount      int    `json:"open_issues_count"`
+	Archived             bool   `json:"archived"`
+	LastActivityAt       string `json:"last_activity_at"`
+	MergeRequestsEnabled bool   `json:"merge_requests_enabled"`
+}
+
+// GitLabRepoAnalyzer analyzes gitlab.com projects with the GitLab REST API.
+// Public projects can be read without a token, at a lower rate limit.
+type GitLabRepoAnalyzer struct {
+	gitlabToken string
+	weights     ParameterWeights
+	options     options
+}
+
+func NewGitLabRepoAnalyzer(token string, weights ParameterWeights, opts ...Option) *GitLabRepoAnalyzer {
+	return &GitLabRepoAnalyzer{
+		gitlabToken: token,
+		weights:     weights,
+		options:     newOptions(opts),
+	}
+}
+
+// FetchRepoInfo fetches the projects with a bounded number of workers and scores
+// them with the same weights as GitHub repositories. GitLab has no watchers, and its open
+// merge requests are counted as the open pull requests, apart from the open issues.
+func (g *GitLabRepoAnalyzer) FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo {
+	client := newAPIClient(g.options, utils.CacheSourceGitLab)
+
+	return fetchEach(ctx, g.options, ForgeGitLab, repositoryUrls, func(ctx context.Context, repoURL string) (
+		*RepoInfo, error,
+	) {
+		return g.getGitLabInfo(ctx, client, repoURL)
+	})
+}
+
+func (g *GitLabRepoAnalyzer) getGitLabInfo(
+	ctx context.Context,
+	client *apiClient,
+	repoURL string,
+) (*RepoInfo, error) {
+	headers := map[string]string{}
+
+	if g.gitlabToken != "" {
+		headers["PRIVATE-TOKEN"] = g.gitlabToken
+	}
+
+	apiBaseURL := g.options.api(defaultGitLabAPIURL)
+	projectURL := apiBaseURL + "/projects/" + url.PathEscape(gitLabProjectPath(repoURL))
+
+	var project gitLabProject
+
+	err := fetchJSONData(ctx, client, projectURL, headers, &project)
+	if err != nil {
+		return nil, err
+	}
+
+	openMergeRequests := 0
+
+	if project.MergeRequestsEnabled {
+		openMergeRequests, err = countOpenMergeRequests(ctx, client, apiBaseURL, project.ID, headers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	repoInfo := &RepoInfo{
+		RepositoryName:   project.Path,
+		Stars:            Ptr(project.StarCount),
+		Forks:            Ptr(project.ForksCount),
+		OpenIssues:       Ptr(project.OpenIssuesCount),
+		OpenPullRequests: Ptr(openMergeRequests),
+		LastCommitDate:   Ptr(utcDate(project.LastActivityAt, time.RFC3339)),
+		Archived:         Ptr(project.Archived),
+	}
+
+	CalcScore(repoInfo, &g.weights)
+
+	return repoInfo, nil
+}
+
+// countOpenMergeRequests reads the X-Total pagination header, so one merge request is enough to fetch.
+func countOpenMergeRequests(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL string,
+	projectID int,
+	headers map[string]string,
+) (int, error) {
+	mergeRequestsURL := fmt.Sprintf("%s/projects/%d/merge_requests?state=opened&per_page=1", apiBaseURL, projectID)
+
+	body, header, err := client.sendWithHeader(ctx, http.MethodGet, mergeRequestsURL, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if total, err := strconv.Atoi(header.Get("X-Total")); err == nil {
+		return total, nil
+	}
+
+	// GitLab leaves out X-Total above 10,000 results; count the page then
+	var mergeRequests []json.RawMessage
+
+	err = json.Unmarshal(body, &mergeRequests)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode JSON response for URL %s: %w", mergeRequestsURL, err)
+	}
+
+	utils.DebugPrintln("No X-Total for " + mergeRequestsURL)
+
+	return len(mergeRequests), nil
+}
+
+// gitLabProjectPath turns https://gitlab.com/group/subgroup/project/-/tree/main into
+// group/subgroup/project, the id GitLab accepts URL-encoded in place of the numeric one.
+func gitLabProjectPath(repoURL string) string {
+	path := repoURL
+
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Host != "" {
+		path = parsed.Path
+	}
+
+	path, _, _ = strings.Cut(path, "/-/")
+
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
@@ -0,0 +1,84 @@
+package analyzer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+)
+
+func gitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "gl-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		// the project path arrives URL-encoded as one segment
+		switch r.URL.EscapedPath() + "?" + r.URL.RawQuery {
+		case "/api/v4/projects/group%2Fsub%2Fproject?":
+			_, _ = w.Write([]byte(`{
+				"id": 42, "path": "project", "star_count": 10, "forks_count": 4, "open_issues_count": 2,
+				"archived": false, "last_activity_at": "2024-01-02T03:04:05.678Z", "merge_requests_enabled": true
+			}`))
+		case "/api/v4/projects/42/merge_requests?state=opened&per_page=1":
+			w.Header().Set("X-Total", "3")
+			_, _ = w.Write([]byte(`[{"iid": 1}]`))
+		case "/api/v4/projects/group%2Farchived?":
+			_, _ = w.Write([]byte(`{"id": 7, "path": "archived", "star_count": 1, "archived": true,
+				"last_activity_at": "2020-01-01T00:00:00Z", "merge_requests_enabled": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGitLabRepoAnalyzer_FetchRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	server := gitLabServer(t)
+	weights := analyzer.ParameterWeights{Stars: 1, Forks: 10, OpenIssues: 100, OpenPullRequests: 1000}
+
+	repoAnalyzer := analyzer.NewGitLabRepoAnalyzer("gl-token", weights,
+		analyzer.WithAPIURL(server.URL+"/api/v4/"), analyzer.WithRetryBudget(0))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://gitlab.com/group/sub/project/-/tree/main",
+		"https://gitlab.com/group/archived.git",
+		"https://gitlab.com/group/missing",
+	})
+
+	require.Len(t, repoInfos, 3)
+
+	project := repoInfos[0]
+	assert.False(t, project.Skip, project.SkipReason)
+	assert.Equal(t, "project", project.RepositoryName)
+	assert.Equal(t, analyzer.ForgeGitLab, project.Forge)
+	assert.Equal(t, "https://gitlab.com/group/sub/project/-/tree/main", project.RepositoryURL)
+	assert.Equal(t, analyzer.Ptr(2), project.OpenIssues)
+	assert.Equal(t, analyzer.Ptr(3), project.OpenPullRequests)
+	assert.Nil(t, project.Watchers)
+	assert.Equal(t, analyzer.Ptr("2024-01-02T03:04:05Z"), project.LastCommitDate)
+	assert.Equal(t, 10+4*10+2*100+3*1000, project.Score)
+
+	archived := repoInfos[1]
+	assert.False(t, archived.Skip, archived.SkipReason)
+	assert.Equal(t, analyzer.Ptr(true), archived.Archived)
+	assert.Equal(t, analyzer.Ptr(0), archived.OpenIssues)
+	assert.Equal(t, analyzer.Ptr(0), archived.OpenPullRequests)
+
+	missing := repoInfos[2]
+	assert.True(t, missing.Skip)
+	assert.Equal(t, analyzer.ForgeGitLab, missing.Forge)
+	assert.Equal(t, "Failed fetching https://gitlab.com/group/missing from GitLab", missing.SkipReason)
+}
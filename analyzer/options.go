@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	defaultGraphQLEndpoint  = "https://api.github.com/graphql"
+	defaultGraphQLBatchSize = 50
+)
+
+// options are the settings shared by the analyzers.
+type options struct {
+	concurrency      int
+	graphQLEndpoint  string
+	graphQLBatchSize int
+	retryBudget      time.Duration
+	cache            *utils.Cache
+	githubHosts      *utils.GitHubHosts
+	apiURL           string
+	lookbackMonths   int
+}
+
+type Option func(*options)
+
+// WithConcurrency sets how many repositories (REST) or batches (GraphQL) are fetched at the same time.
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithGraphQLEndpoint replaces https://api.github.com/graphql.
+func WithGraphQLEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.graphQLEndpoint = endpoint
+	}
+}
+
+// WithGraphQLBatchSize sets how many repositories are asked for in one GraphQL query.
+func WithGraphQLBatchSize(batchSize int) Option {
+	return func(o *options) {
+		o.graphQLBatchSize = batchSize
+	}
+}
+
+// WithRetryBudget sets how long a request may wait in total for rate limits and
+// temporary failures before it is given up.
+func WithRetryBudget(budget time.Duration) Option {
+	return func(o *options) {
+		o.retryBudget = budget
+	}
+}
+
+// WithCache answers REST requests from the on-disk cache, revalidating stale entries with their ETag.
+// GraphQL queries are never cached.
+func WithCache(cache *utils.Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithGitHubHosts adds GitHub Enterprise hosts, each queried at its own API with its own token.
+// Without it only github.com repositories are analyzed.
+func WithGitHubHosts(hosts *utils.GitHubHosts) Option {
+	return func(o *options) {
+		o.githubHosts = hosts
+	}
+}
+
+// WithAPIURL replaces the API the GitLab, Bitbucket, Gitea and SourceHut analyzers ask,
+// e.g. https://gitea.example.com/api/v1 instead of Codeberg's. GitHub APIs come from WithGitHubHosts.
+func WithAPIURL(apiURL string) Option {
+	return func(o *options) {
+		o.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithLookbackMonths sets how many months of issues and pull requests the GitHub analyzers
+// read for the responsiveness metrics, which cost up to 2*maxLookbackPages+maxResponseSamples
+// requests per repository. The default 0 leaves them unknown and saves the requests.
+func WithLookbackMonths(months int) Option {
+	return func(o *options) {
+		o.lookbackMonths = months
+	}
+}
+
+// host finds the GitHub host of a repository. github.com falls back to the token
+// the analyzer was created with; Enterprise hosts only use their own.
+func (o options) host(repoURL, token string) (utils.GitHubHost, error) {
+	host, ok := o.githubHosts.Lookup(repoURL)
+	if !ok {
+		return host, fmt.Errorf("%w: %s", ErrUnknownGitHubHost, repoURL)
+	}
+
+	if host.Token == "" && host.Name == utils.PublicGitHubHost {
+		host.Token = token
+	}
+
+	return host, nil
+}
+
+// lookbackSince is the start of the lookback window. Whole days keep the URL of the issues, and so
+// their cache entry, the same for a day.
+func (o options) lookbackSince(now time.Time) time.Time {
+	return now.UTC().AddDate(0, -o.lookbackMonths, 0).Truncate(hoursOfDay * time.Hour)
+}
+
+// api is the API set with WithAPIURL, or else defaultURL.
+func (o options) api(defaultURL string) string {
+	if o.apiURL != "" {
+		return o.apiURL
+	}
+
+	return defaultURL
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		concurrency:      1,
+		graphQLEndpoint:  defaultGraphQLEndpoint,
+		graphQLBatchSize: defaultGraphQLBatchSize,
+		retryBudget:      DefaultRetryBudget,
+		cache:            nil,
+		githubHosts:      nil,
+		apiURL:           "",
+		lookbackMonths:   0,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.graphQLBatchSize < 1 {
+		o.graphQLBatchSize = 1
+	}
+
+	return o
+}
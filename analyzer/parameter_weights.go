@@ -9,32 +9,73 @@ import (
 )
 
 const (
-	defaultWatcherWeight         = 0.1
-	defaultStarWeight            = 0.1
-	defaultForkWeight            = 0.1
-	defaultOpenPullRequestWeight = 0.01
-	defaultOpenIssueWeight       = 0.01
-	defaultLastCommitDateWeight  = -0.05
-	defaultArchivedWeight        = -1000000
+	defaultWatcherWeight              = 0.1
+	defaultStarWeight                 = 0.1
+	defaultForkWeight                 = 0.1
+	defaultOpenPullRequestWeight      = 0.01
+	defaultOpenIssueWeight            = 0.01
+	defaultLastCommitDateWeight       = -0.05
+	defaultLastReleaseDateWeight      = -0.02
+	defaultReleasesLastYearWeight     = 1
+	defaultMedianReleaseGapWeight     = -0.01
+	defaultRecentCommitterWeight      = 1
+	defaultYearCommitterWeight        = 0.5
+	defaultTopContributorShareWeight  = -0.1
+	defaultMedianIssueResponseWeight  = -0.01
+	defaultMedianMergeTimeWeight      = -0.05
+	defaultIssueCloseRatioWeight      = 0.05
+	defaultActiveWeeksWeight          = 0.5
+	defaultUnfixedVulnerabilityWeight = -20
+	defaultArchivedWeight             = -1000000
+	defaultDirectWeight               = 0
+	defaultIndirectWeight             = 0
 )
 
 type ParameterWeights struct {
-	Watchers       float64 `mapstructure:"watchers"`
-	Stars          float64 `mapstructure:"stars"`
-	Forks          float64 `mapstructure:"forks"`
-	OpenIssues     float64 `mapstructure:"open_issues"`
-	LastCommitDate float64 `mapstructure:"last_commit_date"`
-	Archived       float64 `mapstructure:"archived"`
+	Watchers               float64 `mapstructure:"watchers"`
+	Stars                  float64 `mapstructure:"stars"`
+	Forks                  float64 `mapstructure:"forks"`
+	OpenIssues             float64 `mapstructure:"open_issues"`
+	OpenPullRequests       float64 `mapstructure:"open_pull_requests"`
+	LastCommitDate         float64 `mapstructure:"last_commit_date"`
+	LastReleaseDate        float64 `mapstructure:"last_release_date"`
+	ReleasesLastYear       float64 `mapstructure:"releases_last_year"`
+	MedianReleaseGap       float64 `mapstructure:"median_release_gap"`
+	CommittersLast90Days   float64 `mapstructure:"committers_last_90_days"`
+	CommittersLastYear     float64 `mapstructure:"committers_last_year"`
+	TopContributorShare    float64 `mapstructure:"top_contributor_share"`
+	MedianIssueResponse    float64 `mapstructure:"median_issue_response"`
+	MedianMergeTime        float64 `mapstructure:"median_merge_time"`
+	IssueCloseRatio        float64 `mapstructure:"issue_close_ratio"`
+	ActiveWeeks            float64 `mapstructure:"active_weeks"`
+	UnfixedVulnerabilities float64 `mapstructure:"unfixed_vulnerabilities"`
+	Archived               float64 `mapstructure:"archived"`
+	Direct                 float64 `mapstructure:"direct"`
+	Indirect               float64 `mapstructure:"indirect"`
 }
 
 func NewParameterWeights() ParameterWeights {
 	return ParameterWeights{
-		Watchers:       defaultWatcherWeight,
-		Stars:          defaultStarWeight,
-		Forks:          defaultForkWeight,
-		OpenIssues:     defaultOpenIssueWeight,
-		LastCommitDate: defaultLastCommitDateWeight,
-		Archived:       defaultArchivedWeight,
+		Watchers:               defaultWatcherWeight,
+		Stars:                  defaultStarWeight,
+		Forks:                  defaultForkWeight,
+		OpenIssues:             defaultOpenIssueWeight,
+		OpenPullRequests:       defaultOpenPullRequestWeight,
+		LastCommitDate:         defaultLastCommitDateWeight,
+		LastReleaseDate:        defaultLastReleaseDateWeight,
+		ReleasesLastYear:       defaultReleasesLastYearWeight,
+		MedianReleaseGap:       defaultMedianReleaseGapWeight,
+		CommittersLast90Days:   defaultRecentCommitterWeight,
+		CommittersLastYear:     defaultYearCommitterWeight,
+		TopContributorShare:    defaultTopContributorShareWeight,
+		MedianIssueResponse:    defaultMedianIssueResponseWeight,
+		MedianMergeTime:        defaultMedianMergeTimeWeight,
+		IssueCloseRatio:        defaultIssueCloseRatioWeight,
+		ActiveWeeks:            defaultActiveWeeksWeight,
+		UnfixedVulnerabilities: defaultUnfixedVulnerabilityWeight,
+		Archived:               defaultArchivedWeight,
+		Direct:                 defaultDirectWeight,
+		Indirect:               defaultIndirectWeight,
 	}
 }
 
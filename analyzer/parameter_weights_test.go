@@ -23,8 +23,22 @@ func TestNewParameterWeightsDefaults(t *testing.T) {
 	assert.InDelta(t, 0.1, weights.Stars, 0.0001)
 	assert.InDelta(t, 0.1, weights.Forks, 0.0001)
 	assert.InDelta(t, 0.01, weights.OpenIssues, 0.0001)
+	assert.InDelta(t, 0.01, weights.OpenPullRequests, 0.0001)
 	assert.InDelta(t, -0.05, weights.LastCommitDate, 0.0001)
+	assert.InDelta(t, -0.02, weights.LastReleaseDate, 0.0001)
+	assert.InDelta(t, 1.0, weights.ReleasesLastYear, 0.0001)
+	assert.InDelta(t, -0.01, weights.MedianReleaseGap, 0.0001)
+	assert.InDelta(t, 1.0, weights.CommittersLast90Days, 0.0001)
+	assert.InDelta(t, 0.5, weights.CommittersLastYear, 0.0001)
+	assert.InDelta(t, -0.1, weights.TopContributorShare, 0.0001)
+	assert.InDelta(t, -0.01, weights.MedianIssueResponse, 0.0001)
+	assert.InDelta(t, -0.05, weights.MedianMergeTime, 0.0001)
+	assert.InDelta(t, 0.05, weights.IssueCloseRatio, 0.0001)
+	assert.InDelta(t, 0.5, weights.ActiveWeeks, 0.0001)
+	assert.InDelta(t, -20.0, weights.UnfixedVulnerabilities, 0.0001)
 	assert.InDelta(t, -1000000.0, weights.Archived, 0.1)
+	assert.InDelta(t, 0.0, weights.Direct, 0.0001)
+	assert.InDelta(t, 0.0, weights.Indirect, 0.0001)
 }
 
 func TestNewParameterWeightsFromConfiFile_LoadsValues(t *testing.T) {
@@ -37,8 +51,22 @@ func TestNewParameterWeightsFromConfiFile_LoadsValues(t *testing.T) {
 			"stars: 2.5\n" +
 			"forks: 3.5\n" +
 			"open_issues: 4.5\n" +
+			"open_pull_requests: 5.5\n" +
 			"last_commit_date: -6.5\n" +
-			"archived: -99999\n",
+			"last_release_date: -7.5\n" +
+			"releases_last_year: 8.5\n" +
+			"median_release_gap: -9.5\n" +
+			"committers_last_90_days: 10.5\n" +
+			"committers_last_year: 11.5\n" +
+			"top_contributor_share: -12.5\n" +
+			"median_issue_response: -13.5\n" +
+			"median_merge_time: -14.5\n" +
+			"issue_close_ratio: 15.5\n" +
+			"active_weeks: 16.5\n" +
+			"unfixed_vulnerabilities: -17.5\n" +
+			"archived: -99999\n" +
+			"direct: 5\n" +
+			"indirect: -5\n",
 	)
 
 	err := os.WriteFile(path, content, 0o600)
@@ -52,8 +80,22 @@ func TestNewParameterWeightsFromConfiFile_LoadsValues(t *testing.T) {
 	assert.InDelta(t, 2.5, weights.Stars, 0.0001)
 	assert.InDelta(t, 3.5, weights.Forks, 0.0001)
 	assert.InDelta(t, 4.5, weights.OpenIssues, 0.0001)
+	assert.InDelta(t, 5.5, weights.OpenPullRequests, 0.0001)
 	assert.InDelta(t, -6.5, weights.LastCommitDate, 0.0001)
+	assert.InDelta(t, -7.5, weights.LastReleaseDate, 0.0001)
+	assert.InDelta(t, 8.5, weights.ReleasesLastYear, 0.0001)
+	assert.InDelta(t, -9.5, weights.MedianReleaseGap, 0.0001)
+	assert.InDelta(t, 10.5, weights.CommittersLast90Days, 0.0001)
+	assert.InDelta(t, 11.5, weights.CommittersLastYear, 0.0001)
+	assert.InDelta(t, -12.5, weights.TopContributorShare, 0.0001)
+	assert.InDelta(t, -13.5, weights.MedianIssueResponse, 0.0001)
+	assert.InDelta(t, -14.5, weights.MedianMergeTime, 0.0001)
+	assert.InDelta(t, 15.5, weights.IssueCloseRatio, 0.0001)
+	assert.InDelta(t, 16.5, weights.ActiveWeeks, 0.0001)
+	assert.InDelta(t, -17.5, weights.UnfixedVulnerabilities, 0.0001)
 	assert.InDelta(t, -99999.0, weights.Archived, 0.0001)
+	assert.InDelta(t, 5.0, weights.Direct, 0.0001)
+	assert.InDelta(t, -5.0, weights.Indirect, 0.0001)
 }
 
 // Test that an invalid path leads to os.Exit(1). Use helper process pattern.
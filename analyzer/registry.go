@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+type forgeRoute struct {
+	host     string
+	analyzer RepoAnalyzer
+}
+
+// Registry hands every repository to the analyzer registered for its host.
+// Repositories on no registered host are skipped.
+type Registry struct {
+	routes []forgeRoute
+}
+
+func NewRegistry() *Registry {
+	return &Registry{routes: nil}
+}
+
+// Register sends the repositories on host to analyzer. The same analyzer may be
+// registered for several hosts, like the GitHub analyzer for every GitHub Enterprise host.
+func (r *Registry) Register(host string, analyzer RepoAnalyzer) {
+	r.routes = append(r.routes, forgeRoute{host: strings.ToLower(host), analyzer: analyzer})
+}
+
+// Hosts returns the registered hosts in the order they were registered.
+func (r *Registry) Hosts() []string {
+	hosts := make([]string, len(r.routes))
+	for i, route := range r.routes {
+		hosts[i] = route.host
+	}
+
+	return hosts
+}
+
+// FetchRepoInfo fetches the repositories of each host with its analyzer, one host
+// after the other, and returns the results in the order of repositoryUrls.
+func (r *Registry) FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo {
+	results := make([]RepoInfo, len(repositoryUrls))
+	indexes := make([][]int, len(r.routes))
+
+	for i, repoURL := range repositoryUrls {
+		route := r.route(repoURL)
+		if route < 0 {
+			results[i] = RepoInfo{
+				RepositoryURL: repoURL,
+				Skip:          true,
+				SkipReason:    "No analyzer registered for the host of " + repoURL,
+			}
+
+			continue
+		}
+
+		indexes[route] = append(indexes[route], i)
+	}
+
+	for route, positions := range indexes {
+		if len(positions) == 0 {
+			continue
+		}
+
+		repoURLs := make([]string, len(positions))
+		for i, position := range positions {
+			repoURLs[i] = repositoryUrls[position]
+		}
+
+		for i, info := range r.routes[route].analyzer.FetchRepoInfo(ctx, repoURLs) {
+			results[positions[i]] = info
+		}
+	}
+
+	return results
+}
+
+// route returns the index of the route of repoURL, or -1 when no host matches.
+func (r *Registry) route(repoURL string) int {
+	for i, route := range r.routes {
+		if utils.OnHost(repoURL, route.host) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Backend builds the analyzer of a forge from the weights and options of a run.
+// Options the forge has no use for are ignored.
+type Backend func(weights ParameterWeights, opts ...Option) RepoAnalyzer
+
+type registeredBackend struct {
+	host    string
+	backend Backend
+}
+
+var backends struct {
+	sync.Mutex
+
+	list []registeredBackend
+}
+
+// RegisterBackend adds a forge on host to every run, typically from the init function
+// of a package outside this module, e.g. for an internal Gerrit mirror. Repository URLs
+// on host are then recognized by the parsers as https://<host>/<owner>/<repo>.
+// A later registration for the same host replaces the earlier one.
+func RegisterBackend(host string, backend Backend) {
+	host = strings.ToLower(host)
+
+	backends.Lock()
+	defer backends.Unlock()
+
+	backends.list = slices.DeleteFunc(backends.list, func(registered registeredBackend) bool {
+		return registered.host == host
+	})
+	backends.list = append(backends.list, registeredBackend{host: host, backend: backend})
+
+	utils.RegisterForgeHost(host)
+}
+
+// RegisterBackends registers the analyzers of the backends added with RegisterBackend in registry.
+func RegisterBackends(registry *Registry, weights ParameterWeights, opts ...Option) {
+	backends.Lock()
+	defer backends.Unlock()
+
+	for _, registered := range backends.list {
+		registry.Register(registered.host, registered.backend(weights, opts...))
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+type forgeStub struct {
+	forge string
+	urls  []string
+}
+
+func (s *forgeStub) FetchRepoInfo(_ context.Context, repositoryUrls []string) []analyzer.RepoInfo {
+	s.urls = repositoryUrls
+	infos := make([]analyzer.RepoInfo, len(repositoryUrls))
+
+	for i, repoURL := range repositoryUrls {
+		infos[i] = analyzer.RepoInfo{RepositoryURL: repoURL, Forge: s.forge}
+	}
+
+	return infos
+}
+
+func TestRegistry_RoutesByHost(t *testing.T) {
+	t.Parallel()
+
+	github := &forgeStub{forge: analyzer.ForgeGitHub, urls: nil}
+	gitlab := &forgeStub{forge: analyzer.ForgeGitLab, urls: nil}
+
+	registry := analyzer.NewRegistry()
+	registry.Register("github.com", github)
+	registry.Register("GitLab.com", gitlab)
+
+	repoURLs := []string{
+		"https://github.com/a/one",
+		"https://gitlab.com/b/two",
+		"https://github.com/gitlab.com/three",
+		"https://GitLab.com/c/four",
+		"https://gerrit.example.com/d/five",
+	}
+	repoInfos := registry.FetchRepoInfo(context.Background(), repoURLs)
+
+	assert.Equal(t, []string{"github.com", "gitlab.com"}, registry.Hosts())
+	assert.Equal(t, []string{repoURLs[0], repoURLs[2]}, github.urls)
+	assert.Equal(t, []string{repoURLs[1], repoURLs[3]}, gitlab.urls)
+
+	require.Len(t, repoInfos, 5)
+
+	for i, forge := range []string{"GitHub", "GitLab", "GitHub", "GitLab"} {
+		assert.Equal(t, repoURLs[i], repoInfos[i].RepositoryURL)
+		assert.Equal(t, forge, repoInfos[i].Forge)
+	}
+
+	assert.Equal(t, repoURLs[4], repoInfos[4].RepositoryURL)
+	assert.True(t, repoInfos[4].Skip)
+	assert.Equal(t, "No analyzer registered for the host of https://gerrit.example.com/d/five",
+		repoInfos[4].SkipReason)
+}
+
+func TestRegisterBackend(t *testing.T) {
+	t.Parallel()
+
+	gerrit := &forgeStub{forge: "Gerrit", urls: nil}
+
+	var gotWeights analyzer.ParameterWeights
+
+	analyzer.RegisterBackend("Gerrit.Registry.Example.com",
+		func(weights analyzer.ParameterWeights, _ ...analyzer.Option) analyzer.RepoAnalyzer {
+			gotWeights = weights
+
+			return gerrit
+		})
+
+	registry := analyzer.NewRegistry()
+	analyzer.RegisterBackends(registry, analyzer.ParameterWeights{Stars: 2})
+
+	assert.Contains(t, registry.Hosts(), "gerrit.registry.example.com")
+	assert.InDelta(t, 2.0, gotWeights.Stars, 0.0001)
+	assert.Contains(t, utils.OwnerRepoForgeHosts(), "gerrit.registry.example.com")
+
+	repoInfos := registry.FetchRepoInfo(context.Background(), []string{"https://gerrit.registry.example.com/team/lib"})
+
+	require.Len(t, repoInfos, 1)
+	assert.Equal(t, "Gerrit", repoInfos[0].Forge)
+}
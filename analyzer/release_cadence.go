@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	releasesPerPage = 100 // releases read per repository, the newest first
+	tagsPerPage     = 100
+	maxTagPages     = 3  // pages of tags searched for the newest versions
+	maxTagDates     = 10 // newest tags whose commit dates are read when a repository has no releases
+	daysOfYear      = 365
+)
+
+type gitHubRelease struct {
+	PublishedAt string `json:"published_at"` // empty for drafts
+	Draft       bool   `json:"draft"`
+}
+
+type gitHubTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// fetchReleaseDates returns the publication dates of the published releases. Repositories
+// that only push tags get the commit dates of their maxTagDates newest tags instead, one
+// commit lookup each.
+func fetchReleaseDates(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
+	headers map[string]string,
+) ([]string, error) {
+	repoAPIURL := apiBaseURL + "/repos/" + owner + "/" + repo
+
+	var releases []gitHubRelease
+
+	err := fetchJSONData(ctx, client, repoAPIURL+"/releases?per_page="+strconv.Itoa(releasesPerPage), headers, &releases)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []string
+
+	for _, release := range releases {
+		if !release.Draft && release.PublishedAt != "" {
+			dates = append(dates, release.PublishedAt)
+		}
+	}
+
+	if len(dates) > 0 {
+		return dates, nil
+	}
+
+	tags, _, err := fetchPages[gitHubTag](ctx, client, repoAPIURL+"/tags?per_page="+strconv.Itoa(tagsPerPage), headers,
+		maxTagPages, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range newestTags(tags) {
+		var commitData CommitData
+
+		err = fetchJSONData(ctx, client, repoAPIURL+"/commits/"+tag.Commit.SHA, headers, &commitData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the commit of tag %s: %w", tag.Name, err)
+		}
+
+		dates = append(dates, commitData.Commit.Committer.Date)
+	}
+
+	return dates, nil
+}
+
+// newestTags returns the maxTagDates newest tags by version. The REST API lists tags by name,
+// where v9.0.0 sorts after v10.0.0, unlike GraphQL, which orders them by commit date.
+// Tags that are not versions follow the versions in the order of the API.
+func newestTags(tags []gitHubTag) []gitHubTag {
+	sorted := slices.Clone(tags)
+
+	slices.SortStableFunc(sorted, func(a, b gitHubTag) int {
+		aVersion, bVersion := tagVersion(a.Name), tagVersion(b.Name)
+
+		switch {
+		case aVersion != "" && bVersion != "":
+			return semver.Compare(bVersion, aVersion)
+		case aVersion != "":
+			return -1
+		case bVersion != "":
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return sorted[:min(len(sorted), maxTagDates)]
+}
+
+// tagVersion reads a tag like v1.2.3 or 1.2.3 as a semantic version, or returns "".
+func tagVersion(name string) string {
+	version := "v" + strings.TrimPrefix(name, "v")
+	if !semver.IsValid(version) {
+		return ""
+	}
+
+	return version
+}
+
+// setReleaseCadence fills the release metrics of repoInfo from release dates in any order.
+// Without any release the number of releases is 0 and the dates and gaps are left unknown;
+// releaseAgeDate then counts the release age from the creation of the repository.
+// Dates that do not parse are ignored.
+func setReleaseCadence(repoInfo *RepoInfo, releaseDates []string, now time.Time) {
+	var dates []time.Time
+
+	for _, releaseDate := range releaseDates {
+		parsed, err := time.Parse(time.RFC3339, releaseDate)
+		if err == nil {
+			dates = append(dates, parsed.UTC())
+		}
+	}
+
+	slices.SortFunc(dates, func(a, b time.Time) int { return b.Compare(a) })
+
+	releasesLastYear := 0
+
+	for _, date := range dates {
+		if now.Sub(date) <= daysOfYear*hoursOfDay*time.Hour {
+			releasesLastYear++
+		}
+	}
+
+	repoInfo.ReleasesLastYear = Ptr(releasesLastYear)
+
+	if len(dates) == 0 {
+		return
+	}
+
+	repoInfo.LastReleaseDate = Ptr(dates[0].Format(gitHubDateLayout))
+
+	if len(dates) < 2 {
+		return
+	}
+
+	gaps := make([]int, len(dates)-1)
+	for i := range gaps {
+		gaps[i] = int(dates[i].Sub(dates[i+1]).Hours() / hoursOfDay)
+	}
+
+	repoInfo.MedianReleaseGapDays = Ptr(median(gaps))
+}
+
+// releaseAgeDate is the date CalcScore counts the release age from: the last release, or the
+// creation of a repository whose releases were read and found none, so that never releasing
+// costs at least as much as the oldest release could. It is nil when the releases were not read.
+func (r *RepoInfo) releaseAgeDate() *string {
+	if r.LastReleaseDate == nil && r.ReleasesLastYear != nil && *r.ReleasesLastYear == 0 {
+		return r.CreatedDate
+	}
+
+	return r.LastReleaseDate
+}
+
+// median returns the middle value of values, or the mean of the two middle ones.
+func median(values []int) int {
+	sorted := slices.Sorted(slices.Values(values))
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
@@ -0,0 +1,97 @@
+//nolint:testpackage // Tests unexported release metrics
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetReleaseCadence(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	info := &RepoInfo{}
+	setReleaseCadence(info, []string{
+		"2023-01-01T00:00:00Z",
+		"2024-05-01T00:00:00+02:00", // out of order and not in UTC
+		"2024-03-02T00:00:00Z",
+		"not-a-date",
+	}, now)
+
+	assert.Equal(t, Ptr("2024-04-30T22:00:00Z"), info.LastReleaseDate)
+	assert.Equal(t, Ptr(2), info.ReleasesLastYear)
+	// gaps of 59 and 426 days
+	assert.Equal(t, Ptr((59+426)/2), info.MedianReleaseGapDays)
+}
+
+func TestSetReleaseCadence_NoReleases(t *testing.T) {
+	t.Parallel()
+
+	info := &RepoInfo{}
+	setReleaseCadence(info, nil, time.Now())
+
+	assert.Equal(t, Ptr(0), info.ReleasesLastYear)
+	assert.Nil(t, info.LastReleaseDate)
+	assert.Nil(t, info.MedianReleaseGapDays)
+}
+
+func TestCalcScore_NeverReleasedCountsFromCreation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	created := now.AddDate(-3, 0, 0).Format(gitHubDateLayout)
+	weights := &ParameterWeights{LastReleaseDate: -1}
+
+	neverReleased := &RepoInfo{CreatedDate: Ptr(created)}
+	setReleaseCadence(neverReleased, nil, now)
+
+	releasedLastYear := &RepoInfo{CreatedDate: Ptr(created)}
+	setReleaseCadence(releasedLastYear, []string{now.AddDate(-1, 0, 0).Format(time.RFC3339)}, now)
+
+	notRead := &RepoInfo{CreatedDate: Ptr(created)}
+
+	CalcScore(neverReleased, weights)
+	CalcScore(releasedLastYear, weights)
+	CalcScore(notRead, weights)
+
+	assert.Nil(t, neverReleased.LastReleaseDate)
+	assert.Less(t, neverReleased.Score, releasedLastYear.Score)
+	assert.InDelta(t, -3*daysOfYear, neverReleased.Score, 2)
+	assert.Zero(t, notRead.Score, "releases that were not read are unknown")
+}
+
+func TestMedian(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 5, median([]int{9, 1, 5}))
+	assert.Equal(t, 4, median([]int{8, 1, 3, 5}))
+}
+
+func TestNewestTags(t *testing.T) {
+	t.Parallel()
+
+	tags := []gitHubTag{{Name: "latest"}, {Name: "v1.10.0"}, {Name: "1.9.0"}, {Name: "v1.11.0-rc.1"}, {Name: "v2.0.0"}}
+	for i := range maxTagDates {
+		tags = append(tags, gitHubTag{Name: fmt.Sprintf("v0.%d.0", i)})
+	}
+
+	newest := newestTags(tags)
+
+	assert.Len(t, newest, maxTagDates)
+	assert.Equal(t, []string{"v2.0.0", "v1.11.0-rc.1", "v1.10.0", "1.9.0"}, tagNames(newest[:4]))
+	assert.Equal(t, "v0.9.0", newest[4].Name)
+	assert.Equal(t, "latest", newestTags(tags[:2])[1].Name, "tags that are not versions come last")
+}
+
+func tagNames(tags []gitHubTag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
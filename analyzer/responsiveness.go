@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	lookbackPerPage    = 100
+	maxLookbackPages   = 5  // pages of issues and of pull requests read per repository
+	maxResponseSamples = 10 // newest issues whose comments are read for the first maintainer response
+)
+
+// maintainerAssociations are the author associations of the people who triage a repository.
+var maintainerAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+type gitHubIssue struct {
+	Number            int       `json:"number"`
+	CreatedAt         string    `json:"created_at"`
+	ClosedAt          string    `json:"closed_at"` // empty while open
+	Comments          int       `json:"comments"`
+	AuthorAssociation string    `json:"author_association"`
+	PullRequest       *struct{} `json:"pull_request"` // set for pull requests, which the issues API lists too
+}
+
+type gitHubComment struct {
+	CreatedAt         string `json:"created_at"`
+	AuthorAssociation string `json:"author_association"`
+}
+
+type gitHubPull struct {
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	MergedAt  string `json:"merged_at"` // empty for pull requests closed without merging
+}
+
+// issueActivity is what the responsiveness metrics are computed from.
+type issueActivity struct {
+	issues          []gitHubIssue     // issues and pull requests updated since the start of the lookback window
+	issuesTruncated bool              // more issues were updated than maxLookbackPages pages hold
+	pulls           []gitHubPull      // closed pull requests, the most recently updated first
+	firstResponses  map[int]time.Time // by issue number, for the sampled issues a maintainer answered
+}
+
+// fetchIssueActivity reads the issues and pull requests of the lookback window starting at since.
+// Both lists stop after maxLookbackPages pages, and only the maxResponseSamples newest issues have
+// their comments read, so a repository costs at most 2*maxLookbackPages+maxResponseSamples requests.
+func fetchIssueActivity(
+	ctx context.Context,
+	client *apiClient,
+	apiBaseURL, owner, repo string,
+	headers map[string]string,
+	since time.Time,
+) (*issueActivity, error) {
+	repoAPIURL := apiBaseURL + "/repos/" + owner + "/" + repo
+	perPage := strconv.Itoa(lookbackPerPage)
+
+	// since leaves out the issues not updated in the window, so there is nothing to stop at
+	issuesURL := repoAPIURL + "/issues?state=all&sort=updated&direction=desc&per_page=" + perPage +
+		"&since=" + since.UTC().Format(gitHubDateLayout)
+
+	issues, issuesTruncated, err := fetchPages[gitHubIssue](ctx, client, issuesURL, headers, maxLookbackPages, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pullsURL := repoAPIURL + "/pulls?state=closed&sort=updated&direction=desc&per_page=" + perPage
+
+	pulls, _, err := fetchPages(ctx, client, pullsURL, headers, maxLookbackPages, func(page []gitHubPull) bool {
+		updatedAt, ok := parseGitHubTime(page[len(page)-1].UpdatedAt)
+
+		return ok && updatedAt.Before(since)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &issueActivity{
+		issues:          issues,
+		issuesTruncated: issuesTruncated,
+		pulls:           pulls,
+		firstResponses:  map[int]time.Time{},
+	}
+
+	for _, issue := range responseSample(issues, since) {
+		if issue.Comments == 0 {
+			continue
+		}
+
+		var comments []gitHubComment
+
+		commentsURL := repoAPIURL + "/issues/" + strconv.Itoa(issue.Number) + "/comments?per_page=" + perPage
+
+		err = fetchJSONData(ctx, client, commentsURL, headers, &comments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the comments of issue %d: %w", issue.Number, err)
+		}
+
+		for _, comment := range comments {
+			respondedAt, ok := parseGitHubTime(comment.CreatedAt)
+			if ok && slices.Contains(maintainerAssociations, comment.AuthorAssociation) {
+				activity.firstResponses[issue.Number] = respondedAt
+
+				break
+			}
+		}
+	}
+
+	return activity, nil
+}
+
+// setResponsiveness fills the responsiveness metrics of repoInfo from the activity since the start
+// of the lookback window. An issue without a maintainer comment counts as answered when it was
+// closed, and as waiting until now while it is open. Metrics without any issue or merged pull
+// request to measure are left unknown, and so is the close ratio of a truncated issue list, which
+// would count the issues closed recently against those opened recently.
+func setResponsiveness(repoInfo *RepoInfo, activity *issueActivity, since, now time.Time) {
+	opened, closed := 0, 0
+
+	for _, issue := range activity.issues {
+		if issue.PullRequest != nil {
+			continue
+		}
+
+		if createdAt, ok := parseGitHubTime(issue.CreatedAt); ok && !createdAt.Before(since) {
+			opened++
+		}
+
+		if closedAt, ok := parseGitHubTime(issue.ClosedAt); ok && !closedAt.Before(since) {
+			closed++
+		}
+	}
+
+	switch {
+	case activity.issuesTruncated:
+		repoInfo.Incomplete = append(repoInfo.Incomplete,
+			"issues of the lookback window cut at "+strconv.Itoa(lookbackPerPage*maxLookbackPages))
+	case opened > 0:
+		repoInfo.IssueCloseRatio = Ptr(closed * percent / opened)
+	}
+
+	var responseHours []int
+
+	for _, issue := range responseSample(activity.issues, since) {
+		createdAt, _ := parseGitHubTime(issue.CreatedAt)
+
+		respondedAt, answered := activity.firstResponses[issue.Number]
+		if !answered {
+			respondedAt = now
+
+			if closedAt, ok := parseGitHubTime(issue.ClosedAt); ok {
+				respondedAt = closedAt
+			}
+		}
+
+		responseHours = append(responseHours, int(respondedAt.Sub(createdAt).Hours()))
+	}
+
+	if len(responseHours) > 0 {
+		repoInfo.MedianIssueResponseHours = Ptr(median(responseHours))
+	}
+
+	var mergeDays []int
+
+	for _, pull := range activity.pulls {
+		createdAt, created := parseGitHubTime(pull.CreatedAt)
+		mergedAt, merged := parseGitHubTime(pull.MergedAt)
+
+		if created && merged && !mergedAt.Before(since) {
+			mergeDays = append(mergeDays, int(mergedAt.Sub(createdAt).Hours()/hoursOfDay))
+		}
+	}
+
+	if len(mergeDays) > 0 {
+		repoInfo.MedianMergeDays = Ptr(median(mergeDays))
+	}
+}
+
+// responseSample returns the maxResponseSamples newest issues opened since the start of the
+// lookback window by people other than the maintainers.
+func responseSample(issues []gitHubIssue, since time.Time) []gitHubIssue {
+	var sample []gitHubIssue
+
+	for _, issue := range issues {
+		createdAt, ok := parseGitHubTime(issue.CreatedAt)
+		if ok && !createdAt.Before(since) && issue.PullRequest == nil &&
+			!slices.Contains(maintainerAssociations, issue.AuthorAssociation) {
+			sample = append(sample, issue)
+		}
+	}
+
+	// the timestamps are all UTC in the same layout, so they sort as strings
+	slices.SortFunc(sample, func(a, b gitHubIssue) int { return cmp.Compare(b.CreatedAt, a.CreatedAt) })
+
+	return sample[:min(len(sample), maxResponseSamples)]
+}
+
+// parseGitHubTime parses a timestamp of the GitHub API; null timestamps decode as "" and do not parse.
+func parseGitHubTime(value string) (time.Time, bool) {
+	parsed, err := time.Parse(time.RFC3339, value)
+
+	return parsed, err == nil
+}
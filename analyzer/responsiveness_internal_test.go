@@ -0,0 +1,104 @@
+//nolint:testpackage // Tests unexported responsiveness metrics
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPages_StopsAtLimitAndOlderPages(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	var server *httptest.Server
+
+	// an endless list whose items are older from page to page
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("Link", "<"+server.URL+"/pulls?page="+strconv.Itoa(page+1)+`>; rel="next"`)
+		_, _ = w.Write([]byte(`[{"updated_at": "` + strconv.Itoa(2024-page) + `-01-01T00:00:00Z"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	var waits []time.Duration
+
+	client := newTestClient(0, &waits)
+
+	pulls, truncated, err := fetchPages[gitHubPull](context.Background(), client, server.URL+"/pulls?page=0", nil,
+		maxLookbackPages, nil)
+	require.NoError(t, err)
+	assert.Len(t, pulls, maxLookbackPages)
+	assert.True(t, truncated)
+
+	calls.Store(0)
+	since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	pulls, truncated, err = fetchPages(context.Background(), client, server.URL+"/pulls?page=0", nil, maxLookbackPages,
+		func(page []gitHubPull) bool {
+			updatedAt, _ := parseGitHubTime(page[len(page)-1].UpdatedAt)
+
+			return updatedAt.Before(since)
+		})
+	require.NoError(t, err)
+	assert.Len(t, pulls, 3) // 2024, 2023 and the first page older than since
+	assert.Equal(t, int32(3), calls.Load())
+	assert.False(t, truncated)
+}
+
+func TestSetResponsiveness_TruncatedIssuesLeaveCloseRatioUnknown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, -6, 0)
+
+	info := &RepoInfo{}
+	setResponsiveness(info, &issueActivity{
+		issues: []gitHubIssue{
+			{Number: 2, CreatedAt: "2024-05-31T00:00:00Z", ClosedAt: "2024-05-31T12:00:00Z", AuthorAssociation: "NONE"},
+		},
+		issuesTruncated: true,
+		firstResponses:  map[int]time.Time{},
+	}, since, now)
+
+	assert.Nil(t, info.IssueCloseRatio)
+	assert.Equal(t, Ptr(12), info.MedianIssueResponseHours)
+	assert.Equal(t, []string{"issues of the lookback window cut at 500"}, info.Incomplete)
+}
+
+func TestSetResponsiveness_UnansweredIssueWaitsUntilNow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, -6, 0)
+
+	info := &RepoInfo{}
+	setResponsiveness(info, &issueActivity{
+		issues: []gitHubIssue{
+			{Number: 1, CreatedAt: "2024-05-31T00:00:00Z", AuthorAssociation: "NONE"},
+		},
+		pulls:          []gitHubPull{{CreatedAt: "2024-05-01T00:00:00Z", UpdatedAt: "2024-05-02T00:00:00Z"}},
+		firstResponses: map[int]time.Time{},
+	}, since, now)
+
+	assert.Equal(t, Ptr(24), info.MedianIssueResponseHours)
+	assert.Equal(t, Ptr(0), info.IssueCloseRatio)
+	assert.Nil(t, info.MedianMergeDays) // closed without merging
+
+	empty := &RepoInfo{}
+	setResponsiveness(empty, &issueActivity{}, since, now)
+
+	assert.Nil(t, empty.MedianIssueResponseHours)
+	assert.Nil(t, empty.IssueCloseRatio)
+	assert.Nil(t, empty.MedianMergeDays)
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const defaultSourceHutURL = "https://git.sr.ht"
+
+// sourceHutFeed is the RSS feed of the commit log, newest first.
+type sourceHutFeed struct {
+	Items []struct {
+		PubDate string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+// SourceHutRepoAnalyzer analyzes git.sr.ht repositories. SourceHut keeps issues and patches on
+// other services and has no stars, forks or watchers, so only the last commit date is scored.
+// It is read from the public RSS feed of the log, which needs no token.
+type SourceHutRepoAnalyzer struct {
+	weights ParameterWeights
+	options options
+}
+
+func NewSourceHutRepoAnalyzer(weights ParameterWeights, opts ...Option) *SourceHutRepoAnalyzer {
+	return &SourceHutRepoAnalyzer{
+		weights: weights,
+		options: newOptions(opts),
+	}
+}
+
+// FetchRepoInfo fetches the repositories with a bounded number of workers.
+func (s *SourceHutRepoAnalyzer) FetchRepoInfo(ctx context.Context, repositoryUrls []string) []RepoInfo {
+	client := newAPIClient(s.options, utils.CacheSourceSourceHut)
+
+	return fetchEach(ctx, s.options, ForgeSourceHut, repositoryUrls, func(ctx context.Context, repoURL string) (
+		*RepoInfo, error,
+	) {
+		return s.getSourceHutInfo(ctx, client, repoURL)
+	})
+}
+
+func (s *SourceHutRepoAnalyzer) getSourceHutInfo(
+	ctx context.Context,
+	client *apiClient,
+	repoURL string,
+) (*RepoInfo, error) {
+	owner, repo, err := ownerAndRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	feedURL := s.options.api(defaultSourceHutURL) + "/" + owner + "/" + repo + "/log/rss.xml"
+
+	body, err := client.send(ctx, http.MethodGet, feedURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed sourceHutFeed
+
+	err = xml.Unmarshal(body, &feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode RSS feed %s: %w", feedURL, err)
+	}
+
+	if len(feed.Items) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCommits, repoURL)
+	}
+
+	repoInfo := &RepoInfo{
+		RepositoryName: repo,
+		LastCommitDate: Ptr(utcDate(feed.Items[0].PubDate, time.RFC1123Z)),
+	}
+
+	CalcScore(repoInfo, &s.weights)
+
+	return repoInfo, nil
+}
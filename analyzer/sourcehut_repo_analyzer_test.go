@@ -0,0 +1,56 @@
+package analyzer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+)
+
+func TestSourceHutRepoAnalyzer_FetchRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/~sircmpwn/scdoc/log/rss.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>scdoc log</title>
+<item><title>Release 1.11.3</title><pubDate>Tue, 02 Jan 2024 15:04:05 +0100</pubDate></item>
+<item><title>Older</title><pubDate>Mon, 01 Jan 2024 00:00:00 +0000</pubDate></item>
+</channel></rss>`))
+		case "/~someone/empty/log/rss.xml":
+			_, _ = w.Write([]byte(`<rss version="2.0"><channel></channel></rss>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// the weights of metrics SourceHut does not have must not count
+	weights := analyzer.ParameterWeights{Watchers: 1, Stars: 1, Forks: 1, OpenIssues: 1, Archived: -1000}
+
+	repoAnalyzer := analyzer.NewSourceHutRepoAnalyzer(weights, analyzer.WithAPIURL(server.URL))
+	repoInfos := repoAnalyzer.FetchRepoInfo(context.Background(), []string{
+		"https://git.sr.ht/~sircmpwn/scdoc/tree",
+		"https://git.sr.ht/~someone/empty",
+	})
+
+	require.Len(t, repoInfos, 2)
+
+	info := repoInfos[0]
+	assert.False(t, info.Skip, info.SkipReason)
+	assert.Equal(t, analyzer.ForgeSourceHut, info.Forge)
+	assert.Equal(t, "scdoc", info.RepositoryName)
+	assert.Equal(t, analyzer.Ptr("2024-01-02T14:04:05Z"), info.LastCommitDate)
+	assert.Equal(t, 0, info.Score)
+	assert.NotNil(t, info.LastCommitDate)
+	assert.Nil(t, info.Stars)
+
+	assert.True(t, repoInfos[1].Skip)
+	assert.Equal(t, "Failed fetching https://git.sr.ht/~someone/empty from SourceHut", repoInfos[1].SkipReason)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/uzumaki-inc/stay_or_go/advisory"
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/presenter"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+var (
+	advisoryPath string
+	advisoryDB   *advisory.Database // loaded from --advisories before running, nil without it
+
+	// the OSV ecosystem of the versions each language pins
+	advisoryEcosystems = map[string]string{
+		"go":   advisory.EcosystemGo,
+		"ruby": advisory.EcosystemRubyGems,
+	}
+
+	ErrInvalidAdvisoryFlags = errors.New("invalid advisory flags")
+)
+
+// setUpAdvisories loads the --advisories database once, keeping only the ecosystem of the language analyzed.
+func setUpAdvisories(args []string) error {
+	advisoryDB = nil
+
+	if advisoryPath == "" || len(args) == 0 {
+		return nil
+	}
+
+	ecosystem, ok := advisoryEcosystems[args[0]]
+	if !ok {
+		languages := strings.Join(slices.Sorted(maps.Keys(advisoryEcosystems)), " and ")
+
+		return fmt.Errorf("%w: --advisories supports %s", ErrInvalidAdvisoryFlags, languages)
+	}
+
+	database, err := advisory.Load(advisoryPath, ecosystem)
+	if err != nil {
+		return fmt.Errorf("--advisories: %w", err)
+	}
+
+	advisoryDB = database
+
+	return nil
+}
+
+// matchAdvisories checks the pinned version of each library against the database and scores the
+// advisories affecting it. Libraries without a version, like the gems of a Gemfile without its
+// lock file, are left unchecked.
+func matchAdvisories(
+	database *advisory.Database,
+	analyzedLibInfos []presenter.AnalyzedLibInfo,
+	ecosystem string,
+	weights *analyzer.ParameterWeights,
+) {
+	for i, info := range analyzedLibInfos {
+		others := info.LibInfo.Others
+		if len(others) < 2 || others[1] == "" {
+			continue
+		}
+
+		vulnerabilities := database.Match(ecosystem, others[0], others[1])
+		analyzedLibInfos[i].Vulnerabilities = vulnerabilities
+
+		if len(vulnerabilities) > 0 {
+			utils.DebugPrintln(fmt.Sprintf("%s %s has %d advisories", others[0], others[1], len(vulnerabilities)))
+		}
+
+		if info.RepoInfo != nil {
+			analyzer.AddVulnerabilityScore(info.RepoInfo, len(vulnerabilities), weights)
+		}
+	}
+}
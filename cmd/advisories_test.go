@@ -0,0 +1,67 @@
+//nolint:testpackage // Tests unexported functions
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/advisory"
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/parser"
+	"github.com/uzumaki-inc/stay_or_go/presenter"
+)
+
+const rackAdvisory = `{
+  "id": "GHSA-aaaa-bbbb-cccc",
+  "database_specific": {"severity": "HIGH"},
+  "affected": [{
+    "package": {"ecosystem": "RubyGems", "name": "rack"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.2.8"}]}]
+  }]
+}`
+
+func TestMatchAdvisories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GHSA-aaaa-bbbb-cccc.json"), []byte(rackAdvisory), 0o600))
+
+	database, err := advisory.Load(dir, advisory.EcosystemRubyGems)
+	require.NoError(t, err)
+
+	weights := analyzer.NewParameterWeights()
+	vulnerable := &analyzer.RepoInfo{Score: 100}
+	patched := &analyzer.RepoInfo{Score: 100}
+	infos := []presenter.AnalyzedLibInfo{
+		{LibInfo: &parser.LibInfo{Name: "rack", Others: []string{"rack", "2.2.7"}}, RepoInfo: vulnerable},
+		{LibInfo: &parser.LibInfo{Name: "rack", Others: []string{"rack", "2.2.8"}}, RepoInfo: patched},
+		{LibInfo: &parser.LibInfo{Name: "rails"}, RepoInfo: nil},
+	}
+
+	matchAdvisories(database, infos, advisory.EcosystemRubyGems, &weights)
+
+	assert.Equal(t, []advisory.Advisory{
+		{ID: "GHSA-aaaa-bbbb-cccc", Severity: "HIGH", FixedVersions: []string{"2.2.8"}},
+	}, infos[0].Vulnerabilities)
+	assert.Equal(t, 80, vulnerable.Score)
+	assert.Empty(t, infos[1].Vulnerabilities)
+	assert.NotNil(t, infos[1].Vulnerabilities)
+	assert.Equal(t, 100, patched.Score)
+	assert.Nil(t, infos[2].Vulnerabilities, "a gem without a locked version is not checked")
+}
+
+//nolint:paralleltest // setUpAdvisories sets the package-level database
+func TestSetUpAdvisories_UnsupportedLanguage(t *testing.T) {
+	advisoryPath = t.TempDir()
+
+	t.Cleanup(func() { advisoryPath = "" })
+
+	err := setUpAdvisories([]string{"node"})
+	require.ErrorIs(t, err, ErrInvalidAdvisoryFlags)
+	assert.Contains(t, err.Error(), "go and ruby")
+	assert.Nil(t, advisoryDB)
+}
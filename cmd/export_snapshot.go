@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/uzumaki-inc/stay_or_go/presenter"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const defaultSnapshotPath = "stay_or_go_snapshot.json"
+
+var snapshotOutput string
+
+var exportSnapshotCmd = &cobra.Command{
+	Use:   "export-snapshot [language]",
+	Short: "Fetch everything an analysis needs and write it to a snapshot file for --offline",
+	Long: `export-snapshot runs the analysis of a dependency file online and writes every response it used
+(rubygems.org, the Go module proxy, go-import meta tags and GitHub) to one snapshot file.
+Copy the file to a machine without network access and run stay_or_go there with --offline --snapshot.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: setUp,
+	Run: func(_ *cobra.Command, args []string) {
+		err := exportSnapshot(args[0], snapshotOutput, defaultDeps)
+		if err != nil {
+			utils.StdErrorPrintln("Error exporting snapshot: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+type discardPresenter struct{}
+
+func (discardPresenter) Display() {}
+
+// exportSnapshot records the responses of one analysis instead of displaying its result.
+// The cache is read as usual, so a fresh cache makes the export cheap.
+func exportSnapshot(language, output string, deps Deps) error {
+	if !slices.Contains(offlineLanguages, language) {
+		return fmt.Errorf("%w: --offline supports %s", ErrInvalidOfflineFlags, strings.Join(offlineLanguages, " and "))
+	}
+
+	if responseCache == nil {
+		responseCache = utils.NewCache("", utils.DefaultCacheTTLs())
+	}
+
+	responseCache.Record()
+
+	deps.SelectPresenter = func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort {
+		return discardPresenter{}
+	}
+
+	err := run(language, filePath, "markdown", githubToken, configFilePath, utils.Verbose, deps)
+	if err != nil {
+		return err
+	}
+
+	count, err := responseCache.WriteSnapshot(output)
+	if err != nil {
+		return fmt.Errorf("export snapshot: %w", err)
+	}
+
+	utils.StdErrorPrintln("Wrote %d responses to %s", count, output)
+
+	return nil
+}
+
+func init() {
+	exportSnapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", defaultSnapshotPath,
+		"File to write the snapshot to")
+	rootCmd.AddCommand(exportSnapshotCmd)
+}
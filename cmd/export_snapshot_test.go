@@ -0,0 +1,60 @@
+//nolint:testpackage // Tests unexported functions
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/parser"
+	"github.com/uzumaki-inc/stay_or_go/presenter"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+//nolint:paralleltest // uses the package level response cache
+func TestExportSnapshot(t *testing.T) {
+	responseCache = nil
+
+	t.Cleanup(func() { responseCache = nil })
+
+	recPresenter := &recorderPresenter{}
+	deps := Deps{
+		NewAnalyzer:  func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer { return &stubAnalyzer{} },
+		SelectParser: func(_ string) (parser.Parser, error) { return &recorderParser{}, nil },
+		SelectPresenter: func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort {
+			return recPresenter
+		},
+	}
+
+	t.Setenv("GITHUB_TOKEN", "tok")
+
+	output := filepath.Join(t.TempDir(), "snapshot.json")
+
+	err := exportSnapshot("go", output, deps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recPresenter.displayed {
+		t.Fatalf("expected the report not to be displayed")
+	}
+
+	_, err = utils.LoadSnapshot(output)
+	if err != nil {
+		t.Fatalf("expected a readable snapshot: %v", err)
+	}
+
+	nodeOutput := filepath.Join(t.TempDir(), "node.json")
+
+	err = exportSnapshot("node", nodeOutput, deps)
+	if !errors.Is(err, ErrInvalidOfflineFlags) {
+		t.Fatalf("expected node to be rejected, got %v", err)
+	}
+
+	_, err = os.Stat(nodeOutput)
+	if err == nil {
+		t.Fatalf("expected no snapshot for node")
+	}
+}
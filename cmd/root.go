@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,17 +20,42 @@ import (
 	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
+const (
+	defaultConcurrency = 4
+)
+
 // var greeting string
 var (
-	filePath       string
-	outputFormat   string
-	githubToken    string
-	configFilePath string
-
-	supportedLanguages = []string{"ruby", "go"}
+	filePath        string
+	outputFormat    string
+	githubToken     string
+	gitlabToken     string
+	bitbucketToken  string
+	codebergToken   string
+	configFilePath  string
+	includeIndirect bool
+	concurrency     int
+	useGraphQL      bool
+	retryBudget     time.Duration
+	lookbackMonths  int
+	showSparkline   bool
+	noCache         bool
+	cacheTTL        string
+	offline         bool
+	snapshotPath    string
+	githubHostSpecs []string
+	hostTokens      string
+	responseCache   *utils.Cache       // set up from the cache and offline flags before running
+	gitHubHosts     *utils.GitHubHosts // set up from --github-host and --host-tokens before running
+
+	supportedLanguages = []string{"ruby", "go", "node", "python", "rust"}
+	offlineLanguages   = []string{"ruby", "go"}
 	languageConfigMap  = map[string]string{
-		"ruby": "Gemfile",
-		"go":   "go.mod",
+		"ruby":   "Gemfile",
+		"go":     "go.mod",
+		"node":   "package.json",
+		"python": "requirements.txt",
+		"rust":   "Cargo.toml",
 	}
 	supportedOutputFormats = map[string]bool{
 		"csv":      true,
@@ -34,15 +64,11 @@ var (
 	}
 
 	// Sentinel errors for wrapping
-	ErrUnsupportedFormat  = errors.New("unsupported format")
-	ErrMissingGithubToken = errors.New("missing github token")
+	ErrUnsupportedFormat   = errors.New("unsupported format")
+	ErrMissingGithubToken  = errors.New("missing github token")
+	ErrInvalidOfflineFlags = errors.New("invalid offline flags")
 )
 
-// AnalyzerPort is a minimal adapter for analyzer used by cmd to enable testing with stubs.
-type AnalyzerPort interface {
-	FetchGithubInfo(repositoryUrls []string) []analyzer.GitHubRepoInfo
-}
-
 // PresenterPort narrows the presenter to only what's used here.
 type PresenterPort interface {
 	Display()
@@ -50,18 +76,55 @@ type PresenterPort interface {
 
 // Deps bundles injectable constructors/selectors for testability.
 type Deps struct {
-	NewAnalyzer     func(token string, weights analyzer.ParameterWeights) AnalyzerPort
+	NewAnalyzer     func(token string, weights analyzer.ParameterWeights) analyzer.RepoAnalyzer
 	SelectParser    func(language string) (parser.Parser, error)
 	SelectPresenter func(format string, analyzedLibInfos []presenter.AnalyzedLibInfo) PresenterPort
 }
 
 var defaultDeps = Deps{
-	NewAnalyzer: func(token string, weights analyzer.ParameterWeights) AnalyzerPort {
-		return analyzer.NewGitHubRepoAnalyzer(token, weights)
+	NewAnalyzer: func(token string, weights analyzer.ParameterWeights) analyzer.RepoAnalyzer {
+		options := []analyzer.Option{
+			analyzer.WithConcurrency(concurrency),
+			analyzer.WithRetryBudget(retryBudget),
+			analyzer.WithLookbackMonths(lookbackMonths),
+			analyzer.WithCache(responseCache),
+			analyzer.WithGitHubHosts(gitHubHosts),
+		}
+
+		var githubAnalyzer analyzer.RepoAnalyzer = analyzer.NewGitHubRepoAnalyzer(token, weights, options...)
+
+		if useGraphQL {
+			githubAnalyzer = analyzer.NewGitHubGraphQLAnalyzer(token, weights, options...)
+		}
+
+		// backends registered outside stay_or_go come first, so they may take over a built-in host
+		registry := analyzer.NewRegistry()
+		analyzer.RegisterBackends(registry, weights, options...)
+
+		for _, host := range gitHubHosts.Names() {
+			registry.Register(host, githubAnalyzer)
+		}
+
+		registry.Register(utils.PublicGitLabHost,
+			analyzer.NewGitLabRepoAnalyzer(cmp.Or(gitlabToken, os.Getenv("GITLAB_TOKEN")), weights, options...))
+		registry.Register(utils.PublicBitbucketHost,
+			analyzer.NewBitbucketRepoAnalyzer(cmp.Or(bitbucketToken, os.Getenv("BITBUCKET_TOKEN")), weights, options...))
+		registry.Register(utils.CodebergHost,
+			analyzer.NewGiteaRepoAnalyzer(cmp.Or(codebergToken, os.Getenv("CODEBERG_TOKEN")), weights, options...))
+		registry.Register(utils.SourceHutGitHost, analyzer.NewSourceHutRepoAnalyzer(weights, options...))
+
+		return registry
+	},
+	SelectParser: func(language string) (parser.Parser, error) {
+		return parser.SelectParser(language,
+			parser.WithIncludeIndirect(includeIndirect), parser.WithConcurrency(concurrency),
+			parser.WithCache(responseCache), parser.WithGitHubHosts(gitHubHosts))
 	},
-	SelectParser: parser.SelectParser,
 	SelectPresenter: func(format string, analyzedLibInfos []presenter.AnalyzedLibInfo) PresenterPort {
-		return presenter.SelectPresenter(format, analyzedLibInfos)
+		return presenter.SelectPresenter(format, analyzedLibInfos,
+			presenter.WithDirect(includeIndirect), presenter.WithUsedBy(readsWorkspace(analyzedLibInfos)),
+			presenter.WithSparkline(showSparkline),
+			presenter.WithAdvisories(advisoryDB != nil), presenter.WithIncomplete(countIncomplete(analyzedLibInfos) > 0))
 	},
 }
 
@@ -71,10 +134,12 @@ var defaultDeps = Deps{
 var rootCmd = &cobra.Command{
 	Use:     "stay_or_go",
 	Version: "0.1.2",
-	Short:   "Analyze and score your Go and Ruby dependencies for popularity and maintenance",
-	Long: `stay_or_go scans your Go (go.mod) and Ruby (Gemfile) dependency files to evaluate each library's popularity and maintenance status.
+	Short:   "Analyze and score your Go, Ruby, Node.js, Python and Rust dependencies for popularity and maintenance",
+	Long: `stay_or_go scans your Go (go.mod, go.work), Ruby (Gemfile), Node.js (package.json), Python (requirements.txt, pyproject.toml, poetry.lock) and Rust (Cargo.toml) dependency files to evaluate each library's popularity and maintenance status.
 It generates scores to help you decide whether to keep (‘Stay’) or replace (‘Go’) your dependencies.
 Output the results in Markdown, CSV, or TSV formats.`,
+	Args:    cobra.ArbitraryArgs,
+	PreRunE: setUp,
 	Run: func(_ *cobra.Command, args []string) {
 		if len(args) == 0 {
 			fmt.Fprintln(os.Stderr, "Please Enter specify a language ("+
@@ -91,6 +156,111 @@ Output the results in Markdown, CSV, or TSV formats.`,
 	},
 }
 
+// setUp prepares what the flags configure before the analysis runs.
+func setUp(cmd *cobra.Command, args []string) error {
+	err := setUpGitHubHosts()
+	if err != nil {
+		return err
+	}
+
+	err = setUpAdvisories(args)
+	if err != nil {
+		return err
+	}
+
+	return setUpCache(cmd, args)
+}
+
+// setUpGitHubHosts adds the --github-host Enterprise hosts next to github.com. Their tokens come from
+// --host-tokens or STAY_OR_GO_HOST_TOKENS; github.com keeps using --github-token unless mapped there.
+func setUpGitHubHosts() error {
+	hosts := utils.NewGitHubHosts("")
+
+	for _, spec := range githubHostSpecs {
+		err := hosts.AddEnterprise(spec)
+		if err != nil {
+			return fmt.Errorf("--github-host: %w", err)
+		}
+	}
+
+	tokens := hostTokens
+	if tokens == "" {
+		tokens = os.Getenv("STAY_OR_GO_HOST_TOKENS")
+	}
+
+	err := hosts.SetTokens(tokens)
+	if err != nil {
+		return fmt.Errorf("--host-tokens: %w", err)
+	}
+
+	gitHubHosts = hosts
+
+	return nil
+}
+
+// setUpCache opens the response cache under $XDG_CACHE_HOME/stay_or_go unless --no-cache is given.
+// With --offline the responses come from the cache or --snapshot only.
+func setUpCache(_ *cobra.Command, args []string) error {
+	responseCache = nil
+
+	if offline || snapshotPath != "" {
+		return setUpOfflineCache(args)
+	}
+
+	if noCache {
+		return nil
+	}
+
+	ttls, err := utils.ParseCacheTTLs(cacheTTL)
+	if err != nil {
+		return fmt.Errorf("--cache-ttl: %w", err)
+	}
+
+	dir, err := utils.DefaultCacheDir()
+	if err != nil {
+		utils.StdErrorPrintln("Caching disabled: %v", err)
+
+		return nil
+	}
+
+	utils.DebugPrintln("Cache directory: " + dir)
+	responseCache = utils.NewCache(dir, ttls)
+
+	return nil
+}
+
+func setUpOfflineCache(args []string) error {
+	switch {
+	case !offline:
+		return fmt.Errorf("%w: --snapshot needs --offline", ErrInvalidOfflineFlags)
+	case useGraphQL:
+		return fmt.Errorf("%w: --graphql cannot be used with --offline", ErrInvalidOfflineFlags)
+	case len(args) > 0 && !slices.Contains(offlineLanguages, args[0]):
+		return fmt.Errorf("%w: --offline supports %s", ErrInvalidOfflineFlags, strings.Join(offlineLanguages, " and "))
+	case snapshotPath != "":
+		cache, err := utils.LoadSnapshot(snapshotPath)
+		if err != nil {
+			return fmt.Errorf("--snapshot: %w", err)
+		}
+
+		responseCache = cache
+
+		return nil
+	case noCache:
+		return fmt.Errorf("%w: --offline reads the cache, give --snapshot to use --no-cache", ErrInvalidOfflineFlags)
+	}
+
+	dir, err := utils.DefaultCacheDir()
+	if err != nil {
+		return fmt.Errorf("offline cache: %w", err)
+	}
+
+	utils.DebugPrintln("Offline, reading only the cache directory: " + dir)
+	responseCache = utils.NewOfflineCache(dir)
+
+	return nil
+}
+
 func isSupportedLanguage(language string) bool {
 	return slices.Contains(supportedLanguages, language)
 }
@@ -125,7 +295,7 @@ func run(language, inFile, format, token, config string, _ bool, deps Deps) erro
 
 	if token == "" {
 		token = os.Getenv("GITHUB_TOKEN")
-		if token == "" {
+		if token == "" && !responseCache.Offline() && !gitHubHosts.HasTokens() {
 			//nolint:lll // error message kept in one line for clarity when printed
 			fmt.Fprintln(os.Stderr, "Please provide a GitHub token using the --github-token flag or set the GITHUB_TOKEN environment variable")
 
@@ -177,26 +347,70 @@ func run(language, inFile, format, token, config string, _ bool, deps Deps) erro
 		}
 	}
 
-	utils.StdErrorPrintln("Analyzing libraries with Github...")
+	utils.StdErrorPrintln("Analyzing libraries on their forges...")
 
-	var gitHubRepoInfos []analyzer.GitHubRepoInfo
+	// Ctrl-C stops fetching; what was fetched so far is still displayed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var repoInfos []analyzer.RepoInfo
 	if len(repoURLs) > 0 {
-		gitHubRepoInfos = analyzerSvc.FetchGithubInfo(repoURLs)
+		repoInfos = analyzerSvc.FetchRepoInfo(ctx, repoURLs)
 	} else {
-		gitHubRepoInfos = []analyzer.GitHubRepoInfo{}
+		repoInfos = []analyzer.RepoInfo{}
 	}
 
 	utils.StdErrorPrintln("Making dataset...")
 
-	analyzedLibInfos := presenter.MakeAnalyzedLibInfoList(libInfoList, gitHubRepoInfos)
+	analyzedLibInfos := presenter.MakeAnalyzedLibInfoList(libInfoList, repoInfos)
+	for _, info := range analyzedLibInfos {
+		if info.RepoInfo != nil {
+			analyzer.AddDependencyKindScore(info.RepoInfo, info.LibInfo.Indirect, &weights)
+		}
+	}
+
+	if advisoryDB != nil {
+		utils.StdErrorPrintln("Matching advisories...")
+		matchAdvisories(advisoryDB, analyzedLibInfos, advisoryEcosystems[language], &weights)
+	}
+
+	if incomplete := countIncomplete(analyzedLibInfos); incomplete > 0 {
+		utils.StdErrorPrintln("%d repositories are missing metrics GitHub did not provide in full; "+
+			"they are left unscored, see the Incomplete column", incomplete)
+	}
+
 	presenterInst := deps.SelectPresenter(format, analyzedLibInfos)
 
 	utils.StdErrorPrintln("Displaying result...\n")
 	presenterInst.Display()
 
+	if ctx.Err() != nil {
+		return fmt.Errorf("analyze libraries: %w", ctx.Err())
+	}
+
 	return nil
 }
 
+// readsWorkspace tells that the dependencies come from a go.work, whose parser records the modules using them.
+func readsWorkspace(analyzedLibInfos []presenter.AnalyzedLibInfo) bool {
+	return slices.ContainsFunc(analyzedLibInfos, func(info presenter.AnalyzedLibInfo) bool {
+		return len(info.LibInfo.UsedBy) > 0
+	})
+}
+
+// countIncomplete counts the results missing metrics that a later run may fill.
+func countIncomplete(analyzedLibInfos []presenter.AnalyzedLibInfo) int {
+	count := 0
+
+	for _, info := range analyzedLibInfos {
+		if info.RepoInfo != nil && len(info.RepoInfo.Incomplete) > 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
 // GetRootCmd returns the root command for testing purposes.
 func GetRootCmd() *cobra.Command {
 	return rootCmd
@@ -210,10 +424,44 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&filePath, "input", "i", "", "Specify the file to read")
+	rootCmd.PersistentFlags().StringVarP(&filePath, "input", "i", "", "Specify the file to read")
 	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "markdown", "Specify the output format (csv, tsv, markdown)")
-	rootCmd.Flags().StringVarP(&githubToken, "github-token", "g", "", "GitHub token for authentication")
+	rootCmd.PersistentFlags().StringVarP(&githubToken, "github-token", "g", "", "GitHub token for authentication")
+	rootCmd.PersistentFlags().StringVar(&gitlabToken, "gitlab-token", "",
+		"GitLab token for gitlab.com projects (or GITLAB_TOKEN); public projects are read without one")
+	rootCmd.PersistentFlags().StringVar(&bitbucketToken, "bitbucket-token", "",
+		"Bitbucket Cloud access token (or BITBUCKET_TOKEN); public repositories are read without one")
+	rootCmd.PersistentFlags().StringVar(&codebergToken, "codeberg-token", "",
+		"Codeberg token (or CODEBERG_TOKEN); public repositories are read without one")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	rootCmd.Flags().BoolVarP(&utils.Verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.Flags().StringVarP(&configFilePath, "config", "c", "", "Modify evaluate parameters")
+	rootCmd.PersistentFlags().BoolVarP(&utils.Verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVarP(&configFilePath, "config", "c", "", "Modify evaluate parameters")
+	rootCmd.PersistentFlags().BoolVar(&includeIndirect, "include-indirect", false,
+		"Also analyze indirect (transitive) dependencies, e.g. every gem in Gemfile.lock or // indirect in go.mod")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", defaultConcurrency,
+		"Number of repositories fetched at the same time")
+	rootCmd.Flags().BoolVar(&useGraphQL, "graphql", false,
+		"Fetch repositories in batches of up to 50 with the GitHub GraphQL API instead of several REST calls each; "+
+			"their activity takes up to 11 more queries per batch, and 11 more with --lookback-months")
+	rootCmd.Flags().BoolVar(&showSparkline, "sparkline", false,
+		"Add a column drawing the weekly commits of the last year (markdown and tsv)")
+	rootCmd.PersistentFlags().DurationVar(&retryBudget, "retry-budget", analyzer.DefaultRetryBudget,
+		"Longest total wait per request for rate limits and temporary errors before skipping the repository")
+	rootCmd.PersistentFlags().IntVar(&lookbackMonths, "lookback-months", 0,
+		"Months of GitHub issues and pull requests read for the responsiveness metrics, "+
+			"up to 20 more requests per repository; 0 skips them")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false,
+		"Do not read or write the response cache in $XDG_CACHE_HOME/stay_or_go")
+	rootCmd.PersistentFlags().StringVar(&cacheTTL, "cache-ttl", "",
+		"How long cached responses are used before revalidating, e.g. 12h or github=6h,goproxy=720h,rubygems=48h")
+	rootCmd.PersistentFlags().StringArrayVar(&githubHostSpecs, "github-host", nil,
+		"GitHub Enterprise host analyzed like github.com, as host or host=API URL (default API https://host/api/v3)")
+	rootCmd.PersistentFlags().StringVar(&hostTokens, "host-tokens", "",
+		"Tokens per GitHub host as pattern=token pairs, e.g. *.example.com=TOKEN (or STAY_OR_GO_HOST_TOKENS)")
+	rootCmd.Flags().BoolVar(&offline, "offline", false,
+		"Make no network calls, answer everything from the cache or --snapshot (go and ruby)")
+	rootCmd.Flags().StringVar(&snapshotPath, "snapshot", "",
+		"Snapshot file written by export-snapshot, used with --offline")
+	rootCmd.Flags().StringVar(&advisoryPath, "advisories", "",
+		"OSV advisory database, a directory or zip export, matched against the pinned versions (go and ruby)")
 }
@@ -91,10 +91,24 @@ replace (
 # git specified to be skipped
 
 gem 'nokogiri', git: 'https://example.com/sparklemotion/nokogiri.git'
+`
+	gemfileLock := `PATH
+  remote: .
+  specs:
+    mygem (0.1.0)
+      localdep
+
+PATH
+  remote: ../localdep
+  specs:
+    localdep (0.2.0)
+
+DEPENDENCIES
+  mygem!
 `
 	handlers := map[string]func(){
 		"NOARGS":      func() { cmd.GetRootCmd().SetArgs([]string{}) },
-		"UNSUPPORTED": func() { cmd.GetRootCmd().SetArgs([]string{"python"}) },
+		"UNSUPPORTED": func() { cmd.GetRootCmd().SetArgs([]string{"cobol"}) },
 		"BADFORMAT":   func() { cmd.GetRootCmd().SetArgs([]string{"go", "-f", "json", "-g", "dummy"}) },
 		"NOTOKEN":     func() { _ = os.Unsetenv("GITHUB_TOKEN"); cmd.GetRootCmd().SetArgs([]string{"go"}) },
 		"GO_DEFAULT": func() {
@@ -111,6 +125,13 @@ gem 'nokogiri', git: 'https://example.com/sparklemotion/nokogiri.git'
 			t.Setenv("GITHUB_TOKEN", "dummy")
 			cmd.GetRootCmd().SetArgs([]string{"ruby"})
 		},
+		"RUBY_LOCK_INDIRECT": func() {
+			dir := t.TempDir()
+			_ = os.WriteFile(dir+"/Gemfile.lock", []byte(gemfileLock), 0o600)
+			t.Chdir(dir)
+			t.Setenv("GITHUB_TOKEN", "dummy")
+			cmd.GetRootCmd().SetArgs([]string{"ruby", "-i", "Gemfile.lock", "--include-indirect", "-f", "tsv"})
+		},
 		"GO_VERBOSE": func() {
 			dir := t.TempDir()
 			_ = os.WriteFile(dir+"/go.mod", []byte(goMod), 0o600)
@@ -168,6 +189,7 @@ func TestRootCommand_DefaultInputsAndVerbose(t *testing.T) {
 	}{
 		{name: "go default input", scenario: "GO_DEFAULT", expectErr: false},
 		{name: "ruby default input", scenario: "RUBY_DEFAULT", expectErr: false},
+		{name: "ruby lockfile with indirect gems", scenario: "RUBY_LOCK_INDIRECT", expectErr: false},
 		{name: "go verbose logs", scenario: "GO_VERBOSE", expectErr: false, expectStderrContains: "Selected Language: go"},
 		{name: "go with csv format", scenario: "GO_CSV", expectErr: false},
 		{name: "go with config file", scenario: "GO_CONFIG", expectErr: false},
@@ -2,6 +2,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -14,10 +15,10 @@ import (
 // Stubs
 type stubAnalyzer struct{ called bool }
 
-func (s *stubAnalyzer) FetchGithubInfo(_ []string) []analyzer.GitHubRepoInfo {
+func (s *stubAnalyzer) FetchRepoInfo(_ context.Context, _ []string) []analyzer.RepoInfo {
 	s.called = true
 
-	return []analyzer.GitHubRepoInfo{{GithubRepoURL: "https://github.com/u/a"}}
+	return []analyzer.RepoInfo{{RepositoryURL: "https://github.com/u/a"}}
 }
 
 type recorderParser struct {
@@ -44,7 +45,7 @@ func TestRun_Success_Go_DefaultFile(t *testing.T) {
 	recPresenter := &recorderPresenter{}
 
 	deps := Deps{
-		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) AnalyzerPort { return stubAnal },
+		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer { return stubAnal },
 		SelectParser:    func(_ string) (parser.Parser, error) { return recParser, nil },
 		SelectPresenter: func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort { return recPresenter },
 	}
@@ -78,7 +79,7 @@ func TestRun_Success_Ruby_DefaultFile(t *testing.T) {
 	recPresenter := &recorderPresenter{}
 
 	deps := Deps{
-		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) AnalyzerPort { return stubAnal },
+		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer { return stubAnal },
 		SelectParser:    func(_ string) (parser.Parser, error) { return recParser, nil },
 		SelectPresenter: func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort { return recPresenter },
 	}
@@ -102,14 +103,16 @@ func TestRun_SkipAll_DoesNotCallAnalyzer(t *testing.T) {
 	recPresenter := &recorderPresenter{}
 
 	deps := Deps{
-		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) AnalyzerPort { return &stubAnalyzer{called: true} },
+		NewAnalyzer: func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer {
+			return &stubAnalyzer{called: true}
+		},
 		SelectParser:    func(_ string) (parser.Parser, error) { return recParser, nil },
 		SelectPresenter: func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort { return recPresenter },
 	}
 
-	// Wrap NewAnalyzer to detect if it's used later via FetchGithubInfo
-	deps.NewAnalyzer = func(_ string, _ analyzer.ParameterWeights) AnalyzerPort {
-		return AnalyzerPort(rtFuncAnalyzer(func(_ []string) []analyzer.GitHubRepoInfo {
+	// Wrap NewAnalyzer to detect if it's used later via FetchRepoInfo
+	deps.NewAnalyzer = func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer {
+		return analyzer.RepoAnalyzer(rtFuncAnalyzer(func(_ []string) []analyzer.RepoInfo {
 			called = true
 
 			return nil
@@ -133,16 +136,18 @@ func TestRun_SkipAll_DoesNotCallAnalyzer(t *testing.T) {
 }
 
 // Analyzer adapter via function for testing
-type rtFuncAnalyzer func([]string) []analyzer.GitHubRepoInfo
+type rtFuncAnalyzer func([]string) []analyzer.RepoInfo
 
-func (f rtFuncAnalyzer) FetchGithubInfo(urls []string) []analyzer.GitHubRepoInfo { return f(urls) }
+func (f rtFuncAnalyzer) FetchRepoInfo(_ context.Context, urls []string) []analyzer.RepoInfo {
+	return f(urls)
+}
 
 func TestRun_UnsupportedAndFormatAndTokenErrors(t *testing.T) {
 	t.Parallel()
 
 	deps := Deps{}
 
-	err := run("python", "", "markdown", "tok", "", false, deps)
+	err := run("cobol", "", "markdown", "tok", "", false, deps)
 	if err == nil {
 		t.Fatalf("expected unsupported language error")
 	}
@@ -174,7 +179,7 @@ func TestRun_WithConfigFileBranch(t *testing.T) {
 	recPresenter := &recorderPresenter{}
 
 	deps := Deps{
-		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) AnalyzerPort { return &stubAnalyzer{} },
+		NewAnalyzer:     func(_ string, _ analyzer.ParameterWeights) analyzer.RepoAnalyzer { return &stubAnalyzer{} },
 		SelectParser:    func(_ string) (parser.Parser, error) { return recParser, nil },
 		SelectPresenter: func(_ string, _ []presenter.AnalyzedLibInfo) PresenterPort { return recPresenter },
 	}
@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	lockSectionGit          = "GIT"
+	lockSectionPath         = "PATH"
+	lockSectionGem          = "GEM"
+	lockSectionPlatforms    = "PLATFORMS"
+	lockSectionDependencies = "DEPENDENCIES"
+)
+
+// GemfileLockParser reads the gems Bundler actually resolved from Gemfile.lock.
+// Only the gems listed in DEPENDENCIES are returned unless IncludeIndirect is set.
+type GemfileLockParser struct {
+	IncludeIndirect bool
+	Concurrency     int
+	Cache           *utils.Cache
+	GitHubHosts     *utils.GitHubHosts
+}
+
+// lockedGem is a gem from the specs of a GIT, PATH or GEM section.
+type lockedGem struct {
+	name    string
+	version string
+	section string
+	remote  string
+}
+
+var (
+	// "    rails (7.1.0)" (four spaces) is a spec, "      rack (~> 2.0)" (six spaces) is its dependency
+	lockSpecRegex       = regexp.MustCompile(`^ {4}([^ (]+) \(([^)]+)\)$`)
+	lockDependencyRegex = regexp.MustCompile(`^ {2}([^ (!]+)!?(?: \(.*\))?$`)
+)
+
+func isGemfileLock(filePath string) bool {
+	base := filepath.Base(filePath)
+
+	return strings.HasSuffix(base, ".lock") || strings.HasSuffix(base, ".locked")
+}
+
+func (p GemfileLockParser) Parse(filePath string) ([]LibInfo, error) {
+	lines, err := p.readLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	gems, platforms, directGems := p.parseSections(lines)
+
+	var libs []LibInfo
+
+	seen := map[string]bool{}
+
+	for _, gem := range gems {
+		if seen[gem.name] {
+			// Platform-specific variants of the same gem (e.g. nokogiri for several platforms)
+			continue
+		}
+
+		seen[gem.name] = true
+
+		indirect := !slices.Contains(directGems, gem.name)
+		if indirect && !p.IncludeIndirect {
+			continue
+		}
+
+		libs = append(libs, p.createLibInfo(gem, stripPlatform(gem.version, platforms), indirect))
+	}
+
+	return libs, nil
+}
+
+func (p GemfileLockParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
+	rubyParser := RubyParser{
+		IncludeIndirect: p.IncludeIndirect, Concurrency: p.Concurrency,
+		Cache: p.Cache, GitHubHosts: p.GitHubHosts,
+	}
+
+	return rubyParser.GetRepositoryURL(libInfoList)
+}
+
+// readLines keeps the indentation, which tells specs apart from their dependencies.
+func (p GemfileLockParser) readLines(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+	defer file.Close()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), " \r"))
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return lines, nil
+}
+
+// parseSections returns the gems of the GIT, PATH and GEM sections in file order,
+// the PLATFORMS entries and the gem names listed under DEPENDENCIES.
+func (p GemfileLockParser) parseSections(lines []string) ([]lockedGem, []string, []string) {
+	var (
+		gems       []lockedGem
+		platforms  []string
+		directGems []string
+		section    string
+		remote     string
+	)
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			section = strings.TrimSpace(line)
+			remote = ""
+
+			continue
+		}
+
+		switch section {
+		case lockSectionGit, lockSectionPath, lockSectionGem:
+			if value, found := strings.CutPrefix(line, "  remote: "); found {
+				remote = strings.TrimSpace(value)
+
+				continue
+			}
+
+			if matches := lockSpecRegex.FindStringSubmatch(line); matches != nil {
+				gems = append(gems, lockedGem{name: matches[1], version: matches[2], section: section, remote: remote})
+			}
+		case lockSectionPlatforms:
+			platforms = append(platforms, strings.TrimSpace(line))
+		case lockSectionDependencies:
+			if matches := lockDependencyRegex.FindStringSubmatch(line); matches != nil {
+				directGems = append(directGems, matches[1])
+			}
+		}
+	}
+
+	return gems, platforms, directGems
+}
+
+func (p GemfileLockParser) createLibInfo(gem lockedGem, version string, indirect bool) LibInfo {
+	switch gem.section {
+	case lockSectionPath:
+		return NewLibInfo(gem.name, WithSkip(true), WithSkipReason("Local path gem"), WithIndirect(indirect))
+	case lockSectionGit:
+		repoURL, err := hostedRepository(p.GitHubHosts, gem.remote)
+		if err != nil {
+			return NewLibInfo(gem.name, WithSkip(true), WithSkipReason(unresolvedReason(err)), WithIndirect(indirect))
+		}
+
+		lib := NewLibInfo(gem.name, WithOthers([]string{gem.name, version}), WithIndirect(indirect))
+		lib.RepositoryURL = repoURL
+
+		return lib
+	default:
+		return NewLibInfo(gem.name, WithOthers([]string{gem.name, version}), WithIndirect(indirect))
+	}
+}
+
+// stripPlatform turns "1.15.4-x86_64-linux" into "1.15.4" when the suffix is a locked platform.
+func stripPlatform(version string, platforms []string) string {
+	for _, platform := range platforms {
+		if trimmed, found := strings.CutSuffix(version, "-"+platform); found {
+			return trimmed
+		}
+	}
+
+	return version
+}
@@ -0,0 +1,125 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+const gemfileLock = `GIT
+  remote: https://github.com/rails/rails.git
+  revision: 0123456789abcdef
+  branch: main
+  specs:
+    rails (7.2.0.alpha)
+      actionpack (= 7.2.0.alpha)
+
+GIT
+  remote: https://example.com/private/internal.git
+  revision: fedcba9876543210
+  specs:
+    internal (1.0.0)
+
+PATH
+  remote: .
+  specs:
+    mygem (0.1.0)
+      rack
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    actionpack (7.2.0.alpha)
+      rack (~> 3.0)
+    nokogiri (1.16.0)
+      racc (~> 1.4)
+    nokogiri (1.16.0-x86_64-linux)
+      racc (~> 1.4)
+    rack (3.0.9)
+    racc (1.7.3)
+
+PLATFORMS
+  ruby
+  x86_64-linux
+
+DEPENDENCIES
+  internal!
+  mygem!
+  nokogiri (~> 1.16)
+  rails!
+
+BUNDLED WITH
+   2.5.3
+`
+
+func writeGemfileLock(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "Gemfile.lock")
+	require.NoError(t, os.WriteFile(path, []byte(gemfileLock), 0o600))
+
+	return path
+}
+
+func TestGemfileLockParser_Parse_DirectOnly(t *testing.T) {
+	t.Parallel()
+
+	libs, err := parser.GemfileLockParser{}.Parse(writeGemfileLock(t))
+	require.NoError(t, err)
+	require.Len(t, libs, 4)
+
+	assert.Equal(t, "rails", libs[0].Name)
+	assert.Equal(t, []string{"rails", "7.2.0.alpha"}, libs[0].Others)
+	assert.Equal(t, "https://github.com/rails/rails", libs[0].RepositoryURL)
+	assert.False(t, libs[0].Indirect)
+
+	assert.Equal(t, "internal", libs[1].Name)
+	assert.True(t, libs[1].Skip)
+	assert.Equal(t, "Unsupported repository host: example.com", libs[1].SkipReason)
+
+	assert.Equal(t, "mygem", libs[2].Name)
+	assert.True(t, libs[2].Skip)
+	assert.Equal(t, "Local path gem", libs[2].SkipReason)
+
+	assert.Equal(t, "nokogiri", libs[3].Name)
+	assert.Equal(t, []string{"nokogiri", "1.16.0"}, libs[3].Others)
+	assert.False(t, libs[3].Indirect)
+}
+
+func TestGemfileLockParser_Parse_IncludeIndirect(t *testing.T) {
+	t.Parallel()
+
+	libs, err := parser.GemfileLockParser{IncludeIndirect: true}.Parse(writeGemfileLock(t))
+	require.NoError(t, err)
+
+	indirect := map[string]bool{}
+	for _, lib := range libs {
+		indirect[lib.Name] = lib.Indirect
+	}
+
+	assert.Equal(t, map[string]bool{
+		"rails":      false,
+		"internal":   false,
+		"mygem":      false,
+		"actionpack": true,
+		"nokogiri":   false,
+		"rack":       true,
+		"racc":       true,
+	}, indirect)
+}
+
+func TestRubyParser_Parse_DelegatesToGemfileLock(t *testing.T) {
+	t.Parallel()
+
+	rubyParser, err := parser.SelectParser("ruby", parser.WithIncludeIndirect(true))
+	require.NoError(t, err)
+
+	libs, err := rubyParser.Parse(writeGemfileLock(t))
+	require.NoError(t, err)
+	assert.Len(t, libs, 7)
+}
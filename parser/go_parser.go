@@ -1,155 +1,258 @@
 package parser
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
 	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
-type GoParser struct{}
+// GoParser reads the requirements of go.mod.
+// Requirements marked "// indirect" are only returned when IncludeIndirect is set.
+type GoParser struct {
+	IncludeIndirect bool
+	Concurrency     int
+	Cache           *utils.Cache
+	GitHubHosts     *utils.GitHubHosts
+}
 
 func (p GoParser) Parse(filePath string) ([]LibInfo, error) {
-	file, err := os.Open(filePath)
+	if filepath.Base(filePath) == goWorkFileName {
+		return p.workParser().Parse(filePath)
+	}
+
+	modFile, err := p.readModFile(filePath)
 	if err != nil {
-		utils.StdErrorPrintln("%v: %v", ErrFailedToReadFile, err)
-		os.Exit(1)
+		return nil, err
 	}
-	defer file.Close()
 
-	replaceModules := p.collectReplaceModules(file)
-	libInfoList := p.processRequireBlock(file, replaceModules)
+	return p.processRequires(modFile, nil), nil
+}
 
-	return libInfoList, nil
+func (p GoParser) workParser() GoWorkParser {
+	return GoWorkParser{
+		IncludeIndirect: p.IncludeIndirect, Concurrency: p.Concurrency,
+		Cache: p.Cache, GitHubHosts: p.GitHubHosts,
+	}
 }
 
 func (p GoParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
-	client := &http.Client{}
+	client := p.Cache.Client(utils.CacheSourceGoImport)
+	proxyClient := p.Cache.Client(utils.CacheSourceGoProxy)
+	env := loadGoModuleEnv()
 
-	for i := range libInfoList {
+	_ = utils.ForEachIndex(context.Background(), p.Concurrency, len(libInfoList), func(i int) {
 		libInfo := &libInfoList[i]
 
 		if libInfo.Skip {
-			continue
+			return
 		}
 
 		name := libInfo.Others[0]
 		version := libInfo.Others[1]
 
-		repoURL, err := p.getGitHubRepositoryURL(client, name, version)
+		repoURL, err := p.resolveRepositoryURL(client, proxyClient, env, name, version)
 		if err != nil {
 			libInfo.Skip = true
-			libInfo.SkipReason = "Does not support libraries hosted outside of Github"
 
-			utils.StdErrorPrintln("%s does not support libraries hosted outside of Github: %s", name, err)
+			switch {
+			case errors.Is(err, ErrPrivateModule):
+				libInfo.SkipReason = "Private module not resolvable from its VCS host"
+			case errors.Is(err, ErrModuleLookupDisabled):
+				libInfo.SkipReason = "Module lookup disabled by GOPROXY=off"
+			default:
+				libInfo.SkipReason = unresolvedReason(err)
+			}
+
+			utils.StdErrorPrintln("%s: repository not resolved: %s", name, err)
 
-			continue
+			return
 		}
 
 		libInfo.RepositoryURL = repoURL
-	}
+	})
 
 	return libInfoList
 }
 
-func (p GoParser) collectReplaceModules(file *os.File) []string {
-	var replaceModules []string
+// resolveRepositoryURL tries the built-in mapping of well-known vanity hosts first.
+// Modules matched by GONOPROXY/GOPRIVATE are resolved directly; the others walk the
+// GOPROXY list, where "direct" reads the go-import meta tags served by the import path.
+// The module proxies are asked through proxyClient and the import paths through client.
+func (p GoParser) resolveRepositoryURL(
+	client *http.Client,
+	proxyClient *http.Client,
+	env goModuleEnv,
+	name,
+	version string,
+) (string, error) {
+	if repoURL, ok := knownGitHubRepository(name); ok {
+		return repoURL, nil
+	}
 
-	var inReplaceBlock bool
+	if env.bypassesProxy(name) {
+		utils.DebugPrintln(name + " matches GONOPROXY/GOPRIVATE, resolving it directly")
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		repoURL, err := p.resolveDirect(client, name)
+		if err != nil && env.isPrivate(name) {
+			return "", fmt.Errorf("%w: %w", ErrPrivateModule, err)
+		}
+
+		return repoURL, err
+	}
 
-		if line == "replace (" {
-			inReplaceBlock = true
+	err := ErrNotAGitHubRepository
 
-			continue
+	for _, proxy := range env.proxies {
+		var repoURL string
+
+		switch proxy.url {
+		case goProxyOff:
+			return "", ErrModuleLookupDisabled
+		case goProxyDirect:
+			repoURL, err = p.resolveDirect(client, name)
+		default:
+			repoURL, err = p.getGitHubRepositoryURL(proxyClient, proxy.url, name, version)
 		}
 
-		if line == ")" && inReplaceBlock {
-			inReplaceBlock = false
+		if err == nil {
+			return repoURL, nil
+		}
 
-			continue
+		// Like the go command, a comma only falls back when the module was not found.
+		// Offline, a proxy answer missing from the snapshot may be made up for by the next entry.
+		notFound := errors.Is(err, ErrNotAGitHubRepository) || errors.Is(err, utils.ErrNotInOfflineSnapshot)
+		if !notFound && !proxy.fallbackOnError {
+			return "", err
 		}
 
-		if inReplaceBlock {
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				replaceModules = append(replaceModules, parts[0])
-			}
+		utils.DebugPrintln(name + " is not resolved by " + proxy.url + ": " + err.Error())
+	}
+
+	return "", err
+}
+
+// resolveDirect finds the repository without a module proxy: paths on github.com or a
+// GitHub Enterprise host are used as they are, other import paths are asked for their
+// go-import meta tags.
+func (p GoParser) resolveDirect(client *http.Client, name string) (string, error) {
+	if host, ok := p.GitHubHosts.Lookup("https://" + name); ok && strings.HasPrefix(name, host.Name+"/") {
+		if root, found := repositoryRoot("https://"+name, host.Name); found {
+			return root, nil
 		}
+
+		return "", ErrNotAGitHubRepository
 	}
 
-	_, err := file.Seek(0, 0) // Reset file pointer for next pass
+	return p.resolveVanityImport(client, name)
+}
+
+func (p GoParser) readModFile(filePath string) (*modfile.File, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	modFile, err := modfile.Parse(filePath, data, nil)
 	if err != nil {
-		utils.StdErrorPrintln("%v: %v", ErrFailedToResetFilePointer, err)
-		os.Exit(1)
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
 	}
 
-	return replaceModules
+	return modFile, nil
 }
 
-func (p GoParser) processRequireBlock(file *os.File, replaceModules []string) []LibInfo {
-	var libInfoList []LibInfo
+// processRequires turns every require directive, single-line or block, into a LibInfo.
+// Indirect requirements are left out unless IncludeIndirect is set, and a requirement on an excluded
+// version is skipped. A module replaced by another module is reported
+// together with its replacement, which directly follows it.
+// Replacements in workReplaces (from go.work) win over those of the module itself.
+func (p GoParser) processRequires(modFile *modfile.File, workReplaces []*modfile.Replace) []LibInfo {
+	libInfoList := make([]LibInfo, 0, len(modFile.Require))
 
-	var inRequireBlock bool
+	for _, require := range modFile.Require {
+		if require.Indirect && !p.IncludeIndirect {
+			continue
+		}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		mod := require.Mod
+		libName := lastPathElement(mod.Path)
 
-		if line == "require (" {
-			inRequireBlock = true
+		// Go builds with the next higher version instead, which go.mod does not name
+		if isExcluded(modFile, mod) {
+			libInfoList = append(libInfoList, NewLibInfo(libName,
+				WithSkip(true), WithSkipReason("Excluded version ("+mod.Version+")"),
+				WithOthers([]string{mod.Path, mod.Version}), WithIndirect(require.Indirect)))
 
 			continue
 		}
 
-		if line == ")" && inRequireBlock {
-			inRequireBlock = false
+		replace := findReplace(workReplaces, mod)
+		if replace == nil {
+			replace = findReplace(modFile.Replace, mod)
+		}
 
-			continue
+		switch {
+		case replace == nil:
+			libInfoList = append(libInfoList, NewLibInfo(libName,
+				WithOthers([]string{mod.Path, mod.Version}), WithIndirect(require.Indirect)))
+		case modfile.IsDirectoryPath(replace.New.Path):
+			libInfoList = append(libInfoList, NewLibInfo(libName,
+				WithSkip(true), WithSkipReason("Replaced by local path ("+replace.New.Path+")"),
+				WithOthers([]string{mod.Path, mod.Version}), WithIndirect(require.Indirect)))
+		default:
+			replacement := NewLibInfo(lastPathElement(replace.New.Path)+" (replaces "+libName+")",
+				WithOthers([]string{replace.New.Path, replace.New.Version}), WithIndirect(require.Indirect))
+
+			libInfoList = append(libInfoList, NewLibInfo(libName,
+				WithOthers([]string{mod.Path, mod.Version}), WithIndirect(require.Indirect)), replacement)
 		}
+	}
 
-		if inRequireBlock && !strings.Contains(line, "// indirect") {
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				module := parts[0]
-				libParts := strings.Split(parts[0], "/")
-				libName := libParts[len(libParts)-1]
+	return libInfoList
+}
 
-				var newLib LibInfo
+// findReplace returns the replace directive that applies to the module.
+// A replace with a version on its left-hand side only applies to that exact version,
+// and takes precedence over a replace for all versions of the module.
+func findReplace(replaces []*modfile.Replace, mod module.Version) *modfile.Replace {
+	var wildcard *modfile.Replace
 
-				if contains(replaceModules, module) {
-					newLib = NewLibInfo(libName, WithSkip(true), WithSkipReason("replaced module"))
-				} else {
-					newLib = NewLibInfo(libName, WithOthers([]string{parts[0], parts[1]}))
-				}
+	for _, replace := range replaces {
+		if replace.Old.Path != mod.Path {
+			continue
+		}
 
-				libInfoList = append(libInfoList, newLib)
-			}
+		if replace.Old.Version == mod.Version {
+			return replace
 		}
-	}
 
-	err := scanner.Err()
-	if err != nil {
-		utils.StdErrorPrintln("%v: %v", ErrFailedToScanFile, err)
-		os.Exit(1)
+		if replace.Old.Version == "" {
+			wildcard = replace
+		}
 	}
 
-	return libInfoList
+	return wildcard
+}
+
+func lastPathElement(modulePath string) string {
+	return modulePath[strings.LastIndex(modulePath, "/")+1:]
 }
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
+func isExcluded(modFile *modfile.File, mod module.Version) bool {
+	for _, exclude := range modFile.Exclude {
+		if exclude.Mod == mod {
 			return true
 		}
 	}
@@ -172,14 +275,24 @@ type Origin struct {
 
 func (p GoParser) getGitHubRepositoryURL(
 	client *http.Client,
+	proxyURL,
 	name,
 	version string,
 ) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
 	defer cancel()
 
-	baseURL := "https://proxy.golang.org/"
-	repoURL := baseURL + name + "/@v/" + version + ".info"
+	escapedPath, err := module.EscapePath(name)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	repoURL := proxyURL + "/" + escapedPath + "/@v/" + escapedVersion + ".info"
 	utils.DebugPrintln("Fetching: " + repoURL)
 
 	parsedURL, err := url.Parse(repoURL)
@@ -194,13 +307,17 @@ func (p GoParser) getGitHubRepositoryURL(
 
 	response, err := client.Do(req)
 	if err != nil {
-		return "", ErrFailedToGetRepository
+		return "", fmt.Errorf("%w: %w", ErrFailedToGetRepository, err)
 	}
 
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
 		return "", ErrNotAGitHubRepository
+	default:
+		return "", fmt.Errorf("%w: %s returned %d", ErrFailedToGetRepository, proxyURL, response.StatusCode)
 	}
 
 	bodyBytes, err := io.ReadAll(response.Body)
@@ -208,7 +325,7 @@ func (p GoParser) getGitHubRepositoryURL(
 		return "", ErrFailedToReadResponseBody
 	}
 
-	repoURLfromGithub, err := extractRepoURL(bodyBytes, name)
+	repoURLfromGithub, err := extractRepoURL(bodyBytes, name, p.GitHubHosts)
 	if err != nil {
 		return "", err
 	}
@@ -216,7 +333,7 @@ func (p GoParser) getGitHubRepositoryURL(
 	return repoURLfromGithub, nil
 }
 
-func extractRepoURL(bodyBytes []byte, name string) (string, error) {
+func extractRepoURL(bodyBytes []byte, name string, hosts *utils.GitHubHosts) (string, error) {
 	var repo GoRepository
 
 	err := json.Unmarshal(bodyBytes, &repo)
@@ -227,13 +344,13 @@ func extractRepoURL(bodyBytes []byte, name string) (string, error) {
 	repoURLfromGithub := repo.Origin.URL
 
 	// If there is no URL, use the package name
-	if repoURLfromGithub == "" && strings.Contains(name, "github.com") {
+	if _, ok := hosts.Lookup(name); repoURLfromGithub == "" && ok {
 		repoURLfromGithub = "https://" + name
 	}
 
-	if repoURLfromGithub == "" || !strings.Contains(repoURLfromGithub, "github.com") {
+	if repoURLfromGithub == "" {
 		return "", ErrNotAGitHubRepository
 	}
 
-	return repoURLfromGithub, nil
+	return hostedRepository(hosts, repoURLfromGithub)
 }
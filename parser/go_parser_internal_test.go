@@ -9,7 +9,7 @@ func TestExtractRepoURL_Errors(t *testing.T) {
 	t.Parallel()
 
 	// invalid JSON
-	_, err := extractRepoURL([]byte("not-json"), "github.com/user/lib")
+	_, err := extractRepoURL([]byte("not-json"), "github.com/user/lib", nil)
 	if err == nil || !errors.Is(err, ErrFailedToUnmarshalJSON) {
 		t.Fatalf("expected ErrFailedToUnmarshalJSON, got %v", err)
 	}
@@ -17,7 +17,7 @@ func TestExtractRepoURL_Errors(t *testing.T) {
 	// no github in name and empty origin.url
 	body := []byte(`{"origin":{"url":""}}`)
 
-	_, err = extractRepoURL(body, "code.gitea.io/sdk")
+	_, err = extractRepoURL(body, "code.gitea.io/sdk", nil)
 	if err == nil || !errors.Is(err, ErrNotAGitHubRepository) {
 		t.Fatalf("expected ErrNotAGitHubRepository, got %v", err)
 	}
@@ -25,7 +25,7 @@ func TestExtractRepoURL_Errors(t *testing.T) {
 	// non-github URL in origin
 	body2 := []byte(`{"origin":{"url":"https://example.com/foo"}}`)
 
-	_, err = extractRepoURL(body2, "example.com/foo")
+	_, err = extractRepoURL(body2, "example.com/foo", nil)
 	if err == nil || !errors.Is(err, ErrNotAGitHubRepository) {
 		t.Fatalf("expected ErrNotAGitHubRepository, got %v", err)
 	}
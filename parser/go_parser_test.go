@@ -2,10 +2,12 @@ package parser_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/uzumaki-inc/stay_or_go/parser"
 )
@@ -83,17 +85,183 @@ replace (
 	assert.Equal(t, []string{"code.gitea.io/sdk", "v1.0.0"}, sdk.Others)
 
 	assert.True(t, replaced.Skip)
-	assert.Equal(t, "replaced module", replaced.SkipReason)
+	assert.Equal(t, "Replaced by local path (./local/mod)", replaced.SkipReason)
 }
 
-//nolint:paralleltest,funlen // Uses httpmock which doesn't support parallel tests, complex setup
+func TestGoParser_Parse_FullGrammar(t *testing.T) {
+	t.Parallel()
+
+	content := `// demo module
+module example.com/demo
+
+go 1.22
+
+toolchain go1.22.5
+
+require github.com/user/single v1.0.0
+
+require (
+    // a comment inside the block
+    github.com/user/blockone v1.1.0 // trailing comment
+    github.com/user/indirect v0.1.0 // indirect
+)
+
+require (
+    github.com/user/blocktwo v2.0.0+incompatible
+    github.com/user/pinned v1.5.0
+    github.com/user/otherversion v1.0.0
+)
+
+replace github.com/user/pinned v1.5.0 => ../pinned
+
+replace github.com/user/otherversion v0.9.0 => ../otherversion
+
+exclude github.com/user/blockone v1.0.0
+
+retract [v0.1.0, v0.2.0] // broken releases
+`
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	libs, err := parser.GoParser{}.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, libs, 5)
+
+	assert.Equal(t, []string{"github.com/user/single", "v1.0.0"}, libs[0].Others)
+	assert.Equal(t, []string{"github.com/user/blockone", "v1.1.0"}, libs[1].Others)
+	assert.Equal(t, []string{"github.com/user/blocktwo", "v2.0.0+incompatible"}, libs[2].Others)
+
+	// version-specific replace that matches the required version
+	assert.Equal(t, "pinned", libs[3].Name)
+	assert.True(t, libs[3].Skip)
+
+	// version-specific replace for another version does not apply
+	assert.Equal(t, "otherversion", libs[4].Name)
+	assert.False(t, libs[4].Skip)
+	assert.Equal(t, []string{"github.com/user/otherversion", "v1.0.0"}, libs[4].Others)
+}
+
+func TestGoParser_Parse_ReplacedByFork(t *testing.T) {
+	t.Parallel()
+
+	content := `module example.com/demo
+
+require (
+    github.com/upstream/lib v1.2.0
+    github.com/upstream/other v0.3.0
+)
+
+replace github.com/upstream/lib => github.com/ourorg/lib-fork v1.2.1-fix
+
+replace github.com/upstream/other v0.3.0 => ../other
+`
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	libs, err := parser.GoParser{}.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, libs, 3)
+
+	// the original module is still analyzed, followed by the fork that replaces it
+	assert.Equal(t, "lib", libs[0].Name)
+	assert.False(t, libs[0].Skip)
+	assert.Equal(t, []string{"github.com/upstream/lib", "v1.2.0"}, libs[0].Others)
+
+	assert.Equal(t, "lib-fork (replaces lib)", libs[1].Name)
+	assert.False(t, libs[1].Skip)
+	assert.Equal(t, []string{"github.com/ourorg/lib-fork", "v1.2.1-fix"}, libs[1].Others)
+
+	assert.Equal(t, "other", libs[2].Name)
+	assert.True(t, libs[2].Skip)
+	assert.Equal(t, "Replaced by local path (../other)", libs[2].SkipReason)
+}
+
+func TestGoParser_Parse_ExcludedVersion(t *testing.T) {
+	t.Parallel()
+
+	content := `module example.com/demo
+
+require (
+    github.com/user/kept v1.0.0
+    github.com/user/broken v1.4.0
+)
+
+exclude (
+    github.com/user/kept v0.9.0
+    github.com/user/broken v1.4.0
+)
+`
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	libs, err := parser.GoParser{}.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, libs, 2)
+
+	// excluding another version leaves the requirement as it is
+	assert.Equal(t, "kept", libs[0].Name)
+	assert.False(t, libs[0].Skip)
+
+	assert.Equal(t, "broken", libs[1].Name)
+	assert.True(t, libs[1].Skip)
+	assert.Equal(t, "Excluded version (v1.4.0)", libs[1].SkipReason)
+	assert.Equal(t, []string{"github.com/user/broken", "v1.4.0"}, libs[1].Others)
+}
+
+func TestGoParser_Parse_IncludeIndirect(t *testing.T) {
+	t.Parallel()
+
+	content := `module example.com/demo
+
+require (
+    github.com/user/direct v1.0.0
+    github.com/user/transitive v0.2.0 // indirect
+)
+`
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	goParser, err := parser.SelectParser("go", parser.WithIncludeIndirect(true))
+	require.NoError(t, err)
+
+	libs, err := goParser.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, libs, 2)
+
+	assert.Equal(t, "direct", libs[0].Name)
+	assert.False(t, libs[0].Indirect)
+	assert.Equal(t, "transitive", libs[1].Name)
+	assert.True(t, libs[1].Indirect)
+	assert.Equal(t, []string{"github.com/user/transitive", "v0.2.0"}, libs[1].Others)
+}
+
+func TestGoParser_Parse_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.GoParser{}.Parse(filepath.Join(t.TempDir(), "go.mod"))
+	require.ErrorIs(t, err, parser.ErrFiledToOpenFile)
+
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte("module example.com/demo\nrequire (\n"), 0o600))
+
+	_, err = parser.GoParser{}.Parse(path)
+	require.ErrorIs(t, err, parser.ErrFailedToReadFile)
+}
+
+//nolint:paralleltest,funlen // Uses httpmock and t.Setenv, complex setup
 func TestGoParser_GetRepositoryURL_SetsURLAndSkips(t *testing.T) {
+	setGoModuleEnv(t, "", "")
+
 	// Prepare initial lib list as if parsed
 	libs := []parser.LibInfo{
 		parser.NewLibInfo("libone", parser.WithOthers([]string{"github.com/user/libone", "v1.2.3"})),
 		parser.NewLibInfo("libtwo", parser.WithOthers([]string{"github.com/user/libtwo", "v0.9.0"})),
 		parser.NewLibInfo("sdk", parser.WithOthers([]string{"code.gitea.io/sdk", "v1.0.0"})),
-		parser.NewLibInfo("mod", parser.WithSkip(true), parser.WithSkipReason("replaced module")),
+		parser.NewLibInfo("mod", parser.WithSkip(true), parser.WithSkipReason("Replaced by local path (./local/mod)")),
 	}
 
 	httpmock.Activate()
@@ -151,10 +319,10 @@ func TestGoParser_GetRepositoryURL_SetsURLAndSkips(t *testing.T) {
 	assert.Equal(t, "https://github.com/user/libtwo", libtwo.RepositoryURL)
 
 	assert.True(t, sdk.Skip)
-	assert.Equal(t, "Does not support libraries hosted outside of Github", sdk.SkipReason)
+	assert.Equal(t, "Repository lookup failed", sdk.SkipReason)
 	assert.Empty(t, sdk.RepositoryURL)
 
 	// replaced item should remain skipped and untouched
 	assert.True(t, replaced.Skip)
-	assert.Equal(t, "replaced module", replaced.SkipReason)
+	assert.Equal(t, "Replaced by local path (./local/mod)", replaced.SkipReason)
 }
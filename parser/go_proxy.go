@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	defaultGoProxy = "https://proxy.golang.org,direct"
+	goProxyDirect  = "direct"
+	goProxyOff     = "off"
+)
+
+var (
+	ErrPrivateModule        = errors.New("private module is not resolvable from its VCS host")
+	ErrModuleLookupDisabled = errors.New("module lookup disabled by GOPROXY=off")
+)
+
+// goProxyEntry is one element of GOPROXY. After a comma the next entry is only tried
+// when the module is not found; after a pipe it is tried on any error.
+type goProxyEntry struct {
+	url             string
+	fallbackOnError bool
+}
+
+// goModuleEnv holds the go command settings that decide where module information comes from.
+type goModuleEnv struct {
+	proxies   []goProxyEntry
+	noProxy   string
+	goPrivate string
+}
+
+func loadGoModuleEnv() goModuleEnv {
+	goProxy := os.Getenv("GOPROXY")
+	if goProxy == "" {
+		goProxy = defaultGoProxy
+	}
+
+	goPrivate := os.Getenv("GOPRIVATE")
+
+	noProxy := os.Getenv("GONOPROXY")
+	if noProxy == "" {
+		noProxy = goPrivate
+	}
+
+	return goModuleEnv{
+		proxies:   parseGoProxyList(goProxy),
+		noProxy:   noProxy,
+		goPrivate: goPrivate,
+	}
+}
+
+func parseGoProxyList(value string) []goProxyEntry {
+	var entries []goProxyEntry
+
+	for value != "" {
+		end := strings.IndexAny(value, ",|")
+
+		entry := goProxyEntry{url: value, fallbackOnError: false}
+		rest := ""
+
+		if end >= 0 {
+			entry.url = value[:end]
+			entry.fallbackOnError = value[end] == '|'
+			rest = value[end+1:]
+		}
+
+		entry.url = strings.TrimSpace(entry.url)
+		if entry.url != "" {
+			if entry.url != goProxyDirect && entry.url != goProxyOff {
+				entry.url = strings.TrimSuffix(entry.url, "/")
+			}
+
+			entries = append(entries, entry)
+		}
+
+		value = rest
+	}
+
+	return entries
+}
+
+// bypassesProxy reports whether the module must be fetched directly, as GONOPROXY
+// (which defaults to GOPRIVATE) requires.
+func (e goModuleEnv) bypassesProxy(modulePath string) bool {
+	return module.MatchPrefixPatterns(e.noProxy, modulePath)
+}
+
+func (e goModuleEnv) isPrivate(modulePath string) bool {
+	return module.MatchPrefixPatterns(e.goPrivate, modulePath)
+}
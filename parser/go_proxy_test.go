@@ -0,0 +1,202 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const goProxyInfo = `{"version":"v1.0.0","origin":{"vcs":"git","url":"https://github.com/user/lib"}}`
+
+func setGoModuleEnv(t *testing.T, goProxy, goPrivate string) {
+	t.Helper()
+
+	t.Setenv("GOPROXY", goProxy)
+	t.Setenv("GOPRIVATE", goPrivate)
+	t.Setenv("GONOPROXY", "")
+}
+
+func goLib() []parser.LibInfo {
+	return []parser.LibInfo{
+		parser.NewLibInfo("lib", parser.WithOthers([]string{"example.com/user/lib", "v1.0.0"})),
+	}
+}
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_GoProxyFallback(t *testing.T) {
+	testCases := []struct {
+		name          string
+		goProxy       string
+		athensStatus  int
+		expectedURL   string
+		expectedSkip  string
+		publicProxyOK bool
+	}{
+		{name: "comma falls back on not found", goProxy: "https://athens.corp,https://proxy.golang.org",
+			athensStatus: 404, expectedURL: "https://github.com/user/lib", publicProxyOK: true},
+		{name: "comma stops on server error", goProxy: "https://athens.corp/,https://proxy.golang.org",
+			athensStatus: 500, expectedSkip: "Repository lookup failed"},
+		{name: "pipe falls back on any error", goProxy: "https://athens.corp|https://proxy.golang.org",
+			athensStatus: 500, expectedURL: "https://github.com/user/lib", publicProxyOK: true},
+		{name: "off disables lookups", goProxy: "https://athens.corp,off",
+			athensStatus: 404, expectedSkip: "Module lookup disabled by GOPROXY=off"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setGoModuleEnv(t, testCase.goProxy, "")
+
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("GET", "https://athens.corp/example.com/user/lib/@v/v1.0.0.info",
+				httpmock.NewStringResponder(testCase.athensStatus, ""))
+			httpmock.RegisterResponder("GET", "https://proxy.golang.org/example.com/user/lib/@v/v1.0.0.info",
+				httpmock.NewStringResponder(200, goProxyInfo))
+
+			updated := parser.GoParser{}.GetRepositoryURL(goLib())
+
+			assert.Equal(t, testCase.expectedURL, updated[0].RepositoryURL)
+			assert.Equal(t, testCase.expectedSkip, updated[0].SkipReason)
+
+			calls := httpmock.GetCallCountInfo()["GET https://proxy.golang.org/example.com/user/lib/@v/v1.0.0.info"]
+			assert.Equal(t, testCase.publicProxyOK, calls == 1)
+		})
+	}
+}
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_PrivateModules(t *testing.T) {
+	setGoModuleEnv(t, "", "github.com/ourorg,*.corp.example.com")
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://git.corp.example.com/team/lib?go-get=1",
+		httpmock.NewStringResponder(401, "unauthorized"))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("service", parser.WithOthers([]string{"github.com/ourorg/service/v2", "v2.1.0"})),
+		parser.NewLibInfo("lib", parser.WithOthers([]string{"git.corp.example.com/team/lib", "v0.3.0"})),
+		parser.NewLibInfo("Upper", parser.WithOthers([]string{"github.com/BurntSushi/toml", "v1.4.0"})),
+	}
+
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/github.com/!burnt!sushi/toml/@v/v1.4.0.info",
+		httpmock.NewStringResponder(200,
+			`{"version":"v1.4.0","origin":{"vcs":"git","url":"https://github.com/BurntSushi/toml"}}`))
+
+	updated := parser.GoParser{}.GetRepositoryURL(libs)
+
+	// private GitHub modules are resolved without asking any proxy
+	assert.Equal(t, "https://github.com/ourorg/service", updated[0].RepositoryURL)
+
+	assert.True(t, updated[1].Skip)
+	assert.Equal(t, "Private module not resolvable from its VCS host", updated[1].SkipReason)
+
+	// module paths are case-encoded for the proxy
+	assert.Equal(t, "https://github.com/BurntSushi/toml", updated[2].RepositoryURL)
+
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_GitHubEnterprise(t *testing.T) {
+	setGoModuleEnv(t, "", "ghe.corp.example.com,go.corp.example.com")
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// a vanity path pointing at the Enterprise host
+	httpmock.RegisterResponder("GET", "https://go.corp.example.com/kit?go-get=1",
+		httpmock.NewStringResponder(200,
+			`<meta name="go-import" content="go.corp.example.com/kit git https://ghe.corp.example.com/platform/kit.git">`))
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.corp.example.com"))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("mod", parser.WithOthers([]string{"ghe.corp.example.com/team/mod/v2", "v2.0.0"})),
+		parser.NewLibInfo("kit", parser.WithOthers([]string{"go.corp.example.com/kit", "v1.0.0"})),
+	}
+
+	updated := parser.GoParser{GitHubHosts: hosts}.GetRepositoryURL(libs)
+
+	assert.Equal(t, "https://ghe.corp.example.com/team/mod", updated[0].RepositoryURL)
+	assert.Equal(t, "https://ghe.corp.example.com/platform/kit", updated[1].RepositoryURL)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+
+	// without the host the module is not recognized
+	updated = parser.GoParser{}.GetRepositoryURL([]parser.LibInfo{
+		parser.NewLibInfo("kit", parser.WithOthers([]string{"go.corp.example.com/kit", "v1.0.0"})),
+	})
+	assert.True(t, updated[0].Skip)
+}
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_GitLab(t *testing.T) {
+	setGoModuleEnv(t, "https://proxy.golang.org,direct", "")
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/gitlab.com/group/sub/lib/@v/v1.0.0.info",
+		httpmock.NewStringResponder(200,
+			`{"version":"v1.0.0","origin":{"vcs":"git","url":"https://gitlab.com/group/sub/lib.git"}}`))
+
+	// a vanity path pointing at a project in a nested group
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/example.org/kit/@v/v1.0.0.info",
+		httpmock.NewStringResponder(404, `not found`))
+	httpmock.RegisterResponder("GET", "https://example.org/kit?go-get=1",
+		httpmock.NewStringResponder(200,
+			`<meta name="go-import" content="example.org/kit git https://gitlab.com/team/sub/kit.git">`))
+
+	updated := parser.GoParser{}.GetRepositoryURL([]parser.LibInfo{
+		parser.NewLibInfo("lib", parser.WithOthers([]string{"gitlab.com/group/sub/lib", "v1.0.0"})),
+		parser.NewLibInfo("kit", parser.WithOthers([]string{"example.org/kit", "v1.0.0"})),
+	})
+
+	assert.False(t, updated[0].Skip, updated[0].SkipReason)
+	assert.Equal(t, "https://gitlab.com/group/sub/lib", updated[0].RepositoryURL)
+	assert.Equal(t, "https://gitlab.com/team/sub/kit", updated[1].RepositoryURL)
+}
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_OtherForges(t *testing.T) {
+	setGoModuleEnv(t, "https://proxy.golang.org,direct", "")
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// Codeberg and SourceHut serve go-import meta tags too
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/codeberg.org/team/lib/@v/v1.0.0.info",
+		httpmock.NewStringResponder(404, `not found`))
+	httpmock.RegisterResponder("GET", "https://codeberg.org/team/lib?go-get=1",
+		httpmock.NewStringResponder(200,
+			`<meta name="go-import" content="codeberg.org/team/lib git https://codeberg.org/team/lib.git">`))
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/git.sr.ht/~user/tool/@v/v1.0.0.info",
+		httpmock.NewStringResponder(200, `{"version":"v1.0.0","origin":{"vcs":"git","url":"https://git.sr.ht/~user/tool"}}`))
+
+	// a forge no analyzer knows
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/example.org/pkg/@v/v1.0.0.info",
+		httpmock.NewStringResponder(200,
+			`{"version":"v1.0.0","origin":{"vcs":"git","url":"https://git.example.org/team/pkg"}}`))
+	httpmock.RegisterResponder("GET", "https://example.org/pkg?go-get=1",
+		httpmock.NewStringResponder(200,
+			`<meta name="go-import" content="example.org/pkg git https://git.example.org/team/pkg">`))
+
+	updated := parser.GoParser{}.GetRepositoryURL([]parser.LibInfo{
+		parser.NewLibInfo("lib", parser.WithOthers([]string{"codeberg.org/team/lib", "v1.0.0"})),
+		parser.NewLibInfo("tool", parser.WithOthers([]string{"git.sr.ht/~user/tool", "v1.0.0"})),
+		parser.NewLibInfo("pkg", parser.WithOthers([]string{"example.org/pkg", "v1.0.0"})),
+	})
+
+	assert.Equal(t, "https://codeberg.org/team/lib", updated[0].RepositoryURL)
+	assert.Equal(t, "https://git.sr.ht/~user/tool", updated[1].RepositoryURL)
+	assert.True(t, updated[2].Skip)
+	assert.Equal(t, "Unsupported repository host: git.example.org", updated[2].SkipReason)
+}
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	goSourceFieldCount = 4
+	goImportFieldCount = 3
+)
+
+var (
+	metaTagRegex       = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttributeRegex = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	// gopkg.in/yaml.v3 and gopkg.in/user/pkg.v1
+	gopkgInRegex = regexp.MustCompile(`^gopkg\.in/(?:([^/.]+)/)?([^/.]+)\.v\d+`)
+)
+
+// goImportMeta is one <meta name="go-import"> or <meta name="go-source"> tag.
+type goImportMeta struct {
+	name    string
+	content []string
+}
+
+// knownGitHubRepository maps import paths of well-known vanity hosts to their GitHub
+// repositories without asking the host.
+func knownGitHubRepository(modulePath string) (string, bool) {
+	if rest, found := strings.CutPrefix(modulePath, "golang.org/x/"); found {
+		repo, _, _ := strings.Cut(rest, "/")
+
+		return "https://github.com/golang/" + repo, true
+	}
+
+	if matches := gopkgInRegex.FindStringSubmatch(modulePath); matches != nil {
+		owner := matches[1]
+		if owner == "" {
+			owner = "go-" + matches[2]
+		}
+
+		return "https://github.com/" + owner + "/" + matches[2], true
+	}
+
+	return "", false
+}
+
+// resolveVanityImport asks the host of a vanity import path where the code lives,
+// the same way the go command does, via the go-import and go-source meta tags.
+func (p GoParser) resolveVanityImport(client *http.Client, modulePath string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
+	defer cancel()
+
+	metaURL := "https://" + modulePath + "?go-get=1"
+	utils.DebugPrintln("Fetching: " + metaURL)
+
+	parsedURL, err := url.Parse(metaURL)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToGetRepository, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", ErrNotAGitHubRepository
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", ErrFailedToReadResponseBody
+	}
+
+	return repositoryFromGoImportMeta(parseGoImportMetas(string(bodyBytes)), modulePath, p.GitHubHosts)
+}
+
+func parseGoImportMetas(html string) []goImportMeta {
+	var metas []goImportMeta
+
+	for _, tag := range metaTagRegex.FindAllString(html, -1) {
+		attributes := map[string]string{}
+
+		for _, matches := range metaAttributeRegex.FindAllStringSubmatch(tag, -1) {
+			attributes[strings.ToLower(matches[1])] = matches[2] + matches[3]
+		}
+
+		name := attributes["name"]
+		if name != "go-import" && name != "go-source" {
+			continue
+		}
+
+		metas = append(metas, goImportMeta{name: name, content: strings.Fields(attributes["content"])})
+	}
+
+	return metas
+}
+
+// repositoryFromGoImportMeta picks the GitHub repository from the go-import tag whose
+// prefix matches the module, falling back to the home page of the go-source tag.
+func repositoryFromGoImportMeta(metas []goImportMeta, modulePath string, hosts *utils.GitHubHosts) (string, error) {
+	var candidates []string
+
+	for _, meta := range metas {
+		if len(meta.content) == 0 || !matchesImportPrefix(modulePath, meta.content[0]) {
+			continue
+		}
+
+		switch {
+		case meta.name == "go-import" && len(meta.content) == goImportFieldCount:
+			candidates = append([]string{meta.content[2]}, candidates...)
+		case meta.name == "go-source" && len(meta.content) == goSourceFieldCount:
+			candidates = append(candidates, meta.content[1])
+		}
+	}
+
+	for _, candidate := range candidates {
+		if repoURL, ok := googlesourceMirror(candidate); ok {
+			return repoURL, nil
+		}
+
+		if repoURL, ok := hostedRepositoryRoot(hosts, candidate); ok {
+			return repoURL, nil
+		}
+	}
+
+	if len(candidates) > 0 {
+		return "", unsupportedHost(candidates[0])
+	}
+
+	return "", ErrNotAGitHubRepository
+}
+
+func matchesImportPrefix(modulePath, prefix string) bool {
+	return modulePath == prefix || strings.HasPrefix(modulePath, prefix+"/")
+}
+
+// googlesourceMirror maps go.googlesource.com repositories to their GitHub mirrors under github.com/golang.
+func googlesourceMirror(repoURL string) (string, bool) {
+	_, repo, found := strings.Cut(repoURL, "go.googlesource.com/")
+	if !found || repo == "" {
+		return "", false
+	}
+
+	return "https://github.com/golang/" + strings.TrimSuffix(repo, ".git"), true
+}
@@ -0,0 +1,70 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+//nolint:paralleltest // Uses httpmock and t.Setenv
+func TestGoParser_GetRepositoryURL_VanityImports(t *testing.T) {
+	setGoModuleEnv(t, "", "")
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// the proxy knows the module but its origin is not on GitHub
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/go.uber.org/zap/@v/v1.27.0.info",
+		httpmock.NewStringResponder(200, `{"version":"v1.27.0","origin":{"vcs":"git","url":"https://go.uber.org/zap"}}`))
+	httpmock.RegisterResponder("GET", "https://go.uber.org/zap?go-get=1",
+		httpmock.NewStringResponder(200, `<!DOCTYPE html>
+<html><head>
+<meta name="go-import" content="go.uber.org/zap git https://github.com/uber-go/zap">
+<meta name="go-source" content="go.uber.org/zap https://github.com/uber-go/zap `+
+			`https://github.com/uber-go/zap/tree/master{/dir} https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}">
+</head></html>`))
+
+	// only go-source points at GitHub
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/example.org/tool/@v/v0.1.0.info",
+		httpmock.NewStringResponder(404, `not found`))
+	httpmock.RegisterResponder("GET", "https://example.org/tool?go-get=1",
+		httpmock.NewStringResponder(200,
+			`<meta name='go-import' content='example.org/tool git https://git.example.org/tool'>`+
+				`<meta name='go-source' content='example.org/tool https://github.com/example/tool _ _'>`))
+
+	// the meta tag is for another module
+	httpmock.RegisterResponder("GET", "https://proxy.golang.org/example.org/other/@v/v1.0.0.info",
+		httpmock.NewStringResponder(404, `not found`))
+	httpmock.RegisterResponder("GET", "https://example.org/other?go-get=1",
+		httpmock.NewStringResponder(200, `<meta name="go-import" content="example.org/another git https://github.com/x/y">`))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("zap", parser.WithOthers([]string{"go.uber.org/zap", "v1.27.0"})),
+		parser.NewLibInfo("yaml.v3", parser.WithOthers([]string{"gopkg.in/yaml.v3", "v3.0.1"})),
+		parser.NewLibInfo("check.v1", parser.WithOthers([]string{"gopkg.in/check.v1", "v1.0.0"})),
+		parser.NewLibInfo("tomb.v2", parser.WithOthers([]string{"gopkg.in/someone/tomb.v2", "v2.0.0"})),
+		parser.NewLibInfo("net", parser.WithOthers([]string{"golang.org/x/net", "v0.30.0"})),
+		parser.NewLibInfo("tool", parser.WithOthers([]string{"example.org/tool", "v0.1.0"})),
+		parser.NewLibInfo("other", parser.WithOthers([]string{"example.org/other", "v1.0.0"})),
+	}
+
+	updated := parser.GoParser{}.GetRepositoryURL(libs)
+
+	assert.Equal(t, "https://github.com/uber-go/zap", updated[0].RepositoryURL)
+	assert.Equal(t, "https://github.com/go-yaml/yaml", updated[1].RepositoryURL)
+	assert.Equal(t, "https://github.com/go-check/check", updated[2].RepositoryURL)
+	assert.Equal(t, "https://github.com/someone/tomb", updated[3].RepositoryURL)
+	assert.Equal(t, "https://github.com/golang/net", updated[4].RepositoryURL)
+	assert.Equal(t, "https://github.com/example/tool", updated[5].RepositoryURL)
+
+	assert.True(t, updated[6].Skip)
+	assert.Equal(t, "Repository not found", updated[6].SkipReason)
+
+	// gopkg.in and golang.org/x never hit the network
+	info := httpmock.GetCallCountInfo()
+	assert.Zero(t, info["GET https://proxy.golang.org/golang.org/x/net/@v/v0.30.0.info"])
+	assert.Zero(t, info["GET https://proxy.golang.org/gopkg.in/yaml.v3/@v/v3.0.1.info"])
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	goWorkFileName = "go.work"
+	goModFileName  = "go.mod"
+)
+
+// GoWorkParser reads the go.mod of every module a go.work uses and merges their requirements.
+// Each dependency lists the workspace modules that require it in UsedBy.
+type GoWorkParser struct {
+	IncludeIndirect bool
+	Concurrency     int
+	Cache           *utils.Cache
+	GitHubHosts     *utils.GitHubHosts
+}
+
+func (p GoWorkParser) Parse(filePath string) ([]LibInfo, error) {
+	workFile, err := p.readWorkFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	goParser := GoParser{IncludeIndirect: p.IncludeIndirect}
+	workDir := filepath.Dir(filePath)
+
+	var modFiles []*modfile.File
+
+	workspaceModules := map[string]bool{}
+
+	for _, use := range workFile.Use {
+		modPath := filepath.Join(workDir, filepath.FromSlash(use.Path), goModFileName)
+		utils.DebugPrintln("Reading workspace module: " + modPath)
+
+		modFile, err := goParser.readModFile(modPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if modFile.Module == nil {
+			return nil, fmt.Errorf("%w: %s: missing module directive", ErrFailedToReadFile, modPath)
+		}
+
+		modFiles = append(modFiles, modFile)
+		workspaceModules[modFile.Module.Mod.Path] = true
+	}
+
+	var libs []LibInfo
+
+	index := map[string]int{}
+
+	for _, modFile := range modFiles {
+		user := modFile.Module.Mod.Path
+
+		for _, lib := range goParser.processRequires(modFile, workFile.Replace) {
+			if workspaceModules[lib.Others[0]] {
+				lib = NewLibInfo(lib.Name, WithSkip(true), WithSkipReason("Workspace module"),
+					WithOthers(lib.Others), WithIndirect(lib.Indirect))
+			}
+
+			key := lib.Name + " " + lib.Others[0]
+
+			i, found := index[key]
+			if !found {
+				lib.UsedBy = []string{user}
+				index[key] = len(libs)
+				libs = append(libs, lib)
+
+				continue
+			}
+
+			mergeRequirement(&libs[i], lib, user)
+		}
+	}
+
+	return libs, nil
+}
+
+func (p GoWorkParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
+	goParser := GoParser{
+		IncludeIndirect: p.IncludeIndirect, Concurrency: p.Concurrency,
+		Cache: p.Cache, GitHubHosts: p.GitHubHosts,
+	}
+
+	return goParser.GetRepositoryURL(libInfoList)
+}
+
+func (p GoWorkParser) readWorkFile(filePath string) (*modfile.WorkFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	workFile, err := modfile.ParseWork(filePath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return workFile, nil
+}
+
+// mergeRequirement folds a requirement of another workspace module into an existing one.
+// Like minimal version selection the higher version wins, and a dependency is direct
+// as soon as one module requires it directly.
+func mergeRequirement(existing *LibInfo, other LibInfo, user string) {
+	if !slices.Contains(existing.UsedBy, user) {
+		existing.UsedBy = append(existing.UsedBy, user)
+	}
+
+	existing.Indirect = existing.Indirect && other.Indirect
+
+	if semver.Compare(other.Others[1], existing.Others[1]) > 0 {
+		existing.Others = other.Others
+	}
+}
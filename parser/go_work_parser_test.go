@@ -0,0 +1,89 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+//nolint:funlen // Workspace fixture needs several files
+func TestGoWorkParser_Parse_MergesWorkspaceModules(t *testing.T) {
+	t.Parallel()
+
+	goWork := `go 1.22
+
+use (
+    ./api
+    ./worker
+)
+
+replace github.com/shared/patched => github.com/ourorg/patched v1.0.1
+`
+	apiMod := `module example.com/mono/api
+
+require (
+    github.com/shared/lib v1.2.0
+    github.com/only/api v0.1.0
+    example.com/mono/worker v0.0.0
+    github.com/shared/patched v1.0.0
+)
+`
+	workerMod := `module example.com/mono/worker
+
+require (
+    github.com/shared/lib v1.4.0
+    github.com/only/worker v2.0.0+incompatible // indirect
+)
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "api"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "worker"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.work"), []byte(goWork), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api", "go.mod"), []byte(apiMod), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "worker", "go.mod"), []byte(workerMod), 0o600))
+
+	goParser, err := parser.SelectParser("go", parser.WithIncludeIndirect(true))
+	require.NoError(t, err)
+
+	libs, err := goParser.Parse(filepath.Join(dir, "go.work"))
+	require.NoError(t, err)
+	require.Len(t, libs, 6)
+
+	// shared dependency: merged, highest version wins, both users listed
+	assert.Equal(t, "lib", libs[0].Name)
+	assert.Equal(t, []string{"github.com/shared/lib", "v1.4.0"}, libs[0].Others)
+	assert.Equal(t, []string{"example.com/mono/api", "example.com/mono/worker"}, libs[0].UsedBy)
+
+	assert.Equal(t, "api", libs[1].Name)
+	assert.Equal(t, []string{"example.com/mono/api"}, libs[1].UsedBy)
+
+	// workspace-local module is not analyzed
+	assert.Equal(t, "worker", libs[2].Name)
+	assert.True(t, libs[2].Skip)
+	assert.Equal(t, "Workspace module", libs[2].SkipReason)
+
+	// go.work replace applies to the workspace modules
+	assert.Equal(t, "patched", libs[3].Name)
+	assert.Equal(t, "patched (replaces patched)", libs[4].Name)
+	assert.Equal(t, []string{"github.com/ourorg/patched", "v1.0.1"}, libs[4].Others)
+
+	assert.Equal(t, "worker", libs[5].Name)
+	assert.True(t, libs[5].Indirect)
+	assert.Equal(t, []string{"example.com/mono/worker"}, libs[5].UsedBy)
+}
+
+func TestGoWorkParser_Parse_MissingModule(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.work"), []byte("go 1.22\n\nuse ./missing\n"), 0o600))
+
+	_, err := parser.GoWorkParser{}.Parse(filepath.Join(dir, "go.work"))
+	require.ErrorIs(t, err, parser.ErrFiledToOpenFile)
+}
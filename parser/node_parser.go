@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const packageLockFileName = "package-lock.json"
+
+type NodeParser struct {
+	Concurrency int                // レジストリへの同時リクエスト数
+	GitHubHosts *utils.GitHubHosts // github.com以外のGitHub Enterpriseのホスト
+}
+
+type PackageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`      //nolint:tagliatelle // npm field name
+	OptionalDependencies map[string]string `json:"optionalDependencies"` //nolint:tagliatelle // npm field name
+}
+
+type PackageLockJSON struct {
+	LockfileVersion int                           `json:"lockfileVersion"` //nolint:tagliatelle // npm field name
+	Packages        map[string]PackageLockPackage `json:"packages"`
+	Dependencies    map[string]PackageLockPackage `json:"dependencies"`
+}
+
+type PackageLockPackage struct {
+	Version string `json:"version"`
+}
+
+// NpmRepository is the subset of the npm registry metadata used to find the source repository.
+// The repository field is either a plain string or an object with a url.
+type NpmRepository struct {
+	Repository json.RawMessage `json:"repository"`
+	Homepage   string          `json:"homepage"`
+}
+
+var githubShorthandRegex = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+func (p NodeParser) Parse(filePath string) ([]LibInfo, error) {
+	bodyBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	var packageJSON PackageJSON
+
+	err = json.Unmarshal(bodyBytes, &packageJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToUnmarshalJSON, err)
+	}
+
+	lockedVersions := p.readLockedVersions(filepath.Join(filepath.Dir(filePath), packageLockFileName))
+
+	var libs []LibInfo
+
+	seen := map[string]bool{}
+
+	for _, deps := range []map[string]string{
+		packageJSON.Dependencies,
+		packageJSON.DevDependencies,
+		packageJSON.OptionalDependencies,
+	} {
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			if seen[name] {
+				continue
+			}
+
+			seen[name] = true
+
+			libs = append(libs, p.createLibInfo(name, deps[name], lockedVersions[name]))
+		}
+	}
+
+	return libs, nil
+}
+
+func (p NodeParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
+	client := &http.Client{}
+
+	_ = utils.ForEachIndex(context.Background(), p.Concurrency, len(libInfoList), func(i int) {
+		libInfo := &libInfoList[i]
+
+		if libInfo.Skip || libInfo.RepositoryURL != "" {
+			return
+		}
+
+		name := libInfo.Others[0]
+
+		repoURL, err := p.getGitHubRepositoryURL(client, name)
+		if err != nil {
+			libInfo.Skip = true
+			libInfo.SkipReason = unresolvedReason(err)
+
+			utils.StdErrorPrintln("%s: repository not resolved: %s", name, err)
+
+			return
+		}
+
+		libInfo.RepositoryURL = repoURL
+	})
+
+	return libInfoList
+}
+
+// readLockedVersions returns the resolved version of each top-level package in package-lock.json.
+// A missing or unreadable lockfile is not an error; versions then come from package.json.
+func (p NodeParser) readLockedVersions(lockFilePath string) map[string]string {
+	versions := map[string]string{}
+
+	bodyBytes, err := os.ReadFile(lockFilePath)
+	if err != nil {
+		return versions
+	}
+
+	var lock PackageLockJSON
+
+	err = json.Unmarshal(bodyBytes, &lock)
+	if err != nil {
+		utils.StdErrorPrintln("%v: %s: %v", ErrFailedToUnmarshalJSON, lockFilePath, err)
+
+		return versions
+	}
+
+	utils.DebugPrintln("Reading lockfile: " + lockFilePath)
+
+	// lockfileVersion 2 and 3 list every installed package under "packages"
+	for path, pkg := range lock.Packages {
+		name, found := strings.CutPrefix(path, "node_modules/")
+		if !found || strings.Contains(name, "/node_modules/") {
+			continue
+		}
+
+		versions[name] = pkg.Version
+	}
+
+	// lockfileVersion 1 only has the nested "dependencies" tree
+	for name, pkg := range lock.Dependencies {
+		if _, ok := versions[name]; !ok {
+			versions[name] = pkg.Version
+		}
+	}
+
+	return versions
+}
+
+func (p NodeParser) createLibInfo(name, spec, lockedVersion string) LibInfo {
+	version := spec
+	if lockedVersion != "" {
+		version = lockedVersion
+	}
+
+	switch {
+	case strings.HasPrefix(spec, "file:"), strings.HasPrefix(spec, "link:"), strings.HasPrefix(spec, "workspace:"):
+		return NewLibInfo(name, WithSkip(true), WithSkipReason("Local package"))
+	case strings.HasPrefix(spec, "npm:"):
+		// Aliased package: "alias": "npm:real-name@^1.0.0"
+		realName := strings.TrimPrefix(spec, "npm:")
+		if at := strings.LastIndex(realName, "@"); at > 0 {
+			if lockedVersion == "" {
+				version = realName[at+1:]
+			}
+
+			realName = realName[:at]
+		}
+
+		return NewLibInfo(name, WithOthers([]string{realName, version}))
+	case isGitSpec(spec):
+		root, err := hostedRepository(p.GitHubHosts, normalizeNpmRepositoryURL(spec))
+		if err != nil {
+			return NewLibInfo(name, WithSkip(true), WithSkipReason(unresolvedReason(err)))
+		}
+
+		lib := NewLibInfo(name, WithOthers([]string{name, version}))
+		lib.RepositoryURL = root
+
+		return lib
+	default:
+		return NewLibInfo(name, WithOthers([]string{name, version}))
+	}
+}
+
+func (p NodeParser) getGitHubRepositoryURL(client *http.Client, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
+	defer cancel()
+
+	baseURL := "https://registry.npmjs.org/"
+	repoURL := baseURL + name + "/latest"
+	utils.DebugPrintln("Fetching: " + repoURL)
+
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", ErrNotAGitHubRepository
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", ErrFailedToReadResponseBody
+	}
+
+	var repo NpmRepository
+
+	err = json.Unmarshal(bodyBytes, &repo)
+	if err != nil {
+		return "", ErrFailedToUnmarshalJSON
+	}
+
+	repoURLfromNpm := normalizeNpmRepositoryURL(repositoryFieldURL(repo.Repository))
+
+	if repoURLfromNpm == "" {
+		repoURLfromNpm = normalizeNpmRepositoryURL(repo.Homepage)
+	}
+
+	if repoURLfromNpm == "" {
+		return "", ErrNotAGitHubRepository
+	}
+
+	return hostedRepository(p.GitHubHosts, repoURLfromNpm)
+}
+
+// repositoryFieldURL extracts the URL from the npm "repository" field,
+// which may be either "url" or {"type": "git", "url": "url"}.
+func repositoryFieldURL(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var str string
+
+	err := json.Unmarshal(raw, &str)
+	if err == nil {
+		return str
+	}
+
+	var obj struct {
+		URL string `json:"url"`
+	}
+
+	err = json.Unmarshal(raw, &obj)
+	if err != nil {
+		return ""
+	}
+
+	return obj.URL
+}
+
+func isGitSpec(spec string) bool {
+	spec, _, _ = strings.Cut(spec, "#")
+
+	for _, prefix := range []string{"git+", "git://", "git@", "github:", "https://github.com/", "http://github.com/"} {
+		if strings.HasPrefix(spec, prefix) {
+			return true
+		}
+	}
+
+	return githubShorthandRegex.MatchString(spec)
+}
+
+// normalizeNpmRepositoryURL converts the repository notations accepted by npm
+// (git+https://, git://, git@host:, github:owner/repo, owner/repo) into https://host/owner/repo.
+// Paths after the repository, such as /tree/<branch>, are kept for the repository root to trim.
+func normalizeNpmRepositoryURL(raw string) string {
+	repoURL := strings.TrimSpace(raw)
+	if repoURL == "" {
+		return ""
+	}
+
+	if idx := strings.Index(repoURL, "#"); idx >= 0 {
+		repoURL = repoURL[:idx]
+	}
+
+	switch {
+	case strings.HasPrefix(repoURL, "github:"):
+		repoURL = "https://github.com/" + strings.TrimPrefix(repoURL, "github:")
+	case githubShorthandRegex.MatchString(repoURL):
+		repoURL = "https://github.com/" + repoURL
+	}
+
+	repoURL = strings.TrimPrefix(repoURL, "git+")
+
+	if rest, found := strings.CutPrefix(repoURL, "git@"); found {
+		repoURL = "https://" + strings.Replace(rest, ":", "/", 1)
+	}
+
+	if rest, found := strings.CutPrefix(repoURL, "ssh://git@"); found {
+		repoURL = "https://" + rest
+	}
+
+	if rest, found := strings.CutPrefix(repoURL, "git://"); found {
+		repoURL = "https://" + rest
+	}
+
+	repoURL = strings.TrimSuffix(repoURL, "/")
+
+	return strings.TrimSuffix(repoURL, ".git")
+}
@@ -0,0 +1,173 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+func TestNodeParser_Parse_WithLockfile(t *testing.T) {
+	t.Parallel()
+
+	packageJSON := `{
+  "name": "demo",
+  "dependencies": {
+    "react": "^18.2.0",
+    "local-lib": "file:../local-lib",
+    "forked": "github:someone/forked#v1.0.0",
+    "aliased": "npm:real-package@^2.0.0"
+  },
+  "devDependencies": {
+    "jest": "^29.0.0",
+    "react": "^18.0.0"
+  },
+  "optionalDependencies": {
+    "fsevents": "^2.3.0"
+  }
+}`
+	packageLock := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "demo"},
+    "node_modules/react": {"version": "18.2.0"},
+    "node_modules/jest": {"version": "29.7.0"},
+    "node_modules/jest/node_modules/chalk": {"version": "4.1.2"}
+  }
+}`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJSON), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(packageLock), 0o600))
+
+	p := parser.NodeParser{}
+
+	libs, err := p.Parse(filepath.Join(dir, "package.json"))
+	require.NoError(t, err)
+
+	// dependencies (sorted), then devDependencies, then optionalDependencies; react is not repeated
+	names := make([]string, 0, len(libs))
+	for _, lib := range libs {
+		names = append(names, lib.Name)
+	}
+
+	assert.Equal(t, []string{"aliased", "forked", "local-lib", "react", "jest", "fsevents"}, names)
+
+	assert.Equal(t, []string{"real-package", "^2.0.0"}, libs[0].Others)
+
+	assert.False(t, libs[1].Skip)
+	assert.Equal(t, "https://github.com/someone/forked", libs[1].RepositoryURL)
+
+	assert.True(t, libs[2].Skip)
+	assert.Equal(t, "Local package", libs[2].SkipReason)
+
+	assert.Equal(t, []string{"react", "18.2.0"}, libs[3].Others)
+	assert.Equal(t, []string{"jest", "29.7.0"}, libs[4].Others)
+	assert.Equal(t, []string{"fsevents", "^2.3.0"}, libs[5].Others)
+}
+
+func TestNodeParser_Parse_Errors(t *testing.T) {
+	t.Parallel()
+
+	p := parser.NodeParser{}
+
+	_, err := p.Parse(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, parser.ErrFiledToOpenFile)
+
+	path := filepath.Join(t.TempDir(), "package.json")
+	require.NoError(t, os.WriteFile(path, []byte("not-json"), 0o600))
+
+	_, err = p.Parse(path)
+	require.ErrorIs(t, err, parser.ErrFailedToUnmarshalJSON)
+}
+
+func TestNodeParser_Parse_GitSpecsTrimmedToRepository(t *testing.T) {
+	t.Parallel()
+
+	packageJSON := `{
+  "dependencies": {
+    "branch": "https://github.com/someone/branch/tree/next",
+    "enterprise": "git+https://ghe.example.com/team/lib.git",
+    "nested": "git+https://gitlab.com/group/sub/lib.git",
+    "org-only": "github:org",
+    "org-url": "https://github.com/org",
+    "self-hosted": "git+https://git.example.com/team/lib.git"
+  }
+}`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJSON), 0o600))
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("ghe.example.com"))
+
+	libs, err := parser.NodeParser{GitHubHosts: hosts}.Parse(filepath.Join(dir, "package.json"))
+	require.NoError(t, err)
+	require.Len(t, libs, 6)
+
+	assert.Equal(t, "https://github.com/someone/branch", libs[0].RepositoryURL)
+	assert.Equal(t, "https://ghe.example.com/team/lib", libs[1].RepositoryURL)
+	assert.Equal(t, "https://gitlab.com/group/sub/lib", libs[2].RepositoryURL)
+
+	for _, lib := range libs[3:5] {
+		assert.True(t, lib.Skip, lib.Name)
+		assert.Equal(t, "Repository not found", lib.SkipReason)
+		assert.Empty(t, lib.RepositoryURL)
+	}
+
+	assert.True(t, libs[5].Skip)
+	assert.Equal(t, "Unsupported repository host: git.example.com", libs[5].SkipReason)
+}
+
+//nolint:paralleltest // Uses httpmock which doesn't support parallel tests
+func TestNodeParser_GetRepositoryURL(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://registry.npmjs.org/react/latest",
+		httpmock.NewStringResponder(200,
+			`{"repository": {"type": "git", "url": "git+https://github.com/facebook/react.git", `+
+				`"directory": "packages/react"}}`))
+
+	httpmock.RegisterResponder("GET", "https://registry.npmjs.org/@babel/core/latest",
+		httpmock.NewStringResponder(200, `{"repository": "git://github.com/babel/babel.git"}`))
+
+	httpmock.RegisterResponder("GET", "https://registry.npmjs.org/lodash/latest",
+		httpmock.NewStringResponder(200, `{"repository": "lodash/lodash"}`))
+
+	httpmock.RegisterResponder("GET", "https://registry.npmjs.org/homepage-only/latest",
+		httpmock.NewStringResponder(200, `{"homepage": "https://github.com/someone/homepage-only#readme"}`))
+
+	httpmock.RegisterResponder("GET", "https://registry.npmjs.org/elsewhere/latest",
+		httpmock.NewStringResponder(200,
+			`{"repository": {"type": "git", "url": "https://gitlab.com/someone/elsewhere.git"}}`))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("react", parser.WithOthers([]string{"react", "18.2.0"})),
+		parser.NewLibInfo("@babel/core", parser.WithOthers([]string{"@babel/core", "7.0.0"})),
+		parser.NewLibInfo("lodash", parser.WithOthers([]string{"lodash", "4.17.21"})),
+		parser.NewLibInfo("homepage-only", parser.WithOthers([]string{"homepage-only", "1.0.0"})),
+		parser.NewLibInfo("elsewhere", parser.WithOthers([]string{"elsewhere", "1.0.0"})),
+		parser.NewLibInfo("local-lib", parser.WithSkip(true), parser.WithSkipReason("Local package")),
+	}
+
+	p := parser.NodeParser{}
+	updated := p.GetRepositoryURL(libs)
+
+	assert.Equal(t, "https://github.com/facebook/react", updated[0].RepositoryURL)
+	assert.Equal(t, "https://github.com/babel/babel", updated[1].RepositoryURL)
+	assert.Equal(t, "https://github.com/lodash/lodash", updated[2].RepositoryURL)
+	assert.Equal(t, "https://github.com/someone/homepage-only", updated[3].RepositoryURL)
+
+	assert.False(t, updated[4].Skip, updated[4].SkipReason)
+	assert.Equal(t, "https://gitlab.com/someone/elsewhere", updated[4].RepositoryURL)
+
+	assert.True(t, updated[5].Skip)
+	assert.Equal(t, "Local package", updated[5].SkipReason)
+}
@@ -3,6 +3,10 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
 var (
@@ -22,12 +26,160 @@ var (
 
 const timeOutSec = 30
 
+// UnsupportedHostError is returned for a repository found on a host none of the analyzers knows.
+type UnsupportedHostError struct {
+	Host string
+}
+
+// unsupportedHost reads the host of a repository URL, or of an scp-like git remote (git@host:owner/repo).
+func unsupportedHost(repoURL string) error {
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Host != "" {
+		return &UnsupportedHostError{Host: parsed.Hostname()}
+	}
+
+	_, remote, _ := strings.Cut(repoURL, "@")
+	host, _, _ := strings.Cut(remote, ":")
+
+	return &UnsupportedHostError{Host: host}
+}
+
+func (e *UnsupportedHostError) Error() string {
+	return "repository host " + e.Host + " is not supported"
+}
+
+// Is keeps it an ErrNotAGitHubRepository for the callers that only tell found from not found.
+func (e *UnsupportedHostError) Is(target error) bool {
+	return target == ErrNotAGitHubRepository
+}
+
+// unresolvedReason is the skip reason of a library whose repository could not be resolved.
+func unresolvedReason(err error) string {
+	var hostErr *UnsupportedHostError
+
+	switch {
+	case errors.As(err, &hostErr):
+		return "Unsupported repository host: " + hostErr.Host
+	case errors.Is(err, utils.ErrNotInOfflineSnapshot):
+		return "Not in offline snapshot"
+	case errors.Is(err, ErrNotAGitHubRepository):
+		return "Repository not found"
+	default:
+		return "Repository lookup failed"
+	}
+}
+
+// repositoryRoot trims a repository URL on host down to https://<host>/owner/repo. URLs naming
+// no repository, such as that of an organization, are rejected.
+func repositoryRoot(repoURL, host string) (string, bool) {
+	index := strings.Index(strings.ToLower(repoURL), host)
+	if index < 0 {
+		return "", false
+	}
+
+	path := strings.TrimLeft(repoURL[index+len(host):], "/:")
+	parts := strings.Split(path, "/")
+
+	if len(parts) < 2 || parts[0] == "" || strings.TrimSuffix(parts[1], ".git") == "" {
+		return "", false
+	}
+
+	return "https://" + host + "/" + parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), true
+}
+
+// hostedRepositoryRoot returns the repository root of a URL on github.com, on a
+// GitHub Enterprise host of hosts or on another forge the analyzers know. It fails for
+// other hosts and for URLs naming no repository.
+func hostedRepositoryRoot(hosts *utils.GitHubHosts, repoURL string) (string, bool) {
+	root, err := hostedRepository(hosts, repoURL)
+
+	return root, err == nil
+}
+
+// hostedRepository is hostedRepositoryRoot telling why it failed: an UnsupportedHostError,
+// or ErrNotAGitHubRepository for a URL on a known forge naming no repository.
+func hostedRepository(hosts *utils.GitHubHosts, repoURL string) (string, error) {
+	var (
+		root string
+		ok   bool
+	)
+
+	switch host, found := forgeHost(hosts, repoURL); {
+	case !found:
+		return "", unsupportedHost(repoURL)
+	case host == utils.PublicGitLabHost:
+		root, ok = gitLabRepositoryRoot(repoURL)
+	default:
+		root, ok = repositoryRoot(repoURL, host)
+	}
+
+	if !ok {
+		return "", ErrNotAGitHubRepository
+	}
+
+	return root, nil
+}
+
+// forgeHost finds the host of a repository URL among the forges the analyzers know.
+func forgeHost(hosts *utils.GitHubHosts, repoURL string) (string, bool) {
+	if utils.OnHost(repoURL, utils.PublicGitLabHost) {
+		return utils.PublicGitLabHost, true
+	}
+
+	for _, host := range utils.OwnerRepoForgeHosts() {
+		if utils.OnHost(repoURL, host) {
+			return host, true
+		}
+	}
+
+	host, ok := hosts.Lookup(repoURL)
+
+	return host.Name, ok
+}
+
+// gitLabRepositoryRoot keeps the whole project path, as GitLab projects may sit in nested
+// groups (gitlab.com/group/subgroup/project). Pages of a project follow a "/-/" segment.
+// A group alone is no project.
+func gitLabRepositoryRoot(repoURL string) (string, bool) {
+	host := utils.PublicGitLabHost
+
+	index := strings.Index(strings.ToLower(repoURL), host)
+	path := strings.TrimLeft(repoURL[index+len(host):], "/:")
+	path, _, _ = strings.Cut(path, "/-/")
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+
+	if !strings.Contains(path, "/") {
+		return "", false
+	}
+
+	return "https://" + host + "/" + path, true
+}
+
 type LibInfo struct {
 	Skip          bool     // スキップするかどうかのフラグ
 	SkipReason    string   // スキップ理由
 	Name          string   // ライブラリの名前
 	Others        []string // その他のライブラリの設定値
 	RepositoryURL string   // githubのりポトリのURL
+	Indirect      bool     // 間接(推移的)依存かどうかのフラグ
+	UsedBy        []string // このライブラリを使うワークスペース内のモジュール
+}
+
+// dedupeLibInfos keeps the first library of each name.
+func dedupeLibInfos(libs []LibInfo) []LibInfo {
+	seen := map[string]bool{}
+	deduped := make([]LibInfo, 0, len(libs))
+
+	for _, lib := range libs {
+		if seen[lib.Name] {
+			continue
+		}
+
+		seen[lib.Name] = true
+
+		deduped = append(deduped, lib)
+	}
+
+	return deduped
 }
 
 type LibInfoOption func(*LibInfo)
@@ -50,6 +202,12 @@ func WithOthers(others []string) LibInfoOption {
 	}
 }
 
+func WithIndirect(indirect bool) LibInfoOption {
+	return func(l *LibInfo) {
+		l.Indirect = indirect
+	}
+}
+
 func NewLibInfo(name string, options ...LibInfoOption) LibInfo {
 	libInfo := LibInfo{
 		Name:          name,
@@ -57,6 +215,8 @@ func NewLibInfo(name string, options ...LibInfoOption) LibInfo {
 		SkipReason:    "",
 		Others:        nil,
 		RepositoryURL: "",
+		Indirect:      false,
+		UsedBy:        nil,
 	}
 
 	for _, option := range options {
@@ -71,12 +231,63 @@ type Parser interface {
 	GetRepositoryURL(AnalyzedLibInfoList []LibInfo) []LibInfo
 }
 
-func SelectParser(language string) (Parser, error) {
+// Options holds the settings shared by all parsers.
+type Options struct {
+	IncludeIndirect bool               // 間接(推移的)依存も解析対象にする
+	Concurrency     int                // リポジトリURL解決の同時リクエスト数
+	Cache           *utils.Cache       // proxy.golang.org と rubygems.org の応答キャッシュ (nil なら無効)
+	GitHubHosts     *utils.GitHubHosts // github.com 以外に GitHub Enterprise として扱うホスト
+}
+
+type Option func(*Options)
+
+func WithIncludeIndirect(include bool) Option {
+	return func(o *Options) {
+		o.IncludeIndirect = include
+	}
+}
+
+func WithConcurrency(concurrency int) Option {
+	return func(o *Options) {
+		o.Concurrency = concurrency
+	}
+}
+
+func WithCache(cache *utils.Cache) Option {
+	return func(o *Options) {
+		o.Cache = cache
+	}
+}
+
+func WithGitHubHosts(hosts *utils.GitHubHosts) Option {
+	return func(o *Options) {
+		o.GitHubHosts = hosts
+	}
+}
+
+func SelectParser(language string, options ...Option) (Parser, error) {
+	opts := Options{IncludeIndirect: false, Concurrency: 1, Cache: nil, GitHubHosts: nil}
+	for _, option := range options {
+		option(&opts)
+	}
+
 	switch language {
 	case "ruby":
-		return RubyParser{}, nil
+		return RubyParser{
+			IncludeIndirect: opts.IncludeIndirect, Concurrency: opts.Concurrency,
+			Cache: opts.Cache, GitHubHosts: opts.GitHubHosts,
+		}, nil
 	case "go":
-		return GoParser{}, nil
+		return GoParser{
+			IncludeIndirect: opts.IncludeIndirect, Concurrency: opts.Concurrency,
+			Cache: opts.Cache, GitHubHosts: opts.GitHubHosts,
+		}, nil
+	case "node":
+		return NodeParser{Concurrency: opts.Concurrency, GitHubHosts: opts.GitHubHosts}, nil
+	case "python":
+		return PythonParser{Concurrency: opts.Concurrency, GitHubHosts: opts.GitHubHosts}, nil
+	case "rust":
+		return RustParser{Concurrency: opts.Concurrency, GitHubHosts: opts.GitHubHosts}, nil
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
 	}
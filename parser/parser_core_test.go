@@ -25,13 +25,34 @@ func TestSelectParser_ReturnsCorrectTypes(t *testing.T) {
 	if _, ok := rubyParser.(parser.RubyParser); !ok {
 		t.Fatalf("expected RubyParser, got %T", rubyParser)
 	}
+
+	nodeParser, err := parser.SelectParser("node")
+	require.NoError(t, err)
+
+	if _, ok := nodeParser.(parser.NodeParser); !ok {
+		t.Fatalf("expected NodeParser, got %T", nodeParser)
+	}
+
+	pythonParser, err := parser.SelectParser("python")
+	require.NoError(t, err)
+
+	if _, ok := pythonParser.(parser.PythonParser); !ok {
+		t.Fatalf("expected PythonParser, got %T", pythonParser)
+	}
+
+	rustParser, err := parser.SelectParser("rust")
+	require.NoError(t, err)
+
+	if _, ok := rustParser.(parser.RustParser); !ok {
+		t.Fatalf("expected RustParser, got %T", rustParser)
+	}
 }
 
 func TestSelectParser_UnsupportedLanguage(t *testing.T) {
 	t.Parallel()
 
-	pythonParser, err := parser.SelectParser("python")
-	assert.Nil(t, pythonParser)
+	cobolParser, err := parser.SelectParser("cobol")
+	assert.Nil(t, cobolParser)
 	require.Error(t, err)
 	assert.ErrorIs(t, err, parser.ErrUnsupportedLanguage)
 }
@@ -46,6 +67,7 @@ func TestNewLibInfo_DefaultsAndOptions(t *testing.T) {
 	assert.Empty(t, li.SkipReason)
 	assert.Nil(t, li.Others)
 	assert.Empty(t, li.RepositoryURL)
+	assert.False(t, li.Indirect)
 
 	// With options
 	li2 := parser.NewLibInfo(
@@ -53,9 +75,11 @@ func TestNewLibInfo_DefaultsAndOptions(t *testing.T) {
 		parser.WithSkip(true),
 		parser.WithSkipReason("reason"),
 		parser.WithOthers([]string{"a", "b"}),
+		parser.WithIndirect(true),
 	)
 	assert.Equal(t, "libY", li2.Name)
 	assert.True(t, li2.Skip)
 	assert.Equal(t, "reason", li2.SkipReason)
 	assert.Equal(t, []string{"a", "b"}, li2.Others)
+	assert.True(t, li2.Indirect)
 }
@@ -0,0 +1,526 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	pyprojectFileName  = "pyproject.toml"
+	poetryLockFileName = "poetry.lock"
+)
+
+type PythonParser struct {
+	Concurrency int                // PyPIへの同時リクエスト数
+	GitHubHosts *utils.GitHubHosts // github.com以外のGitHub Enterpriseのホスト
+}
+
+type PyPIRepository struct {
+	Info struct {
+		ProjectURLs map[string]string `json:"project_urls"`
+		HomePage    string            `json:"home_page"`
+	} `json:"info"`
+}
+
+type Pyproject struct {
+	Project struct {
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+}
+
+type PoetryLock struct {
+	Packages []PoetryLockPackage `toml:"package"`
+}
+
+type PoetryLockPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Source  struct {
+		Type string `toml:"type"`
+		URL  string `toml:"url"`
+	} `toml:"source"`
+}
+
+var (
+	// PEP 508: name[extras] specifiers ; markers
+	requirementRegex = regexp.MustCompile(
+		`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*([^;]*?)\s*(?:;.*)?$`)
+	eggRegex            = regexp.MustCompile(`[#&]egg=([A-Za-z0-9._-]+)`)
+	pythonNameSeparator = regexp.MustCompile(`[-_.]+`)
+
+	// project_urls keys that usually point at the source repository, in order of preference
+	pypiSourceURLKeys = []string{"Source", "Source Code", "Repository", "Code", "GitHub", "Homepage"}
+)
+
+func (p PythonParser) Parse(filePath string) ([]LibInfo, error) {
+	switch filepath.Base(filePath) {
+	case pyprojectFileName:
+		return p.parsePyproject(filePath)
+	case poetryLockFileName:
+		return p.parsePoetryLock(filePath)
+	default:
+		return p.parseRequirements(filePath, map[string]bool{})
+	}
+}
+
+func (p PythonParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
+	client := &http.Client{}
+
+	_ = utils.ForEachIndex(context.Background(), p.Concurrency, len(libInfoList), func(i int) {
+		libInfo := &libInfoList[i]
+
+		if libInfo.Skip || libInfo.RepositoryURL != "" {
+			return
+		}
+
+		name := libInfo.Others[0]
+
+		repoURL, err := p.getGitHubRepositoryURL(client, name)
+		if err != nil {
+			libInfo.Skip = true
+			libInfo.SkipReason = unresolvedReason(err)
+
+			utils.StdErrorPrintln("%s: repository not resolved: %s", name, err)
+
+			return
+		}
+
+		libInfo.RepositoryURL = repoURL
+	})
+
+	return libInfoList
+}
+
+// parseRequirements reads a pip requirements file, following -r includes.
+// visited guards against include cycles.
+func (p PythonParser) parseRequirements(filePath string, visited map[string]bool) ([]LibInfo, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	if visited[absPath] {
+		return nil, nil
+	}
+
+	visited[absPath] = true
+
+	lines, err := p.readLogicalLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var libs []LibInfo
+
+	for _, line := range lines {
+		if include, ok := p.includedFile(line); ok {
+			included, err := p.parseRequirements(filepath.Join(filepath.Dir(filePath), include), visited)
+			if err != nil {
+				return nil, err
+			}
+
+			libs = append(libs, included...)
+
+			continue
+		}
+
+		if lib, ok := p.parseRequirementLine(line); ok {
+			libs = append(libs, lib)
+		}
+	}
+
+	return dedupeLibInfos(libs), nil
+}
+
+// readLogicalLines joins backslash continuations and strips comments and blank lines.
+func (p PythonParser) readLogicalLines(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+	defer file.Close()
+
+	var lines []string
+
+	current := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		line = strings.TrimSpace(line)
+
+		if continued, found := strings.CutSuffix(line, `\`); found {
+			current += continued + " "
+
+			continue
+		}
+
+		current = strings.TrimSpace(current + line)
+		if current != "" {
+			lines = append(lines, current)
+		}
+
+		current = ""
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	if current = strings.TrimSpace(current); current != "" {
+		lines = append(lines, current)
+	}
+
+	return lines, nil
+}
+
+func (p PythonParser) includedFile(line string) (string, bool) {
+	for _, option := range []string{"-r", "--requirement"} {
+		if rest, found := strings.CutPrefix(line, option); found {
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, "="))
+			if rest != "" {
+				return rest, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (p PythonParser) parseRequirementLine(line string) (LibInfo, bool) {
+	for _, option := range []string{"-e ", "--editable ", "--editable="} {
+		if target, found := strings.CutPrefix(line, option); found {
+			return p.createVCSLibInfo(strings.TrimSpace(target)), true
+		}
+	}
+
+	// Other pip options (-c, -i, --index-url, -f, ...) do not declare dependencies
+	if strings.HasPrefix(line, "-") {
+		return LibInfo{}, false
+	}
+
+	// Per-requirement options such as --hash are not part of the requirement itself
+	if idx := strings.Index(line, " --"); idx >= 0 {
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	scheme := strings.Index(line, "://")
+	at := strings.Index(line, "@")
+
+	// Bare URLs: "git+https://github.com/owner/repo@v1.0#egg=name"
+	if scheme >= 0 && (at < 0 || at > scheme) {
+		return p.createVCSLibInfo(line), true
+	}
+
+	// Direct references: "name @ git+https://github.com/owner/repo"
+	if scheme >= 0 {
+		lib := p.createVCSLibInfo(strings.TrimSpace(line[at+1:]))
+		lib.Name = normalizePythonName(strings.TrimSpace(stripExtras(line[:at])))
+
+		if !lib.Skip {
+			lib.Others[0] = lib.Name
+		}
+
+		return lib, true
+	}
+
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "/") {
+		return NewLibInfo(line, WithSkip(true), WithSkipReason("Local package")), true
+	}
+
+	return p.createRequirementLibInfo(line)
+}
+
+func (p PythonParser) createRequirementLibInfo(requirement string) (LibInfo, bool) {
+	matches := requirementRegex.FindStringSubmatch(requirement)
+	if matches == nil {
+		utils.StdErrorPrintln("%v: %s", ErrInvalidLineFormat, requirement)
+
+		return LibInfo{}, false
+	}
+
+	name := normalizePythonName(matches[1])
+	version := strings.ReplaceAll(matches[2], " ", "")
+
+	if pinned, found := strings.CutPrefix(version, "=="); found && !strings.ContainsAny(pinned, ",*") {
+		version = pinned
+	}
+
+	return NewLibInfo(name, WithOthers([]string{name, version})), true
+}
+
+// createVCSLibInfo handles editable and direct URL requirements.
+// URLs on a forge the analyzers know are used as the repository directly instead of asking PyPI.
+func (p PythonParser) createVCSLibInfo(target string) LibInfo {
+	if !strings.Contains(target, "://") && !strings.HasPrefix(target, "git@") {
+		return NewLibInfo(target, WithSkip(true), WithSkipReason("Local package"))
+	}
+
+	name := ""
+	if matches := eggRegex.FindStringSubmatch(target); matches != nil {
+		name = normalizePythonName(matches[1])
+	}
+
+	repoURL := target
+	if idx := strings.Index(repoURL, "#"); idx >= 0 {
+		repoURL = repoURL[:idx]
+	}
+
+	repoURL = strings.TrimPrefix(repoURL, "git+")
+
+	// Drop the "@ref" suffix of the path: https://github.com/owner/repo.git@v1.0
+	version := ""
+	rest := repoURL
+
+	if _, afterScheme, found := strings.Cut(rest, "://"); found {
+		rest = afterScheme
+	}
+
+	if _, path, _ := strings.Cut(rest, "/"); strings.Contains(path, "@") {
+		version = path[strings.LastIndex(path, "@")+1:]
+		repoURL = strings.TrimSuffix(repoURL, "@"+version)
+	}
+
+	repoURL, err := hostedRepository(p.GitHubHosts, repoURL)
+	if err != nil {
+		if name == "" {
+			name = target
+		}
+
+		return NewLibInfo(name, WithSkip(true), WithSkipReason(unresolvedReason(err)))
+	}
+
+	if name == "" {
+		name = normalizePythonName(repoURL[strings.LastIndex(repoURL, "/")+1:])
+	}
+
+	lib := NewLibInfo(name, WithOthers([]string{name, version}))
+	lib.RepositoryURL = repoURL
+
+	return lib
+}
+
+func (p PythonParser) parsePyproject(filePath string) ([]LibInfo, error) {
+	bodyBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	var pyproject Pyproject
+
+	err = toml.Unmarshal(bodyBytes, &pyproject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	lockedVersions := p.readPoetryLockVersions(filepath.Join(filepath.Dir(filePath), poetryLockFileName))
+
+	var libs []LibInfo
+
+	for _, dependency := range pyproject.Project.Dependencies {
+		lib, ok := p.parseRequirementLine(strings.TrimSpace(dependency))
+		if !ok {
+			continue
+		}
+
+		if version, found := lockedVersions[lib.Name]; found && !lib.Skip {
+			lib.Others[1] = version
+		}
+
+		libs = append(libs, lib)
+	}
+
+	return dedupeLibInfos(libs), nil
+}
+
+func (p PythonParser) parsePoetryLock(filePath string) ([]LibInfo, error) {
+	lock, err := p.readPoetryLock(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	libs := make([]LibInfo, 0, len(lock.Packages))
+
+	for _, pkg := range lock.Packages {
+		name := normalizePythonName(pkg.Name)
+
+		switch pkg.Source.Type {
+		case "git":
+			lib := p.createVCSLibInfo("git+" + pkg.Source.URL)
+			lib.Name = name
+
+			if !lib.Skip {
+				lib.Others = []string{name, pkg.Version}
+			}
+
+			libs = append(libs, lib)
+		case "directory", "file":
+			libs = append(libs, NewLibInfo(name, WithSkip(true), WithSkipReason("Local package")))
+		default:
+			libs = append(libs, NewLibInfo(name, WithOthers([]string{name, pkg.Version})))
+		}
+	}
+
+	return libs, nil
+}
+
+func (p PythonParser) readPoetryLock(filePath string) (*PoetryLock, error) {
+	bodyBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	var lock PoetryLock
+
+	err = toml.Unmarshal(bodyBytes, &lock)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return &lock, nil
+}
+
+// readPoetryLockVersions returns the locked version of each package, or an empty map without a lockfile.
+func (p PythonParser) readPoetryLockVersions(lockFilePath string) map[string]string {
+	versions := map[string]string{}
+
+	if _, err := os.Stat(lockFilePath); err != nil {
+		return versions
+	}
+
+	lock, err := p.readPoetryLock(lockFilePath)
+	if err != nil {
+		utils.StdErrorPrintln("%v", err)
+
+		return versions
+	}
+
+	utils.DebugPrintln("Reading lockfile: " + lockFilePath)
+
+	for _, pkg := range lock.Packages {
+		versions[normalizePythonName(pkg.Name)] = pkg.Version
+	}
+
+	return versions
+}
+
+func (p PythonParser) getGitHubRepositoryURL(client *http.Client, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
+	defer cancel()
+
+	baseURL := "https://pypi.org/pypi/"
+	repoURL := baseURL + name + "/json"
+	utils.DebugPrintln("Fetching: " + repoURL)
+
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", ErrNotAGitHubRepository
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", ErrFailedToReadResponseBody
+	}
+
+	var repo PyPIRepository
+
+	err = json.Unmarshal(bodyBytes, &repo)
+	if err != nil {
+		return "", ErrFailedToUnmarshalJSON
+	}
+
+	return pickPyPIRepositoryURL(p.GitHubHosts, repo)
+}
+
+// pickPyPIRepositoryURL takes the repository from the project URLs labeled as its source, then
+// from the home page. Other labels such as Funding or Documentation may point to GitHub pages
+// that are not the repository, e.g. https://github.com/sponsors/someone, and are not read.
+// Without a repository the first candidate tells why.
+func pickPyPIRepositoryURL(hosts *utils.GitHubHosts, repo PyPIRepository) (string, error) {
+	var candidates []string
+
+	for _, key := range pypiSourceURLKeys {
+		for label, value := range repo.Info.ProjectURLs {
+			if strings.EqualFold(label, key) {
+				candidates = append(candidates, value)
+			}
+		}
+	}
+
+	candidates = append(candidates, repo.Info.HomePage)
+
+	var firstErr error
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+
+		repoURL, err := hostedRepository(hosts, candidate)
+		if err == nil {
+			return repoURL, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	return "", ErrNotAGitHubRepository
+}
+
+// normalizePythonName applies the PEP 503 name normalization used by PyPI.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparator.ReplaceAllString(name, "-"))
+}
+
+func stripExtras(name string) string {
+	if idx := strings.Index(name, "["); idx >= 0 {
+		return name[:idx]
+	}
+
+	return name
+}
@@ -0,0 +1,161 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+func TestPythonParser_Parse_Requirements(t *testing.T) {
+	t.Parallel()
+
+	requirements := `# main requirements
+-r base.txt
+--index-url https://pypi.org/simple
+Django>=4.2,<5.0 ; python_version >= "3.10"
+requests[security,socks]==2.31.0  # pinned
+Flask_Login == 0.6.3 \
+    --hash=sha256:deadbeef
+-e git+https://github.com/someone/editable-lib.git@v1.0#egg=Editable_Lib
+-e ./local/package
+mylib @ git+https://github.com/someone/mylib.git@main
+other @ https://example.com/other-1.0.tar.gz
+`
+	base := `-r requirements.txt
+numpy
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte(requirements), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.txt"), []byte(base), 0o600))
+
+	p := parser.PythonParser{}
+
+	libs, err := p.Parse(filepath.Join(dir, "requirements.txt"))
+	require.NoError(t, err)
+	require.Len(t, libs, 8)
+
+	assert.Equal(t, []string{"numpy", ""}, libs[0].Others)
+	assert.Equal(t, []string{"django", ">=4.2,<5.0"}, libs[1].Others)
+	assert.Equal(t, []string{"requests", "2.31.0"}, libs[2].Others)
+	assert.Equal(t, []string{"flask-login", "0.6.3"}, libs[3].Others)
+
+	assert.Equal(t, "editable-lib", libs[4].Name)
+	assert.Equal(t, "https://github.com/someone/editable-lib", libs[4].RepositoryURL)
+	assert.Equal(t, []string{"editable-lib", "v1.0"}, libs[4].Others)
+
+	assert.True(t, libs[5].Skip)
+	assert.Equal(t, "Local package", libs[5].SkipReason)
+
+	assert.Equal(t, "mylib", libs[6].Name)
+	assert.Equal(t, "https://github.com/someone/mylib", libs[6].RepositoryURL)
+
+	assert.Equal(t, "other", libs[7].Name)
+	assert.True(t, libs[7].Skip)
+	assert.Equal(t, "Unsupported repository host: example.com", libs[7].SkipReason)
+}
+
+func TestPythonParser_Parse_PyprojectWithPoetryLock(t *testing.T) {
+	t.Parallel()
+
+	pyproject := `[project]
+name = "demo"
+dependencies = [
+  "httpx>=0.27",
+  "Pydantic[email]~=2.0",
+]
+`
+	poetryLock := `[[package]]
+name = "httpx"
+version = "0.27.2"
+
+[[package]]
+name = "pydantic"
+version = "2.9.1"
+
+[[package]]
+name = "forked"
+version = "1.0.0"
+
+[package.source]
+type = "git"
+url = "https://github.com/someone/forked.git"
+reference = "main"
+
+[[package]]
+name = "local-lib"
+version = "0.1.0"
+
+[package.source]
+type = "directory"
+url = "../local-lib"
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte(pyproject), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "poetry.lock"), []byte(poetryLock), 0o600))
+
+	p := parser.PythonParser{}
+
+	libs, err := p.Parse(filepath.Join(dir, "pyproject.toml"))
+	require.NoError(t, err)
+	require.Len(t, libs, 2)
+	assert.Equal(t, []string{"httpx", "0.27.2"}, libs[0].Others)
+	assert.Equal(t, []string{"pydantic", "2.9.1"}, libs[1].Others)
+
+	libs, err = p.Parse(filepath.Join(dir, "poetry.lock"))
+	require.NoError(t, err)
+	require.Len(t, libs, 4)
+	assert.Equal(t, "forked", libs[2].Name)
+	assert.Equal(t, "https://github.com/someone/forked", libs[2].RepositoryURL)
+	assert.Equal(t, []string{"forked", "1.0.0"}, libs[2].Others)
+	assert.True(t, libs[3].Skip)
+	assert.Equal(t, "Local package", libs[3].SkipReason)
+}
+
+//nolint:paralleltest // Uses httpmock which doesn't support parallel tests
+func TestPythonParser_GetRepositoryURL(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://pypi.org/pypi/requests/json",
+		httpmock.NewStringResponder(200, `{"info": {"project_urls": {`+
+			`"Documentation": "https://requests.readthedocs.io", `+
+			`"Source": "https://github.com/psf/requests/tree/main"}, "home_page": "https://requests.readthedocs.io"}}`))
+
+	httpmock.RegisterResponder("GET", "https://pypi.org/pypi/numpy/json",
+		httpmock.NewStringResponder(200, `{"info": {"project_urls": null, "home_page": "https://github.com/numpy/numpy"}}`))
+
+	httpmock.RegisterResponder("GET", "https://pypi.org/pypi/elsewhere/json",
+		httpmock.NewStringResponder(200, `{"info": {"project_urls": {"Homepage": "https://gitlab.com/x/elsewhere"}}}`))
+
+	// neither the sponsors page nor the organization is a repository
+	httpmock.RegisterResponder("GET", "https://pypi.org/pypi/sponsored/json",
+		httpmock.NewStringResponder(200, `{"info": {"project_urls": {`+
+			`"Funding": "https://github.com/sponsors/someone", "Documentation": "https://github.com/org"}, `+
+			`"home_page": "https://github.com/org"}}`))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("requests", parser.WithOthers([]string{"requests", "2.31.0"})),
+		parser.NewLibInfo("numpy", parser.WithOthers([]string{"numpy", ""})),
+		parser.NewLibInfo("elsewhere", parser.WithOthers([]string{"elsewhere", ""})),
+		parser.NewLibInfo("sponsored", parser.WithOthers([]string{"sponsored", ""})),
+	}
+
+	p := parser.PythonParser{}
+	updated := p.GetRepositoryURL(libs)
+
+	assert.Equal(t, "https://github.com/psf/requests", updated[0].RepositoryURL)
+	assert.Equal(t, "https://github.com/numpy/numpy", updated[1].RepositoryURL)
+	assert.False(t, updated[2].Skip, updated[2].SkipReason)
+	assert.Equal(t, "https://gitlab.com/x/elsewhere", updated[2].RepositoryURL)
+	assert.True(t, updated[3].Skip)
+	assert.Empty(t, updated[3].RepositoryURL)
+	assert.Equal(t, "Repository not found", updated[3].SkipReason)
+}
@@ -16,7 +16,15 @@ import (
 	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
-type RubyParser struct{}
+// rubyGemsHost is the default gem server, whose gems are looked up by name.
+const rubyGemsHost = "rubygems.org"
+
+type RubyParser struct {
+	IncludeIndirect bool               // Gemfile.lock の推移的依存も含める
+	Concurrency     int                // rubygems.orgへの同時リクエスト数
+	Cache           *utils.Cache       // rubygems.orgの応答キャッシュ (nilなら無効)
+	GitHubHosts     *utils.GitHubHosts // github.com以外のGitHub Enterpriseのホスト
+}
 
 type RubyRepository struct {
 	SourceCodeURI string `json:"source_code_uri"`
@@ -25,6 +33,10 @@ type RubyRepository struct {
 
 // Parse メソッド
 func (p RubyParser) Parse(filePath string) ([]LibInfo, error) {
+	if isGemfileLock(filePath) {
+		return p.lockParser().Parse(filePath)
+	}
+
 	lines, err := p.readLines(filePath)
 	if err != nil {
 		return nil, err
@@ -32,56 +44,61 @@ func (p RubyParser) Parse(filePath string) ([]LibInfo, error) {
 
 	var libs []LibInfo
 
-	inOtherBlock := false
+	blockSource := "" // platforms and install_if blocks keep the default source
 
 	for _, line := range lines {
 		if p.isOtherBlockStart(line) {
-			inOtherBlock = true
+			blockSource = p.extractBlockSource(line)
 
 			continue
 		}
 
 		if p.isBlockEnd(line) {
-			inOtherBlock = false
+			blockSource = ""
 
 			continue
 		}
 
 		// gem を解析
 		if gemName := p.extractGemName(line); gemName != "" {
-			isNgGem := p.containsInvalidKeywords(line)
-			lib := p.createLibInfo(gemName, isNgGem, inOtherBlock)
-			libs = append(libs, lib)
+			libs = append(libs, p.createLibInfo(gemName, line, blockSource))
 		}
 	}
 
 	return libs, nil
 }
 
+func (p RubyParser) lockParser() GemfileLockParser {
+	return GemfileLockParser{
+		IncludeIndirect: p.IncludeIndirect, Concurrency: p.Concurrency,
+		Cache: p.Cache, GitHubHosts: p.GitHubHosts,
+	}
+}
+
 func (p RubyParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
-	client := &http.Client{}
+	client := p.Cache.Client(utils.CacheSourceRubyGems)
 
-	for i := range libInfoList {
+	_ = utils.ForEachIndex(context.Background(), p.Concurrency, len(libInfoList), func(i int) {
 		// ポインタを取得
 		libInfo := &libInfoList[i]
 		name := libInfo.Name
 
-		if libInfo.Skip {
-			continue
+		if libInfo.Skip || libInfo.RepositoryURL != "" {
+			return
 		}
 
 		repoURL, err := p.getGitHubRepositoryURL(client, name)
 		if err != nil {
 			libInfo.Skip = true
-			libInfo.SkipReason = "Does not support libraries hosted outside of Github"
+			libInfo.SkipReason = unresolvedReason(err)
 
-			utils.StdErrorPrintln("%s does not support libraries hosted outside of Github: %s", name, err)
+			utils.StdErrorPrintln("%s: repository not resolved: %s", name, err)
 
-			continue
+			return
 		}
 
 		libInfo.RepositoryURL = repoURL
-	}
+	})
 
 	return libInfoList
 }
@@ -120,6 +137,17 @@ func (p RubyParser) isOtherBlockStart(line string) bool {
 		installIfStartRegex.MatchString(line)
 }
 
+// source ブロックのgemサーバーを抽出
+func (p RubyParser) extractBlockSource(line string) string {
+	sourceStartRegex := regexp.MustCompile(`source\s+['"](.+)['"]\s+do`)
+
+	if matches := sourceStartRegex.FindStringSubmatch(line); matches != nil {
+		return matches[1]
+	}
+
+	return ""
+}
+
 // ブロックの終了か判定
 func (p RubyParser) isBlockEnd(line string) bool {
 	endRegex := regexp.MustCompile(`^end$`)
@@ -138,34 +166,50 @@ func (p RubyParser) extractGemName(line string) string {
 	return ""
 }
 
-func (p RubyParser) containsInvalidKeywords(line string) bool {
-	// カンマ区切りで分割
-	parts := strings.Split(line, ",")
-
-	// 判定するキーワード
-	ngKeywords := []string{"source", "git", "github"}
+// gemOption reads the git, github or source option of a gem line, e.g. git: "https://..."
+// or :source => "https://...".
+func (p RubyParser) gemOption(line string) (string, string, bool) {
+	optionRegex := regexp.MustCompile(`[\s,]:?(github|git|source):?\s*(?:=>\s*)?['"]([^'"]+)['"]`)
 
-	// 2番目以降をチェック
-	for _, part := range parts[1:] {
-		trimmedPart := strings.TrimSpace(part)
-		for _, keyword := range ngKeywords {
-			if strings.Contains(trimmedPart, keyword) {
-				return true
-			}
-		}
+	if matches := optionRegex.FindStringSubmatch(line); matches != nil {
+		return matches[1], matches[2], true
 	}
 
-	return false
+	return "", "", false
 }
 
-func (p RubyParser) createLibInfo(gemName string, isNgGem bool, inOtherBlock bool) LibInfo {
+// createLibInfo follows the gem to where it comes from: a gem from git is analyzed in its
+// repository, like in a Gemfile.lock, and a gem from another gem server than rubygems.org is
+// skipped with the host of the server. blockSource is the server of an enclosing source block.
+func (p RubyParser) createLibInfo(gemName string, line string, blockSource string) LibInfo {
 	lib := LibInfo{Name: gemName}
-	if isNgGem {
-		lib.Skip = true
-		lib.SkipReason = "Not hosted on Github"
-	} else if inOtherBlock {
+
+	option, value, found := p.gemOption(line)
+	if !found {
+		option, value = "source", blockSource
+	}
+
+	if option == "github" {
+		// github: "rails" is short for rails/rails
+		if !strings.Contains(value, "/") {
+			value += "/" + value
+		}
+
+		option, value = "git", "https://"+utils.PublicGitHubHost+"/"+value
+	}
+
+	switch {
+	case option == "git":
+		repoURL, err := hostedRepository(p.GitHubHosts, value)
+		if err != nil {
+			lib.Skip = true
+			lib.SkipReason = unresolvedReason(err)
+		} else {
+			lib.RepositoryURL = repoURL
+		}
+	case value != "" && !utils.OnHost(value, rubyGemsHost):
 		lib.Skip = true
-		lib.SkipReason = "Not hosted on Github"
+		lib.SkipReason = unresolvedReason(unsupportedHost(value))
 	}
 
 	return lib
@@ -191,7 +235,7 @@ func (p RubyParser) getGitHubRepositoryURL(client *http.Client, name string) (st
 
 	response, err := client.Do(req)
 	if err != nil {
-		return "", ErrFailedToGetRepository
+		return "", fmt.Errorf("%w: %w", ErrFailedToGetRepository, err)
 	}
 	defer response.Body.Close()
 
@@ -217,9 +261,9 @@ func (p RubyParser) getGitHubRepositoryURL(client *http.Client, name string) (st
 		repoURLfromRubyGems = repo.HomepageURI
 	}
 
-	if repoURLfromRubyGems == "" || !strings.Contains(repoURLfromRubyGems, "github.com") {
+	if repoURLfromRubyGems == "" {
 		return "", ErrNotAGitHubRepository
 	}
 
-	return repoURLfromRubyGems, nil
+	return hostedRepository(p.GitHubHosts, repoURLfromRubyGems)
 }
@@ -11,11 +11,15 @@ import (
 )
 
 //nolint:paralleltest // Uses file I/O which may conflict in parallel
-func TestRubyParser_Parse_SkipsGemsInBlocks(t *testing.T) {
+func TestRubyParser_Parse_FollowsGemSources(t *testing.T) {
 	content := `source "https://rubygems.org" do
   gem 'rails'
 end
 
+source "https://gems.example.com" do
+  gem 'private_gem'
+end
+
 platforms :jruby do
   gem 'jruby-openssl'
 end
@@ -24,7 +28,9 @@ install_if -> { true } do
   gem 'pg'
 end
 
-gem 'puma'
+gem 'puma', github: 'puma/puma'
+gem 'gitlab', git: 'https://gitlab.com/NARKOZ/gitlab.git', branch: 'main'
+gem 'internal', source: 'https://gems.example.com'
 `
 
 	tmpFile, err := os.CreateTemp(t.TempDir(), "Gemfile-*.tmp")
@@ -48,25 +54,32 @@ gem 'puma'
 		t.Fatal(err)
 	}
 
-	// Expect 4 gems listed in order encountered
-	assert.Len(t, libs, 4)
+	// Expect 7 gems listed in order encountered
+	assert.Len(t, libs, 7)
 
-	// First three are inside blocks → skipped
-	assert.Equal(t, "rails", libs[0].Name)
-	assert.True(t, libs[0].Skip)
-	assert.Equal(t, "Not hosted on Github", libs[0].SkipReason)
+	// rubygems.org, platforms and install_if blocks → looked up on rubygems.org
+	for _, i := range []int{0, 2, 3} {
+		assert.False(t, libs[i].Skip, libs[i].Name)
+		assert.Empty(t, libs[i].RepositoryURL, libs[i].Name)
+	}
 
-	assert.Equal(t, "jruby-openssl", libs[1].Name)
+	// Another gem server → skipped with its host
+	assert.Equal(t, "private_gem", libs[1].Name)
 	assert.True(t, libs[1].Skip)
-	assert.Equal(t, "Not hosted on Github", libs[1].SkipReason)
+	assert.Equal(t, "Unsupported repository host: gems.example.com", libs[1].SkipReason)
+
+	// Gems from git → analyzed in their repository
+	assert.Equal(t, "puma", libs[4].Name)
+	assert.False(t, libs[4].Skip)
+	assert.Equal(t, "https://github.com/puma/puma", libs[4].RepositoryURL)
 
-	assert.Equal(t, "pg", libs[2].Name)
-	assert.True(t, libs[2].Skip)
-	assert.Equal(t, "Not hosted on Github", libs[2].SkipReason)
+	assert.Equal(t, "gitlab", libs[5].Name)
+	assert.False(t, libs[5].Skip)
+	assert.Equal(t, "https://gitlab.com/NARKOZ/gitlab", libs[5].RepositoryURL)
 
-	// Outside blocks → not skipped
-	assert.Equal(t, "puma", libs[3].Name)
-	assert.False(t, libs[3].Skip)
+	assert.Equal(t, "internal", libs[6].Name)
+	assert.True(t, libs[6].Skip)
+	assert.Equal(t, "Unsupported repository host: gems.example.com", libs[6].SkipReason)
 }
 
 //nolint:paralleltest // Uses httpmock which doesn't support parallel tests
@@ -88,6 +101,6 @@ func TestRubyParser_GetRepositoryURL_NonGitHubHomepageSkips(t *testing.T) {
 	updated := p.GetRepositoryURL(libs)
 
 	assert.True(t, updated[0].Skip)
-	assert.Equal(t, "Does not support libraries hosted outside of Github", updated[0].SkipReason)
+	assert.Equal(t, "Unsupported repository host: example.com", updated[0].SkipReason)
 	assert.Empty(t, updated[0].RepositoryURL)
 }
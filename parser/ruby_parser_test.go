@@ -2,12 +2,15 @@ package parser_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/uzumaki-inc/stay_or_go/parser"
+	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
 func TestRubyParser_Parse(t *testing.T) {
@@ -44,7 +47,7 @@ gem 'puma'`
 	assert.False(t, libInfoList[0].Skip)
 	assert.Equal(t, "nokogiri", libInfoList[1].Name)
 	assert.True(t, libInfoList[1].Skip)
-	assert.Equal(t, "Not hosted on Github", libInfoList[1].SkipReason)
+	assert.Equal(t, "Unsupported repository host: self_hosting_git.com", libInfoList[1].SkipReason)
 	assert.Equal(t, "puma", libInfoList[2].Name)
 	assert.False(t, libInfoList[2].Skip)
 }
@@ -65,11 +68,15 @@ func TestRubyParser_GetRepositoryURL(t *testing.T) {
 	httpmock.RegisterResponder("GET", "https://rubygems.org/api/v1/gems/puma.json",
 		httpmock.NewStringResponder(200, `{"source_code_uri": ""}`))
 
+	httpmock.RegisterResponder("GET", "https://rubygems.org/api/v1/gems/gitlab.json",
+		httpmock.NewStringResponder(200, `{"source_code_uri": "https://gitlab.com/NARKOZ/gitlab/-/tree/v4.19.0"}`))
+
 	// Create initial LibInfo list
 	libInfoList := []parser.LibInfo{
 		{Name: "rails"},
 		{Name: "nokogiri"},
 		{Name: "puma"},
+		{Name: "gitlab"},
 	}
 
 	// Run GetRepositoryURL method
@@ -81,5 +88,54 @@ func TestRubyParser_GetRepositoryURL(t *testing.T) {
 	assert.Equal(t, "https://github.com/sparklemotion/nokogiri", updatedLibInfoList[1].RepositoryURL)
 	assert.Empty(t, updatedLibInfoList[2].RepositoryURL)
 	assert.True(t, updatedLibInfoList[2].Skip)
-	assert.Equal(t, "Does not support libraries hosted outside of Github", updatedLibInfoList[2].SkipReason)
+	assert.Equal(t, "Repository not found", updatedLibInfoList[2].SkipReason)
+	assert.Equal(t, "https://gitlab.com/NARKOZ/gitlab", updatedLibInfoList[3].RepositoryURL)
+}
+
+//nolint:paralleltest // Uses httpmock which doesn't support parallel tests
+func TestRubyParser_GetRepositoryURL_OtherForges(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://rubygems.org/api/v1/gems/bb.json",
+		httpmock.NewStringResponder(200, `{"homepage_uri": "https://bitbucket.org/team/bb"}`))
+	httpmock.RegisterResponder("GET", "https://rubygems.org/api/v1/gems/cb.json",
+		httpmock.NewStringResponder(200, `{"source_code_uri": "https://codeberg.org/team/cb"}`))
+
+	updated := parser.RubyParser{}.GetRepositoryURL([]parser.LibInfo{{Name: "bb"}, {Name: "cb"}})
+
+	assert.Equal(t, "https://bitbucket.org/team/bb", updated[0].RepositoryURL)
+	assert.Equal(t, "https://codeberg.org/team/cb", updated[1].RepositoryURL)
+}
+
+func TestRubyParser_GetRepositoryURL_Offline(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://rubygems.org/api/v1/gems/rails.json",
+		httpmock.NewStringResponder(200, `{"source_code_uri": "https://github.com/rails/rails"}`))
+
+	// export a snapshot online
+	snapshot := filepath.Join(t.TempDir(), "snapshot.json")
+	online := utils.NewCache("", utils.DefaultCacheTTLs())
+	online.Record()
+	parser.RubyParser{Cache: online}.GetRepositoryURL([]parser.LibInfo{{Name: "rails"}})
+
+	_, err := online.WriteSnapshot(snapshot)
+	require.NoError(t, err)
+
+	offline, err := utils.LoadSnapshot(snapshot)
+	require.NoError(t, err)
+
+	httpmock.Reset()
+
+	libInfoList := parser.RubyParser{Cache: offline}.GetRepositoryURL([]parser.LibInfo{
+		{Name: "rails"},
+		{Name: "nokogiri"},
+	})
+
+	assert.Equal(t, "https://github.com/rails/rails", libInfoList[0].RepositoryURL)
+	assert.True(t, libInfoList[1].Skip)
+	assert.Equal(t, "Not in offline snapshot", libInfoList[1].SkipReason)
+	assert.Zero(t, httpmock.GetTotalCallCount())
 }
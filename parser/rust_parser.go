@@ -0,0 +1,380 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+const (
+	cargoManifestFileName = "Cargo.toml"
+	cargoLockFileName     = "Cargo.lock"
+	cratesIOUserAgent     = "stay_or_go (https://github.com/uzumaki-inc/stay_or_go)"
+)
+
+type RustParser struct {
+	Concurrency int                // crates.ioへの同時リクエスト数
+	GitHubHosts *utils.GitHubHosts // github.com以外のGitHub Enterpriseのホスト
+}
+
+type CargoManifest struct {
+	Dependencies      map[string]any         `toml:"dependencies"`
+	DevDependencies   map[string]any         `toml:"dev-dependencies"`
+	BuildDependencies map[string]any         `toml:"build-dependencies"`
+	Target            map[string]CargoTarget `toml:"target"`
+	Workspace         *CargoWorkspace        `toml:"workspace"`
+}
+
+type CargoTarget struct {
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+}
+
+type CargoWorkspace struct {
+	Members      []string       `toml:"members"`
+	Exclude      []string       `toml:"exclude"`
+	Dependencies map[string]any `toml:"dependencies"`
+}
+
+type CargoLock struct {
+	Packages []CargoLockPackage `toml:"package"`
+}
+
+type CargoLockPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Source  string `toml:"source"`
+}
+
+type CratesIORepository struct {
+	Crate struct {
+		Repository string `json:"repository"`
+		Homepage   string `json:"homepage"`
+	} `json:"crate"`
+}
+
+// cargoDependency is a dependency entry, which Cargo accepts either as
+// a version string or as an inline table.
+type cargoDependency struct {
+	Version   string
+	Path      string
+	Git       string
+	Package   string
+	Workspace bool
+}
+
+func (p RustParser) Parse(filePath string) ([]LibInfo, error) {
+	rootManifest, err := p.readManifest(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rootDir := filepath.Dir(filePath)
+	manifests := []*CargoManifest{rootManifest}
+
+	var workspaceDeps map[string]any
+
+	if rootManifest.Workspace != nil {
+		workspaceDeps = rootManifest.Workspace.Dependencies
+
+		members, err := p.readWorkspaceMembers(rootDir, rootManifest.Workspace)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, members...)
+	}
+
+	lockedVersions := p.readLockedVersions(filepath.Join(rootDir, cargoLockFileName))
+
+	var libs []LibInfo
+
+	for _, manifest := range manifests {
+		for _, table := range manifest.dependencyTables() {
+			for _, name := range slices.Sorted(maps.Keys(table)) {
+				dependency := toCargoDependency(table[name])
+				if dependency.Workspace {
+					dependency = toCargoDependency(workspaceDeps[name])
+				}
+
+				libs = append(libs, p.createLibInfo(name, dependency, lockedVersions))
+			}
+		}
+	}
+
+	return dedupeLibInfos(libs), nil
+}
+
+func (p RustParser) GetRepositoryURL(libInfoList []LibInfo) []LibInfo {
+	client := &http.Client{}
+
+	_ = utils.ForEachIndex(context.Background(), p.Concurrency, len(libInfoList), func(i int) {
+		libInfo := &libInfoList[i]
+
+		if libInfo.Skip || libInfo.RepositoryURL != "" {
+			return
+		}
+
+		name := libInfo.Others[0]
+
+		repoURL, err := p.getGitHubRepositoryURL(client, name)
+		if err != nil {
+			libInfo.Skip = true
+			libInfo.SkipReason = unresolvedReason(err)
+
+			utils.StdErrorPrintln("%s: repository not resolved: %s", name, err)
+
+			return
+		}
+
+		libInfo.RepositoryURL = repoURL
+	})
+
+	return libInfoList
+}
+
+// dependencyTables returns [dependencies], [dev-dependencies], [build-dependencies]
+// followed by the same tables of every [target.'cfg(...)'] section.
+func (m *CargoManifest) dependencyTables() []map[string]any {
+	tables := []map[string]any{m.Dependencies, m.DevDependencies, m.BuildDependencies}
+
+	for _, target := range slices.Sorted(maps.Keys(m.Target)) {
+		tables = append(tables,
+			m.Target[target].Dependencies,
+			m.Target[target].DevDependencies,
+			m.Target[target].BuildDependencies,
+		)
+	}
+
+	return tables
+}
+
+func (p RustParser) readManifest(filePath string) (*CargoManifest, error) {
+	bodyBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFiledToOpenFile, err)
+	}
+
+	var manifest CargoManifest
+
+	err = toml.Unmarshal(bodyBytes, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFailedToReadFile, filePath, err)
+	}
+
+	return &manifest, nil
+}
+
+// readWorkspaceMembers expands the member globs of [workspace] and reads each member's Cargo.toml.
+func (p RustParser) readWorkspaceMembers(rootDir string, workspace *CargoWorkspace) ([]*CargoManifest, error) {
+	excluded := map[string]bool{}
+	for _, exclude := range workspace.Exclude {
+		excluded[filepath.Clean(filepath.Join(rootDir, exclude))] = true
+	}
+
+	var manifests []*CargoManifest
+
+	for _, member := range workspace.Members {
+		dirs, err := filepath.Glob(filepath.Join(rootDir, member))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFailedToReadFile, member, err)
+		}
+
+		for _, dir := range dirs {
+			if excluded[filepath.Clean(dir)] || filepath.Clean(dir) == filepath.Clean(rootDir) {
+				continue
+			}
+
+			manifestPath := filepath.Join(dir, cargoManifestFileName)
+			if _, err := os.Stat(manifestPath); err != nil {
+				continue
+			}
+
+			utils.DebugPrintln("Reading workspace member: " + manifestPath)
+
+			manifest, err := p.readManifest(manifestPath)
+			if err != nil {
+				return nil, err
+			}
+
+			manifests = append(manifests, manifest)
+		}
+	}
+
+	return manifests, nil
+}
+
+// readLockedVersions returns every locked version of each registry crate in Cargo.lock.
+// A missing lockfile is not an error; versions then come from Cargo.toml.
+func (p RustParser) readLockedVersions(lockFilePath string) map[string][]string {
+	versions := map[string][]string{}
+
+	bodyBytes, err := os.ReadFile(lockFilePath)
+	if err != nil {
+		return versions
+	}
+
+	var lock CargoLock
+
+	err = toml.Unmarshal(bodyBytes, &lock)
+	if err != nil {
+		utils.StdErrorPrintln("%v: %s: %v", ErrFailedToReadFile, lockFilePath, err)
+
+		return versions
+	}
+
+	utils.DebugPrintln("Reading lockfile: " + lockFilePath)
+
+	for _, pkg := range lock.Packages {
+		if pkg.Source == "" {
+			// Workspace and path crates have no source
+			continue
+		}
+
+		versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+	}
+
+	return versions
+}
+
+func (p RustParser) createLibInfo(name string, dependency cargoDependency, lockedVersions map[string][]string) LibInfo {
+	crateName := name
+	if dependency.Package != "" {
+		crateName = dependency.Package
+	}
+
+	switch {
+	case dependency.Path != "":
+		return NewLibInfo(name, WithSkip(true), WithSkipReason("Local path dependency ("+dependency.Path+")"))
+	case dependency.Git != "":
+		root, err := hostedRepository(p.GitHubHosts, strings.TrimPrefix(dependency.Git, "git+"))
+		if err != nil {
+			return NewLibInfo(name, WithSkip(true), WithSkipReason(unresolvedReason(err)))
+		}
+
+		lib := NewLibInfo(name, WithOthers([]string{crateName, dependency.Version}))
+		lib.RepositoryURL = root
+
+		return lib
+	default:
+		version := pickLockedVersion(dependency.Version, lockedVersions[crateName])
+
+		return NewLibInfo(name, WithOthers([]string{crateName, version}))
+	}
+}
+
+func (p RustParser) getGitHubRepositoryURL(client *http.Client, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOutSec*time.Second)
+	defer cancel()
+
+	baseURL := "https://crates.io/api/v1/crates/"
+	repoURL := baseURL + name
+	utils.DebugPrintln("Fetching: " + repoURL)
+
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+
+	// crates.io rejects requests without a User-Agent
+	req.Header.Set("User-Agent", cratesIOUserAgent)
+
+	response, err := client.Do(req)
+	if err != nil {
+		return "", ErrFailedToGetRepository
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", ErrNotAGitHubRepository
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", ErrFailedToReadResponseBody
+	}
+
+	var repo CratesIORepository
+
+	err = json.Unmarshal(bodyBytes, &repo)
+	if err != nil {
+		return "", ErrFailedToUnmarshalJSON
+	}
+
+	repoURLfromCratesIO := repo.Crate.Repository
+
+	if repoURLfromCratesIO == "" {
+		repoURLfromCratesIO = repo.Crate.Homepage
+	}
+
+	if repoURLfromCratesIO == "" {
+		return "", ErrNotAGitHubRepository
+	}
+
+	return hostedRepository(p.GitHubHosts, repoURLfromCratesIO)
+}
+
+func toCargoDependency(value any) cargoDependency {
+	switch spec := value.(type) {
+	case string:
+		return cargoDependency{Version: spec}
+	case map[string]any:
+		dependency := cargoDependency{}
+		dependency.Version, _ = spec["version"].(string)
+		dependency.Path, _ = spec["path"].(string)
+		dependency.Git, _ = spec["git"].(string)
+		dependency.Package, _ = spec["package"].(string)
+		dependency.Workspace, _ = spec["workspace"].(bool)
+
+		return dependency
+	default:
+		return cargoDependency{}
+	}
+}
+
+// pickLockedVersion chooses the Cargo.lock version that matches the requirement.
+// When a crate is locked at several versions, the one compatible under Cargo's
+// caret rules (same major, or same minor for 0.x) wins; without a lock entry
+// the requirement is kept.
+func pickLockedVersion(requirement string, locked []string) string {
+	switch len(locked) {
+	case 0:
+		return requirement
+	case 1:
+		return locked[0]
+	}
+
+	parts := strings.Split(strings.TrimLeft(requirement, "^~=<> "), ".")
+
+	prefix := parts[0] + "."
+	if parts[0] == "0" && len(parts) > 1 {
+		prefix = "0." + parts[1] + "."
+	}
+
+	for _, version := range locked {
+		if strings.HasPrefix(version, prefix) {
+			return version
+		}
+	}
+
+	return locked[0]
+}
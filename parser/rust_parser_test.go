@@ -0,0 +1,151 @@
+package parser_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+//nolint:funlen // Workspace fixture needs several files
+func TestRustParser_Parse_WorkspaceWithLockfile(t *testing.T) {
+	t.Parallel()
+
+	rootManifest := `[workspace]
+members = ["crates/*"]
+exclude = ["crates/ignored"]
+
+[workspace.dependencies]
+tokio = { version = "1", features = ["full"] }
+
+[dependencies]
+serde = "1.0"
+rand_core = { package = "rand", version = "0.8" }
+fork = { git = "https://github.com/someone/fork.git", branch = "main" }
+mirrored = { git = "https://codeberg.org/someone/mirrored.git" }
+private = { git = "https://git.example.com/team/private" }
+vendored = { path = "vendor/vendored" }
+
+[dev-dependencies]
+criterion = "0.5"
+
+[build-dependencies]
+cc = "1"
+
+[target.'cfg(windows)'.dependencies]
+winapi = "0.3"
+`
+	member := `[package]
+name = "cli"
+
+[dependencies]
+tokio = { workspace = true }
+serde = "1.0"
+core = { path = "../core" }
+`
+	ignored := `[dependencies]
+ignored-dep = "1"
+`
+	lock := `version = 3
+
+[[package]]
+name = "serde"
+version = "1.0.210"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "rand"
+version = "0.7.3"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "rand"
+version = "0.8.5"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "tokio"
+version = "1.40.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "cli"
+version = "0.1.0"
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "crates", "cli"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "crates", "ignored"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(rootManifest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Cargo.lock"), []byte(lock), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crates", "cli", "Cargo.toml"), []byte(member), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crates", "ignored", "Cargo.toml"), []byte(ignored), 0o600))
+
+	p := parser.RustParser{}
+
+	libs, err := p.Parse(filepath.Join(dir, "Cargo.toml"))
+	require.NoError(t, err)
+
+	byName := map[string]parser.LibInfo{}
+	names := make([]string, 0, len(libs))
+
+	for _, lib := range libs {
+		byName[lib.Name] = lib
+		names = append(names, lib.Name)
+	}
+
+	assert.Equal(t, []string{
+		"fork", "mirrored", "private", "rand_core", "serde", "vendored", "criterion", "cc", "winapi", "core", "tokio",
+	}, names)
+
+	assert.Equal(t, []string{"serde", "1.0.210"}, byName["serde"].Others)
+	assert.Equal(t, []string{"rand", "0.8.5"}, byName["rand_core"].Others)
+	assert.Equal(t, []string{"tokio", "1.40.0"}, byName["tokio"].Others)
+	assert.Equal(t, []string{"criterion", "0.5"}, byName["criterion"].Others)
+
+	assert.Equal(t, "https://github.com/someone/fork", byName["fork"].RepositoryURL)
+	assert.Equal(t, "https://codeberg.org/someone/mirrored", byName["mirrored"].RepositoryURL)
+	assert.True(t, byName["private"].Skip)
+	assert.Equal(t, "Unsupported repository host: git.example.com", byName["private"].SkipReason)
+
+	assert.True(t, byName["vendored"].Skip)
+	assert.Equal(t, "Local path dependency (vendor/vendored)", byName["vendored"].SkipReason)
+	assert.True(t, byName["core"].Skip)
+	assert.Equal(t, "Local path dependency (../core)", byName["core"].SkipReason)
+}
+
+//nolint:paralleltest // Uses httpmock which doesn't support parallel tests
+func TestRustParser_GetRepositoryURL(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://crates.io/api/v1/crates/serde",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("User-Agent") == "" {
+				return httpmock.NewStringResponse(403, "missing user agent"), nil
+			}
+
+			return httpmock.NewStringResponse(200, `{"crate": {"repository": "https://github.com/serde-rs/serde"}}`), nil
+		})
+
+	httpmock.RegisterResponder("GET", "https://crates.io/api/v1/crates/elsewhere",
+		httpmock.NewStringResponder(200, `{"crate": {"repository": "https://gitlab.com/x/elsewhere"}}`))
+
+	libs := []parser.LibInfo{
+		parser.NewLibInfo("serde", parser.WithOthers([]string{"serde", "1.0.210"})),
+		parser.NewLibInfo("elsewhere", parser.WithOthers([]string{"elsewhere", "1"})),
+	}
+
+	p := parser.RustParser{}
+	updated := p.GetRepositoryURL(libs)
+
+	assert.Equal(t, "https://github.com/serde-rs/serde", updated[0].RepositoryURL)
+	assert.False(t, updated[1].Skip, updated[1].SkipReason)
+	assert.Equal(t, "https://gitlab.com/x/elsewhere", updated[1].RepositoryURL)
+}
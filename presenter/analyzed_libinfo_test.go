@@ -13,16 +13,18 @@ import (
 func TestAnalyzedLibInfo_EmptyLibInfo_NoRepoInfo(t *testing.T) {
 	t.Parallel()
 
-	info := presenter.AnalyzedLibInfo{LibInfo: &parser.LibInfo{Skip: true, SkipReason: "li"}, GitHubRepoInfo: nil}
+	info := presenter.AnalyzedLibInfo{LibInfo: &parser.LibInfo{Skip: true, SkipReason: "li"}, RepoInfo: nil}
 
 	assert.Nil(t, info.Name())
+	assert.Nil(t, info.UsedBy())
 	assert.Nil(t, info.RepositoryURL())
+	assert.Nil(t, info.Forge())
 	assert.Nil(t, info.Watchers())
 	assert.Nil(t, info.Stars())
 	assert.Nil(t, info.Forks())
 	assert.Nil(t, info.OpenIssues())
 	assert.Nil(t, info.LastCommitDate())
-	assert.Nil(t, info.GithubRepoURL())
+	assert.Nil(t, info.RepositoryURL())
 	assert.Nil(t, info.Archived())
 	assert.Nil(t, info.Score())
 
@@ -42,23 +44,30 @@ func TestAnalyzedLibInfo_EmptyLibInfo_NoRepoInfo(t *testing.T) {
 func TestAnalyzedLibInfo_WithValues_AllGetters(t *testing.T) {
 	t.Parallel()
 
-	lib := parser.LibInfo{Name: "lib", RepositoryURL: "https://github.com/x/y"}
-	repo := analyzer.GitHubRepoInfo{
-		Watchers:       1,
-		Stars:          2,
-		Forks:          3,
-		OpenIssues:     4,
-		LastCommitDate: "2024-01-01T00:00:00Z",
-		GithubRepoURL:  "https://github.com/x/y",
-		Archived:       true,
+	lib := parser.LibInfo{Name: "lib", RepositoryURL: "https://github.com/x/y", UsedBy: []string{"a", "b"}}
+	repo := analyzer.RepoInfo{
+		Watchers:       analyzer.Ptr(1),
+		Stars:          analyzer.Ptr(2),
+		Forks:          analyzer.Ptr(3),
+		OpenIssues:     analyzer.Ptr(4),
+		LastCommitDate: analyzer.Ptr("2024-01-01T00:00:00Z"),
+		RepositoryURL:  "https://github.com/x/y",
+		Forge:          analyzer.ForgeGitHub,
+		Archived:       analyzer.Ptr(true),
 		Score:          42,
 	}
-	info := presenter.AnalyzedLibInfo{LibInfo: &lib, GitHubRepoInfo: &repo}
+	info := presenter.AnalyzedLibInfo{LibInfo: &lib, RepoInfo: &repo}
 
 	assert.NotNil(t, info.Name())
 	assert.Equal(t, "lib", *info.Name())
+	assert.NotNil(t, info.Direct())
+	assert.True(t, *info.Direct())
+	assert.NotNil(t, info.UsedBy())
+	assert.Equal(t, "a b", *info.UsedBy())
 	assert.NotNil(t, info.RepositoryURL())
 	assert.Equal(t, "https://github.com/x/y", *info.RepositoryURL())
+	assert.NotNil(t, info.Forge())
+	assert.Equal(t, "GitHub", *info.Forge())
 	assert.NotNil(t, info.Watchers())
 	assert.Equal(t, 1, *info.Watchers())
 	assert.NotNil(t, info.Stars())
@@ -69,8 +78,8 @@ func TestAnalyzedLibInfo_WithValues_AllGetters(t *testing.T) {
 	assert.Equal(t, 4, *info.OpenIssues())
 	assert.NotNil(t, info.LastCommitDate())
 	assert.Equal(t, "2024-01-01T00:00:00Z", *info.LastCommitDate())
-	assert.NotNil(t, info.GithubRepoURL())
-	assert.Equal(t, "https://github.com/x/y", *info.GithubRepoURL())
+	assert.NotNil(t, info.RepositoryURL())
+	assert.Equal(t, "https://github.com/x/y", *info.RepositoryURL())
 	assert.NotNil(t, info.Archived())
 	assert.True(t, *info.Archived())
 	assert.NotNil(t, info.Score())
@@ -84,8 +93,8 @@ func TestAnalyzedLibInfo_SkipReason_FromLibInfo(t *testing.T) {
 	t.Parallel()
 
 	lib := parser.LibInfo{Name: "lib3", Skip: true, SkipReason: "reason"}
-	repo := analyzer.GitHubRepoInfo{Score: 1}
-	info := presenter.AnalyzedLibInfo{LibInfo: &lib, GitHubRepoInfo: &repo}
+	repo := analyzer.RepoInfo{Score: 1}
+	info := presenter.AnalyzedLibInfo{LibInfo: &lib, RepoInfo: &repo}
 
 	if v := info.Skip(); v == nil {
 		t.Fatalf("skip nil")
@@ -104,8 +113,8 @@ func TestAnalyzedLibInfo_SkipReason_FromRepoInfo(t *testing.T) {
 	t.Parallel()
 
 	lib := parser.LibInfo{Name: "lib"}
-	repo := analyzer.GitHubRepoInfo{Skip: true, SkipReason: "repo-reason"}
-	info := presenter.AnalyzedLibInfo{LibInfo: &lib, GitHubRepoInfo: &repo}
+	repo := analyzer.RepoInfo{Skip: true, SkipReason: "repo-reason"}
+	info := presenter.AnalyzedLibInfo{LibInfo: &lib, RepoInfo: &repo}
 
 	if v := info.Skip(); v == nil {
 		t.Fatalf("skip nil")
@@ -119,3 +128,22 @@ func TestAnalyzedLibInfo_SkipReason_FromRepoInfo(t *testing.T) {
 		assert.Equal(t, "repo-reason", *v)
 	}
 }
+
+func TestAnalyzedLibInfo_UnavailableMetricsAreNil(t *testing.T) {
+	t.Parallel()
+
+	lib := parser.LibInfo{Name: "lib", RepositoryURL: "https://git.sr.ht/~x/y"}
+	repo := analyzer.RepoInfo{
+		LastCommitDate: analyzer.Ptr("2024-01-01T00:00:00Z"),
+		Forge:          analyzer.ForgeSourceHut,
+	}
+	info := presenter.AnalyzedLibInfo{LibInfo: &lib, RepoInfo: &repo}
+
+	assert.Nil(t, info.Watchers())
+	assert.Nil(t, info.Stars())
+	assert.Nil(t, info.Forks())
+	assert.Nil(t, info.OpenIssues())
+	assert.Nil(t, info.Archived())
+	assert.NotNil(t, info.LastCommitDate())
+	assert.Equal(t, "SourceHut", *info.Forge())
+}
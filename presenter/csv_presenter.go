@@ -6,10 +6,15 @@ import (
 
 type CsvPresenter struct {
 	analyzedLibInfos []AnalyzedLibInfo
+	headers          []string
 }
 
-func NewCsvPresenter(infos []AnalyzedLibInfo) CsvPresenter {
-	return CsvPresenter{analyzedLibInfos: infos}
+// NewCsvPresenter leaves out the sparkline of WithSparkline, which other programs cannot read.
+func NewCsvPresenter(infos []AnalyzedLibInfo, opts ...Option) CsvPresenter {
+	o := newOptions(opts)
+	o.sparkline = false
+
+	return CsvPresenter{analyzedLibInfos: infos, headers: o.headers()}
 }
 
 func (p CsvPresenter) Display() {
@@ -17,11 +22,11 @@ func (p CsvPresenter) Display() {
 }
 
 func (p CsvPresenter) makeHeader() []string {
-	headerRow := strings.Join(headerString, ", ")
+	headerRow := strings.Join(p.headers, ", ")
 
 	return []string{headerRow}
 }
 
 func (p CsvPresenter) makeBody() []string {
-	return makeBody(p.analyzedLibInfos, ", ")
+	return makeBody(p.analyzedLibInfos, p.headers, ", ")
 }
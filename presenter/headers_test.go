@@ -4,6 +4,11 @@ package presenter
 import (
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/parser"
 )
 
 func TestMarkdownPresenter_makeHeader(t *testing.T) {
@@ -54,3 +59,26 @@ func TestTsvPresenter_makeHeader(t *testing.T) {
 		t.Fatalf("expected tabs in header: %q", header[0])
 	}
 }
+
+func TestWithIncomplete_AddsColumnAfterScore(t *testing.T) {
+	t.Parallel()
+
+	infos := []AnalyzedLibInfo{
+		{
+			LibInfo:  &parser.LibInfo{Name: "lib"},
+			RepoInfo: &analyzer.RepoInfo{Score: 7, Incomplete: []string{"commit activity pending"}},
+		},
+		{LibInfo: &parser.LibInfo{Name: "other"}, RepoInfo: &analyzer.RepoInfo{Score: 9}},
+	}
+
+	markdown := SelectPresenter("markdown", infos, WithIncomplete(true))
+	assert.Contains(t, markdown.makeHeader()[0], "| Score | Incomplete | Skip |")
+	assert.Contains(t, markdown.makeBody()[0], "|N/A|commit activity pending|false|")
+	assert.Contains(t, markdown.makeBody()[1], "|9||false|")
+
+	csv := SelectPresenter("csv", infos, WithIncomplete(true))
+	assert.Contains(t, csv.makeHeader()[0], "Score, Incomplete, Skip")
+
+	plain := SelectPresenter("tsv", infos)
+	assert.NotContains(t, plain.makeHeader()[0], "Incomplete")
+}
@@ -6,10 +6,11 @@ import (
 
 type MarkdownPresenter struct {
 	analyzedLibInfos []AnalyzedLibInfo
+	headers          []string
 }
 
-func NewMarkdownPresenter(infos []AnalyzedLibInfo) MarkdownPresenter {
-	return MarkdownPresenter{analyzedLibInfos: infos}
+func NewMarkdownPresenter(infos []AnalyzedLibInfo, opts ...Option) MarkdownPresenter {
+	return MarkdownPresenter{analyzedLibInfos: infos, headers: newOptions(opts).headers()}
 }
 
 func (p MarkdownPresenter) Display() {
@@ -17,10 +18,10 @@ func (p MarkdownPresenter) Display() {
 }
 
 func (p MarkdownPresenter) makeHeader() []string {
-	headerRow := "| " + strings.Join(headerString, " | ") + " |"
+	headerRow := "| " + strings.Join(p.headers, " | ") + " |"
 
 	separatorRow := "|"
-	for _, header := range headerString {
+	for _, header := range p.headers {
 		separatorRow += " " + strings.Repeat("-", len(header)) + " |"
 	}
 
@@ -28,5 +29,5 @@ func (p MarkdownPresenter) makeHeader() []string {
 }
 
 func (p MarkdownPresenter) makeBody() []string {
-	return makeBody(p.analyzedLibInfos, "|")
+	return makeBody(p.analyzedLibInfos, p.headers, "|")
 }
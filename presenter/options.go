@@ -0,0 +1,84 @@
+package presenter
+
+import "slices"
+
+// options are the settings of the presenters, each showing optional columns.
+type options struct {
+	direct     bool
+	usedBy     bool
+	sparkline  bool
+	advisories bool
+	incomplete bool
+}
+
+type Option func(*options)
+
+// WithDirect adds a Direct column after Name, telling direct dependencies from the indirect
+// ones read with them.
+func WithDirect(direct bool) Option {
+	return func(o *options) {
+		o.direct = direct
+	}
+}
+
+// WithUsedBy adds a UsedBy column after Direct, listing the workspace modules that need each
+// dependency of a go.work.
+func WithUsedBy(usedBy bool) Option {
+	return func(o *options) {
+		o.usedBy = usedBy
+	}
+}
+
+// WithSparkline adds a CommitActivity column after ActiveWeeksLastYear, drawing the commits of the
+// last year as a sparkline. CSV is read by other programs and leaves it out.
+func WithSparkline(sparkline bool) Option {
+	return func(o *options) {
+		o.sparkline = sparkline
+	}
+}
+
+// WithAdvisories adds the UnfixedVulnerabilities and Advisories columns after Archived, for the
+// results of an advisory database.
+func WithAdvisories(advisories bool) Option {
+	return func(o *options) {
+		o.advisories = advisories
+	}
+}
+
+// WithIncomplete adds an Incomplete column after Score, telling which metrics were left unknown
+// this time, e.g. statistics GitHub was still computing, and so were left out of the score.
+func WithIncomplete(incomplete bool) Option {
+	return func(o *options) {
+		o.incomplete = incomplete
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		direct:     false,
+		usedBy:     false,
+		sparkline:  false,
+		advisories: false,
+		incomplete: false,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// headers are the columns shown: headerString without the optional columns whose option is off.
+func (o options) headers() []string {
+	hidden := map[string]bool{
+		"Direct":                 !o.direct,
+		"UsedBy":                 !o.usedBy,
+		"CommitActivity":         !o.sparkline,
+		"UnfixedVulnerabilities": !o.advisories,
+		"Advisories":             !o.advisories,
+		"Incomplete":             !o.incomplete,
+	}
+
+	return slices.DeleteFunc(slices.Clone(headerString), func(header string) bool { return hidden[header] })
+}
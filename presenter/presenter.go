@@ -4,15 +4,18 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 
+	"github.com/uzumaki-inc/stay_or_go/advisory"
 	"github.com/uzumaki-inc/stay_or_go/analyzer"
 	"github.com/uzumaki-inc/stay_or_go/parser"
 	"github.com/uzumaki-inc/stay_or_go/utils"
 )
 
 type AnalyzedLibInfo struct {
-	LibInfo        *parser.LibInfo
-	GitHubRepoInfo *analyzer.GitHubRepoInfo
+	LibInfo         *parser.LibInfo
+	RepoInfo        *analyzer.RepoInfo
+	Vulnerabilities []advisory.Advisory // nil when not checked against an advisory database
 }
 
 func (ainfo AnalyzedLibInfo) Name() *string {
@@ -23,6 +26,24 @@ func (ainfo AnalyzedLibInfo) Name() *string {
 	return nil
 }
 
+// Direct tells a direct dependency from an indirect one, shown with WithDirect.
+func (ainfo AnalyzedLibInfo) Direct() *bool {
+	direct := !ainfo.LibInfo.Indirect
+
+	return &direct
+}
+
+// UsedBy lists the workspace modules needing the dependency, shown with WithUsedBy.
+func (ainfo AnalyzedLibInfo) UsedBy() *string {
+	if len(ainfo.LibInfo.UsedBy) > 0 {
+		usedBy := strings.Join(ainfo.LibInfo.UsedBy, " ")
+
+		return &usedBy
+	}
+
+	return nil
+}
+
 func (ainfo AnalyzedLibInfo) RepositoryURL() *string {
 	if ainfo.LibInfo.RepositoryURL != "" {
 		return &ainfo.LibInfo.RepositoryURL
@@ -31,65 +52,207 @@ func (ainfo AnalyzedLibInfo) RepositoryURL() *string {
 	return nil
 }
 
+func (ainfo AnalyzedLibInfo) Forge() *string {
+	if ainfo.RepoInfo != nil && ainfo.RepoInfo.Forge != "" {
+		return &ainfo.RepoInfo.Forge
+	}
+
+	return nil
+}
+
 func (ainfo AnalyzedLibInfo) Watchers() *int {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.Watchers
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.Watchers
 	}
 
 	return nil
 }
 
 func (ainfo AnalyzedLibInfo) Stars() *int {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.Stars
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.Stars
 	}
 
 	return nil
 }
 
 func (ainfo AnalyzedLibInfo) Forks() *int {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.Forks
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.Forks
 	}
 
 	return nil
 }
 
 func (ainfo AnalyzedLibInfo) OpenIssues() *int {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.OpenIssues
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.OpenIssues
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) OpenPullRequests() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.OpenPullRequests
 	}
 
 	return nil
 }
 
 func (ainfo AnalyzedLibInfo) LastCommitDate() *string {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.LastCommitDate
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.LastCommitDate
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) LastReleaseDate() *string {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.LastReleaseDate
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) ReleasesLastYear() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.ReleasesLastYear
 	}
 
 	return nil
 }
 
-func (ainfo AnalyzedLibInfo) GithubRepoURL() *string {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.GithubRepoURL
+func (ainfo AnalyzedLibInfo) MedianReleaseGapDays() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.MedianReleaseGapDays
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) CommittersLast90Days() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.CommittersLast90Days
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) CommittersLastYear() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.CommittersLastYear
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) TopContributorShare() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.TopContributorShare
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) MedianIssueResponseHours() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.MedianIssueResponseHours
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) MedianMergeDays() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.MedianMergeDays
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) IssueCloseRatio() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.IssueCloseRatio
+	}
+
+	return nil
+}
+
+func (ainfo AnalyzedLibInfo) ActiveWeeksLastYear() *int {
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.ActiveWeeksLastYear
+	}
+
+	return nil
+}
+
+// CommitActivity draws the weekly commits of the last year, shown with WithSparkline.
+func (ainfo AnalyzedLibInfo) CommitActivity() *string {
+	if ainfo.RepoInfo != nil && ainfo.RepoInfo.WeeklyCommits != nil {
+		return analyzer.Ptr(sparkline(ainfo.RepoInfo.WeeklyCommits))
 	}
 
 	return nil
 }
 
 func (ainfo AnalyzedLibInfo) Archived() *bool {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.Archived
+	if ainfo.RepoInfo != nil {
+		return ainfo.RepoInfo.Archived
+	}
+
+	return nil
+}
+
+// UnfixedVulnerabilities counts the advisories affecting the version in use, shown with WithAdvisories.
+func (ainfo AnalyzedLibInfo) UnfixedVulnerabilities() *int {
+	if ainfo.Vulnerabilities != nil {
+		return analyzer.Ptr(len(ainfo.Vulnerabilities))
+	}
+
+	return nil
+}
+
+// Advisories lists the advisories affecting the version in use, e.g. "GHSA-xxxx-xxxx-xxxx (HIGH; fixed in 1.2.4)".
+func (ainfo AnalyzedLibInfo) Advisories() *string {
+	if ainfo.Vulnerabilities == nil {
+		return nil
+	}
+
+	entries := make([]string, len(ainfo.Vulnerabilities))
+
+	for i, vulnerability := range ainfo.Vulnerabilities {
+		fix := "no fix"
+		if len(vulnerability.FixedVersions) > 0 {
+			fix = "fixed in " + strings.Join(vulnerability.FixedVersions, "/")
+		}
+
+		if vulnerability.Severity != "" {
+			fix = vulnerability.Severity + "; " + fix
+		}
+
+		entries[i] = vulnerability.ID + " (" + fix + ")"
+	}
+
+	advisories := strings.Join(entries, " ")
+
+	return &advisories
+}
+
+// Incomplete tells why metrics are unknown this time, shown with WithIncomplete.
+func (ainfo AnalyzedLibInfo) Incomplete() *string {
+	if ainfo.RepoInfo != nil {
+		return analyzer.Ptr(strings.Join(ainfo.RepoInfo.Incomplete, "; "))
 	}
 
 	return nil
 }
 
+// Score is unknown for incomplete repositories, which CalcScore leaves unscored.
 func (ainfo AnalyzedLibInfo) Score() *int {
-	if ainfo.GitHubRepoInfo != nil {
-		return &ainfo.GitHubRepoInfo.Score
+	if ainfo.RepoInfo != nil && len(ainfo.RepoInfo.Incomplete) == 0 {
+		return &ainfo.RepoInfo.Score
 	}
 
 	return nil
@@ -101,7 +264,7 @@ func (ainfo AnalyzedLibInfo) Skip() *bool {
 
 	if ainfo.LibInfo.Skip {
 		return &trueValue
-	} else if ainfo.GitHubRepoInfo.Skip {
+	} else if ainfo.RepoInfo.Skip {
 		return &trueValue
 	}
 
@@ -111,8 +274,8 @@ func (ainfo AnalyzedLibInfo) Skip() *bool {
 func (ainfo AnalyzedLibInfo) SkipReason() *string {
 	if ainfo.LibInfo.Skip {
 		return &ainfo.LibInfo.SkipReason
-	} else if ainfo.GitHubRepoInfo.Skip {
-		return &ainfo.GitHubRepoInfo.SkipReason
+	} else if ainfo.RepoInfo.Skip {
+		return &ainfo.RepoInfo.SkipReason
 	}
 
 	return nil
@@ -120,7 +283,7 @@ func (ainfo AnalyzedLibInfo) SkipReason() *string {
 
 func MakeAnalyzedLibInfoList(
 	libInfoList []parser.LibInfo,
-	gitHubRepoInfos []analyzer.GitHubRepoInfo,
+	repoInfos []analyzer.RepoInfo,
 ) []AnalyzedLibInfo {
 	analyzedLibInfos := make([]AnalyzedLibInfo, 0, len(libInfoList))
 
@@ -128,12 +291,12 @@ func MakeAnalyzedLibInfoList(
 
 	for _, info := range libInfoList {
 		analyzedLibInfo := AnalyzedLibInfo{
-			LibInfo:        &info,
-			GitHubRepoInfo: nil,
+			LibInfo:  &info,
+			RepoInfo: nil,
 		}
 
-		if repoIndex < len(gitHubRepoInfos) && info.RepositoryURL == gitHubRepoInfos[repoIndex].GithubRepoURL {
-			analyzedLibInfo.GitHubRepoInfo = &gitHubRepoInfos[repoIndex]
+		if repoIndex < len(repoInfos) && info.RepositoryURL == repoInfos[repoIndex].RepositoryURL {
+			analyzedLibInfo.RepoInfo = &repoInfos[repoIndex]
 			repoIndex++
 		}
 
@@ -162,7 +325,7 @@ func Display(p Presenter) {
 	}
 }
 
-func makeBody(analyzedLibInfos []AnalyzedLibInfo, separator string) []string {
+func makeBody(analyzedLibInfos []AnalyzedLibInfo, headers []string, separator string) []string {
 	rows := []string{}
 
 	for _, info := range analyzedLibInfos {
@@ -173,7 +336,7 @@ func makeBody(analyzedLibInfos []AnalyzedLibInfo, separator string) []string {
 			val = val.Elem()
 		}
 
-		for index, header := range headerString {
+		for index, header := range headers {
 			method := val.MethodByName(header)
 
 			if method.IsValid() {
@@ -189,7 +352,7 @@ func makeBody(analyzedLibInfos []AnalyzedLibInfo, separator string) []string {
 
 				row += fmt.Sprintf("%v", resultStr)
 				// 最後の要素でない場合にのみseparatorを追加
-				if index < len(headerString)-1 {
+				if index < len(headers)-1 {
 					row += separator
 				}
 			} else {
@@ -210,25 +373,44 @@ func makeBody(analyzedLibInfos []AnalyzedLibInfo, separator string) []string {
 
 var headerString = []string{
 	"Name",
+	"Direct", // with WithDirect
+	"UsedBy", // with WithUsedBy
 	"RepositoryURL",
+	"Forge",
 	"Watchers",
 	"Stars",
 	"Forks",
 	"OpenIssues",
+	"OpenPullRequests",
 	"LastCommitDate",
+	"LastReleaseDate",
+	"ReleasesLastYear",
+	"MedianReleaseGapDays",
+	"CommittersLast90Days",
+	"CommittersLastYear",
+	"TopContributorShare",
+	"MedianIssueResponseHours",
+	"MedianMergeDays",
+	"IssueCloseRatio",
+	"ActiveWeeksLastYear",
+	"CommitActivity", // with WithSparkline
 	"Archived",
+	"UnfixedVulnerabilities", // with WithAdvisories, like Advisories
+	"Advisories",
 	"Score",
+	"Incomplete", // with WithIncomplete
 	"Skip",
 	"SkipReason",
 }
 
-func SelectPresenter(format string, analyzedLibInfos []AnalyzedLibInfo) Presenter {
+// SelectPresenter returns the presenter of format with the optional columns of opts.
+func SelectPresenter(format string, analyzedLibInfos []AnalyzedLibInfo, opts ...Option) Presenter {
 	switch format {
 	case "tsv":
-		return TsvPresenter{analyzedLibInfos}
+		return NewTsvPresenter(analyzedLibInfos, opts...)
 	case "csv":
-		return CsvPresenter{analyzedLibInfos}
+		return NewCsvPresenter(analyzedLibInfos, opts...)
 	default:
-		return MarkdownPresenter{analyzedLibInfos}
+		return NewMarkdownPresenter(analyzedLibInfos, opts...)
 	}
 }
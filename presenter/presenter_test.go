@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/uzumaki-inc/stay_or_go/advisory"
 	"github.com/uzumaki-inc/stay_or_go/analyzer"
 	"github.com/uzumaki-inc/stay_or_go/parser"
 	"github.com/uzumaki-inc/stay_or_go/presenter"
@@ -25,34 +26,37 @@ func TestDisplay(t *testing.T) {
 		{
 			name: "MarkDown Presenter",
 			presenterFunc: func(analyzedLibInfos []presenter.AnalyzedLibInfo) presenter.Presenter {
-				return presenter.NewMarkdownPresenter(analyzedLibInfos)
+				return presenter.NewMarkdownPresenter(analyzedLibInfos, presenter.WithDirect(true), presenter.WithUsedBy(true),
+					presenter.WithAdvisories(true))
 			},
 
 			//nolint:lll
-			expectedOutput: "| Name | RepositoryURL | Watchers | Stars | Forks | OpenIssues | LastCommitDate | Archived | Score | Skip | SkipReason |\n" +
-				"| ---- | ------------- | -------- | ----- | ----- | ---------- | -------------- | -------- | ----- | ---- | ---------- |\n" +
-				"|lib1|https://github.com/lib1|100|200|50|10|2023-10-10|false|85|false|N/A|\n" +
-				"|lib2|https://github.com/lib2|150|250|60|15|2023-10-11|false|90|false|N/A|\n",
+			expectedOutput: "| Name | Direct | UsedBy | RepositoryURL | Forge | Watchers | Stars | Forks | OpenIssues | OpenPullRequests | LastCommitDate | LastReleaseDate | ReleasesLastYear | MedianReleaseGapDays | CommittersLast90Days | CommittersLastYear | TopContributorShare | MedianIssueResponseHours | MedianMergeDays | IssueCloseRatio | ActiveWeeksLastYear | Archived | UnfixedVulnerabilities | Advisories | Score | Skip | SkipReason |\n" +
+				"| ---- | ------ | ------ | ------------- | ----- | -------- | ----- | ----- | ---------- | ---------------- | -------------- | --------------- | ---------------- | -------------------- | -------------------- | ------------------ | ------------------- | ------------------------ | --------------- | --------------- | ------------------- | -------- | ---------------------- | ---------- | ----- | ---- | ---------- |\n" +
+				"|lib1|true|N/A|https://github.com/lib1|GitHub|100|200|50|10|3|2023-10-10|2023-09-01|4|30|3|5|62|18|2|90|40|false|1|GO-2024-0001 (HIGH; fixed in v1.2.4)|85|false|N/A|\n" +
+				"|lib2|false|N/A|https://gitlab.com/lib2|GitLab|150|250|60|15|4|2023-10-11|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|false|N/A|N/A|90|false|N/A|\n",
 		},
 		{
 			name: "TSV Presenter",
 			presenterFunc: func(analyzedLibInfos []presenter.AnalyzedLibInfo) presenter.Presenter {
-				return presenter.NewTsvPresenter(analyzedLibInfos)
+				return presenter.NewTsvPresenter(analyzedLibInfos, presenter.WithDirect(true), presenter.WithUsedBy(true),
+					presenter.WithAdvisories(true))
 			},
 			//nolint:lll
-			expectedOutput: "Name\tRepositoryURL\tWatchers\tStars\tForks\tOpenIssues\tLastCommitDate\tArchived\tScore\tSkip\tSkipReason\n" +
-				"lib1\thttps://github.com/lib1\t100\t200\t50\t10\t2023-10-10\tfalse\t85\tfalse\tN/A\n" +
-				"lib2\thttps://github.com/lib2\t150\t250\t60\t15\t2023-10-11\tfalse\t90\tfalse\tN/A\n",
+			expectedOutput: "Name\tDirect\tUsedBy\tRepositoryURL\tForge\tWatchers\tStars\tForks\tOpenIssues\tOpenPullRequests\tLastCommitDate\tLastReleaseDate\tReleasesLastYear\tMedianReleaseGapDays\tCommittersLast90Days\tCommittersLastYear\tTopContributorShare\tMedianIssueResponseHours\tMedianMergeDays\tIssueCloseRatio\tActiveWeeksLastYear\tArchived\tUnfixedVulnerabilities\tAdvisories\tScore\tSkip\tSkipReason\n" +
+				"lib1\ttrue\tN/A\thttps://github.com/lib1\tGitHub\t100\t200\t50\t10\t3\t2023-10-10\t2023-09-01\t4\t30\t3\t5\t62\t18\t2\t90\t40\tfalse\t1\tGO-2024-0001 (HIGH; fixed in v1.2.4)\t85\tfalse\tN/A\n" +
+				"lib2\tfalse\tN/A\thttps://gitlab.com/lib2\tGitLab\t150\t250\t60\t15\t4\t2023-10-11\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tfalse\tN/A\tN/A\t90\tfalse\tN/A\n",
 		},
 		{
 			name: "CSV Presenter",
 			presenterFunc: func(analyzedLibInfos []presenter.AnalyzedLibInfo) presenter.Presenter {
-				return presenter.NewCsvPresenter(analyzedLibInfos)
+				return presenter.NewCsvPresenter(analyzedLibInfos, presenter.WithDirect(true), presenter.WithUsedBy(true),
+					presenter.WithAdvisories(true))
 			},
 			//nolint:lll
-			expectedOutput: "Name, RepositoryURL, Watchers, Stars, Forks, OpenIssues, LastCommitDate, Archived, Score, Skip, SkipReason\n" +
-				"lib1, https://github.com/lib1, 100, 200, 50, 10, 2023-10-10, false, 85, false, N/A\n" +
-				"lib2, https://github.com/lib2, 150, 250, 60, 15, 2023-10-11, false, 90, false, N/A\n",
+			expectedOutput: "Name, Direct, UsedBy, RepositoryURL, Forge, Watchers, Stars, Forks, OpenIssues, OpenPullRequests, LastCommitDate, LastReleaseDate, ReleasesLastYear, MedianReleaseGapDays, CommittersLast90Days, CommittersLastYear, TopContributorShare, MedianIssueResponseHours, MedianMergeDays, IssueCloseRatio, ActiveWeeksLastYear, Archived, UnfixedVulnerabilities, Advisories, Score, Skip, SkipReason\n" +
+				"lib1, true, N/A, https://github.com/lib1, GitHub, 100, 200, 50, 10, 3, 2023-10-10, 2023-09-01, 4, 30, 3, 5, 62, 18, 2, 90, 40, false, 1, GO-2024-0001 (HIGH; fixed in v1.2.4), 85, false, N/A\n" +
+				"lib2, false, N/A, https://gitlab.com/lib2, GitLab, 150, 250, 60, 15, 4, 2023-10-11, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, false, N/A, N/A, 90, false, N/A\n",
 		},
 	}
 
@@ -60,19 +64,29 @@ func TestDisplay(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			libInfo1 := parser.LibInfo{Name: "lib1", RepositoryURL: "https://github.com/lib1"}
-			repoInfo1 := analyzer.GitHubRepoInfo{
-				RepositoryName: "lib1", Watchers: 100, Stars: 200, Forks: 50,
-				OpenIssues: 10, LastCommitDate: "2023-10-10", Archived: false, Score: 85,
+			repoInfo1 := analyzer.RepoInfo{
+				RepositoryName: "lib1", Forge: analyzer.ForgeGitHub,
+				Watchers: analyzer.Ptr(100), Stars: analyzer.Ptr(200), Forks: analyzer.Ptr(50),
+				OpenIssues: analyzer.Ptr(10), OpenPullRequests: analyzer.Ptr(3), LastCommitDate: analyzer.Ptr("2023-10-10"),
+				LastReleaseDate: analyzer.Ptr("2023-09-01"), ReleasesLastYear: analyzer.Ptr(4),
+				MedianReleaseGapDays: analyzer.Ptr(30), CommittersLast90Days: analyzer.Ptr(3),
+				CommittersLastYear: analyzer.Ptr(5), TopContributorShare: analyzer.Ptr(62),
+				MedianIssueResponseHours: analyzer.Ptr(18), MedianMergeDays: analyzer.Ptr(2), IssueCloseRatio: analyzer.Ptr(90),
+				ActiveWeeksLastYear: analyzer.Ptr(40), Archived: analyzer.Ptr(false), Score: 85,
 			}
-			libInfo2 := parser.LibInfo{Name: "lib2", RepositoryURL: "https://github.com/lib2"}
-			repoInfo2 := analyzer.GitHubRepoInfo{
-				RepositoryName: "lib2", Watchers: 150, Stars: 250, Forks: 60,
-				OpenIssues: 15, LastCommitDate: "2023-10-11", Archived: false, Score: 90,
+			libInfo2 := parser.LibInfo{Name: "lib2", RepositoryURL: "https://gitlab.com/lib2", Indirect: true}
+			repoInfo2 := analyzer.RepoInfo{
+				RepositoryName: "lib2", Forge: analyzer.ForgeGitLab,
+				Watchers: analyzer.Ptr(150), Stars: analyzer.Ptr(250), Forks: analyzer.Ptr(60),
+				OpenIssues: analyzer.Ptr(15), OpenPullRequests: analyzer.Ptr(4), LastCommitDate: analyzer.Ptr("2023-10-11"),
+				Archived: analyzer.Ptr(false), Score: 90,
 			}
 
 			analyzedLibInfos := []presenter.AnalyzedLibInfo{
-				{LibInfo: &libInfo1, GitHubRepoInfo: &repoInfo1},
-				{LibInfo: &libInfo2, GitHubRepoInfo: &repoInfo2},
+				{LibInfo: &libInfo1, RepoInfo: &repoInfo1, Vulnerabilities: []advisory.Advisory{
+					{ID: "GO-2024-0001", Severity: "HIGH", FixedVersions: []string{"v1.2.4"}},
+				}},
+				{LibInfo: &libInfo2, RepoInfo: &repoInfo2, Vulnerabilities: nil},
 			}
 
 			presenter := testCase.presenterFunc(analyzedLibInfos)
@@ -11,7 +11,7 @@ import (
 	"github.com/uzumaki-inc/stay_or_go/presenter"
 )
 
-// Verify presenters when GitHubRepoInfo is missing and LibInfo is skipped.
+// Verify presenters when RepoInfo is missing and LibInfo is skipped.
 func getTestCases() []struct {
 	name           string
 	presenterFunc  func([]presenter.AnalyzedLibInfo) presenter.Presenter
@@ -27,11 +27,16 @@ func getTestCases() []struct {
 			presenterFunc: func(infos []presenter.AnalyzedLibInfo) presenter.Presenter {
 				return presenter.NewMarkdownPresenter(infos)
 			},
-			expectedOutput: `| Name | RepositoryURL | Watchers | Stars | Forks | OpenIssues | ` +
-				`LastCommitDate | Archived | Score | Skip | SkipReason |
-| ---- | ------------- | -------- | ----- | ----- | ---------- | ` +
-				`-------------- | -------- | ----- | ---- | ---------- |
-|libX|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|true|Not hosted on Github|
+			expectedOutput: `| Name | RepositoryURL | Forge | Watchers | Stars | Forks | OpenIssues | ` +
+				`OpenPullRequests | LastCommitDate | LastReleaseDate | ReleasesLastYear | MedianReleaseGapDays | ` +
+				`CommittersLast90Days | CommittersLastYear | TopContributorShare | MedianIssueResponseHours | ` +
+				`MedianMergeDays | IssueCloseRatio | ActiveWeeksLastYear | Archived | Score | Skip | SkipReason |
+| ---- | ------------- | ----- | -------- | ----- | ----- | ---------- | ` +
+				`---------------- | -------------- | --------------- | ---------------- | -------------------- | ` +
+				`-------------------- | ------------------ | ------------------- | ------------------------ | ` +
+				`--------------- | --------------- | ------------------- | -------- | ----- | ---- | ---------- |
+|libX|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|` +
+				`N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|N/A|true|Not hosted on Github|
 `,
 		},
 		{
@@ -40,9 +45,12 @@ func getTestCases() []struct {
 				return presenter.NewCsvPresenter(infos)
 			},
 			//nolint:dupword // N/A repetition is expected output format
-			expectedOutput: "Name, RepositoryURL, Watchers, Stars, Forks, OpenIssues, " +
-				"LastCommitDate, Archived, Score, Skip, SkipReason\n" +
-				"libX, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, true, Not hosted on Github\n",
+			expectedOutput: "Name, RepositoryURL, Forge, Watchers, Stars, Forks, OpenIssues, " +
+				"OpenPullRequests, LastCommitDate, LastReleaseDate, ReleasesLastYear, MedianReleaseGapDays, " +
+				"CommittersLast90Days, CommittersLastYear, TopContributorShare, MedianIssueResponseHours, " +
+				"MedianMergeDays, IssueCloseRatio, ActiveWeeksLastYear, Archived, Score, Skip, SkipReason\n" +
+				"libX, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, N/A, " +
+				"N/A, N/A, N/A, N/A, N/A, true, Not hosted on Github\n",
 		},
 		{
 			name: "TSV",
@@ -50,9 +58,12 @@ func getTestCases() []struct {
 				return presenter.NewTsvPresenter(infos)
 			},
 			//nolint:dupword // N/A repetition is expected output format
-			expectedOutput: "Name\tRepositoryURL\tWatchers\tStars\tForks\tOpenIssues\t" +
-				"LastCommitDate\tArchived\tScore\tSkip\tSkipReason\n" +
-				"libX\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\ttrue\tNot hosted on Github\n",
+			expectedOutput: "Name\tRepositoryURL\tForge\tWatchers\tStars\tForks\tOpenIssues\t" +
+				"OpenPullRequests\tLastCommitDate\tLastReleaseDate\tReleasesLastYear\tMedianReleaseGapDays\t" +
+				"CommittersLast90Days\tCommittersLastYear\tTopContributorShare\tMedianIssueResponseHours\t" +
+				"MedianMergeDays\tIssueCloseRatio\tActiveWeeksLastYear\tArchived\tScore\tSkip\tSkipReason\n" +
+				"libX\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\tN/A\t" +
+				"N/A\tN/A\tN/A\tN/A\tN/A\ttrue\tNot hosted on Github\n",
 		},
 	}
 }
@@ -84,7 +95,7 @@ func TestPresenters_WithSkippedLibInfoAndMissingRepoInfo(t *testing.T) {
 		SkipReason:    "Not hosted on Github",
 	}
 
-	analyzed := []presenter.AnalyzedLibInfo{{LibInfo: &lib, GitHubRepoInfo: nil}}
+	analyzed := []presenter.AnalyzedLibInfo{{LibInfo: &lib, RepoInfo: nil}}
 	cases := getTestCases()
 
 	for _, testCase := range cases {
@@ -0,0 +1,45 @@
+package presenter
+
+import (
+	"slices"
+	"strings"
+)
+
+const weeksPerBar = 4 // 52 weeks draw as 13 bars
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws weekly commit counts as bars of weeksPerBar weeks each, scaled to the busiest bar.
+// Only bars without any commit are drawn at the lowest height.
+func sparkline(weeklyCommits []int) string {
+	var sums []int
+
+	for chunk := range slices.Chunk(weeklyCommits, weeksPerBar) {
+		sum := 0
+		for _, commits := range chunk {
+			sum += commits
+		}
+
+		sums = append(sums, sum)
+	}
+
+	if len(sums) == 0 {
+		return ""
+	}
+
+	highest := slices.Max(sums)
+	top := len(sparkBars) - 1
+
+	var line strings.Builder
+
+	for _, sum := range sums {
+		level := 0
+		if highest > 0 {
+			level = (sum*top + highest - 1) / highest // rounded up, so one commit shows
+		}
+
+		line.WriteRune(sparkBars[level])
+	}
+
+	return line.String()
+}
@@ -0,0 +1,47 @@
+//nolint:testpackage // Tests unexported methods
+package presenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzumaki-inc/stay_or_go/analyzer"
+	"github.com/uzumaki-inc/stay_or_go/parser"
+)
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	weeklyCommits := make([]int, 52)
+	weeklyCommits[0] = 1   // a single commit in the first four weeks still shows
+	weeklyCommits[48] = 20 // the busiest bar is the last
+	weeklyCommits[49] = 8
+	weeklyCommits[25] = 14
+
+	assert.Equal(t, "▂▁▁▁▁▁▅▁▁▁▁▁█", sparkline(weeklyCommits))
+	assert.Equal(t, "▁▁", sparkline(make([]int, 8)))
+	assert.Empty(t, sparkline(nil))
+}
+
+func TestWithSparkline_AddsColumnToMarkdownAndTsv(t *testing.T) {
+	t.Parallel()
+
+	infos := []AnalyzedLibInfo{{
+		LibInfo:  &parser.LibInfo{Name: "lib"},
+		RepoInfo: &analyzer.RepoInfo{ActiveWeeksLastYear: analyzer.Ptr(2), WeeklyCommits: []int{0, 0, 0, 0, 3, 1}},
+	}}
+
+	markdown := SelectPresenter("markdown", infos, WithSparkline(true))
+	assert.Contains(t, markdown.makeHeader()[0], "| ActiveWeeksLastYear | CommitActivity | Archived |")
+	assert.Contains(t, markdown.makeBody()[0], "|2|▁█|N/A|")
+
+	tsv := SelectPresenter("tsv", infos, WithSparkline(true))
+	assert.Contains(t, tsv.makeHeader()[0], "ActiveWeeksLastYear\tCommitActivity\tArchived")
+
+	csv := SelectPresenter("csv", infos, WithSparkline(true))
+	assert.NotContains(t, csv.makeHeader()[0], "CommitActivity")
+
+	plain := SelectPresenter("markdown", infos)
+	assert.NotContains(t, plain.makeHeader()[0], "CommitActivity")
+}
@@ -6,10 +6,11 @@ import (
 
 type TsvPresenter struct {
 	analyzedLibInfos []AnalyzedLibInfo
+	headers          []string
 }
 
-func NewTsvPresenter(infos []AnalyzedLibInfo) TsvPresenter {
-	return TsvPresenter{analyzedLibInfos: infos}
+func NewTsvPresenter(infos []AnalyzedLibInfo, opts ...Option) TsvPresenter {
+	return TsvPresenter{analyzedLibInfos: infos, headers: newOptions(opts).headers()}
 }
 
 func (p TsvPresenter) Display() {
@@ -17,11 +18,11 @@ func (p TsvPresenter) Display() {
 }
 
 func (p TsvPresenter) makeHeader() []string {
-	headerRow := strings.Join(headerString, "\t")
+	headerRow := strings.Join(p.headers, "\t")
 
 	return []string{headerRow}
 }
 
 func (p TsvPresenter) makeBody() []string {
-	return makeBody(p.analyzedLibInfos, "\t")
+	return makeBody(p.analyzedLibInfos, p.headers, "\t")
 }
@@ -0,0 +1,460 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheSource groups cached responses by the service they come from, each with its own TTL.
+type CacheSource string
+
+const (
+	CacheSourceGitHub    CacheSource = "github"
+	CacheSourceGitLab    CacheSource = "gitlab"
+	CacheSourceBitbucket CacheSource = "bitbucket"
+	CacheSourceGitea     CacheSource = "gitea"
+	CacheSourceSourceHut CacheSource = "sourcehut"
+	CacheSourceGoProxy   CacheSource = "goproxy"
+	CacheSourceRubyGems  CacheSource = "rubygems"
+	CacheSourceGoImport  CacheSource = "goimport" // go-import meta tags of vanity import paths
+
+	cacheDirName  = "stay_or_go"
+	cacheDirPerm  = 0o755
+	hoursOfDay    = 24
+	cacheFileExt  = ".json"
+	cacheTTLSplit = "="
+	snapshotPerm  = 0o644
+)
+
+var (
+	ErrInvalidCacheTTL      = errors.New("invalid cache TTL")
+	ErrNotInOfflineSnapshot = errors.New("not in offline snapshot")
+	ErrInvalidSnapshot      = errors.New("invalid snapshot")
+
+	// pagination headers describe the body, so they are kept with it
+	cachedHeaders = []string{"Link", "X-Total", "X-Total-Pages"}
+)
+
+// DefaultCacheTTLs are how long a response is used without asking the server again.
+// A module version's .info never changes, repository metrics do every day.
+func DefaultCacheTTLs() map[CacheSource]time.Duration {
+	return map[CacheSource]time.Duration{
+		CacheSourceGitHub:    hoursOfDay * time.Hour,
+		CacheSourceGitLab:    hoursOfDay * time.Hour,
+		CacheSourceBitbucket: hoursOfDay * time.Hour,
+		CacheSourceGitea:     hoursOfDay * time.Hour,
+		CacheSourceSourceHut: hoursOfDay * time.Hour,
+		CacheSourceGoProxy:   30 * hoursOfDay * time.Hour,
+		CacheSourceRubyGems:  7 * hoursOfDay * time.Hour,
+		CacheSourceGoImport:  7 * hoursOfDay * time.Hour,
+	}
+}
+
+// Cache keeps successful GET responses on disk, one file per URL under <dir>/<source>/.
+// A nil *Cache disables caching.
+//
+// An offline cache never goes to the network: every entry is used whatever its age, and a
+// request without an entry fails with ErrNotInOfflineSnapshot. Its entries come from the
+// cache directory or from a snapshot file written by WriteSnapshot.
+type Cache struct {
+	dir     string
+	ttls    map[CacheSource]time.Duration
+	now     func() time.Time
+	offline bool
+
+	mu       sync.Mutex
+	snapshot map[string]*cacheEntry // entries of a loaded snapshot, by entryKey
+	recorded map[string]*cacheEntry // responses used in this run, when recording
+}
+
+type cacheEntry struct {
+	Source   CacheSource `json:"source,omitempty"`
+	URL      string      `json:"url"`
+	ETag     string      `json:"etag,omitempty"`
+	StoredAt time.Time   `json:"stored_at"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body"`
+}
+
+// snapshotFile is the format of --snapshot and export-snapshot.
+type snapshotFile struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Entries   []*cacheEntry `json:"entries"`
+}
+
+// NewCache returns a cache in dir. An empty dir keeps nothing on disk, which is still
+// useful to record a snapshot.
+func NewCache(dir string, ttls map[CacheSource]time.Duration) *Cache {
+	return &Cache{dir: dir, ttls: ttls, now: time.Now, offline: false, snapshot: nil, recorded: nil}
+}
+
+// NewOfflineCache answers only from the entries already in dir.
+func NewOfflineCache(dir string) *Cache {
+	cache := NewCache(dir, DefaultCacheTTLs())
+	cache.offline = true
+
+	return cache
+}
+
+// LoadSnapshot returns an offline cache holding the entries of a snapshot file.
+func LoadSnapshot(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var file snapshotFile
+
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSnapshot, path, err)
+	}
+
+	cache := NewOfflineCache("")
+	cache.snapshot = map[string]*cacheEntry{}
+
+	for _, entry := range file.Entries {
+		cache.snapshot[entryKey(entry.Source, entry.URL)] = entry
+	}
+
+	DebugPrintln(fmt.Sprintf("Loaded %d responses from snapshot %s created at %s",
+		len(file.Entries), path, file.CreatedAt.Format(time.RFC3339)))
+
+	return cache, nil
+}
+
+// Offline tells whether requests are answered without the network.
+func (c *Cache) Offline() bool {
+	return c != nil && c.offline
+}
+
+// Record keeps every response used from now on, for WriteSnapshot.
+func (c *Cache) Record() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recorded = map[string]*cacheEntry{}
+}
+
+// WriteSnapshot writes the recorded responses to path and returns how many there were.
+func (c *Cache) WriteSnapshot(path string) (int, error) {
+	c.mu.Lock()
+
+	file := snapshotFile{CreatedAt: c.now().UTC(), Entries: make([]*cacheEntry, 0, len(c.recorded))}
+	for _, entry := range c.recorded {
+		file.Entries = append(file.Entries, entry)
+	}
+
+	c.mu.Unlock()
+
+	// a stable order keeps snapshots of the same dependencies diffable
+	slices.SortFunc(file.Entries, func(a, b *cacheEntry) int {
+		return strings.Compare(entryKey(a.Source, a.URL), entryKey(b.Source, b.URL))
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	err = os.WriteFile(path, data, snapshotPerm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return len(file.Entries), nil
+}
+
+func entryKey(source CacheSource, url string) string {
+	return string(source) + " " + url
+}
+
+func (c *Cache) record(source CacheSource, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.recorded != nil {
+		entry.Source = source
+		c.recorded[entryKey(source, entry.URL)] = entry
+	}
+}
+
+// DefaultCacheDir returns $XDG_CACHE_HOME/stay_or_go, falling back to the user cache directory of the OS.
+func DefaultCacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the cache directory: %w", err)
+		}
+	}
+
+	return filepath.Join(base, cacheDirName), nil
+}
+
+// ParseCacheTTLs reads --cache-ttl: either one duration for every source ("12h"),
+// or comma separated source=duration pairs ("github=6h,rubygems=48h").
+// Sources that are not given keep their default.
+func ParseCacheTTLs(value string) (map[CacheSource]time.Duration, error) {
+	ttls := DefaultCacheTTLs()
+	if value == "" {
+		return ttls, nil
+	}
+
+	if !strings.Contains(value, cacheTTLSplit) {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCacheTTL, err)
+		}
+
+		for source := range ttls {
+			ttls[source] = ttl
+		}
+
+		return ttls, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, duration, _ := strings.Cut(strings.TrimSpace(pair), cacheTTLSplit)
+
+		source := CacheSource(name)
+		if _, ok := ttls[source]; !ok {
+			return nil, fmt.Errorf("%w: unknown source %q", ErrInvalidCacheTTL, name)
+		}
+
+		ttl, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCacheTTL, name, err)
+		}
+
+		ttls[source] = ttl
+	}
+
+	return ttls, nil
+}
+
+// Client returns an HTTP client whose GET requests go through the cache of source.
+// Fresh entries are answered from disk; stale ones are revalidated with If-None-Match,
+// so an unchanged resource costs a 304 that GitHub does not count against the rate limit.
+func (c *Cache) Client(source CacheSource) *http.Client {
+	if c == nil {
+		return &http.Client{}
+	}
+
+	return &http.Client{Transport: &cachingTransport{cache: c, source: source}}
+}
+
+func (c *Cache) path(source CacheSource, url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(c.dir, string(source), hex.EncodeToString(sum[:])+cacheFileExt)
+}
+
+func (c *Cache) load(source CacheSource, url string) (*cacheEntry, bool) {
+	if c.snapshot != nil {
+		entry, ok := c.snapshot[entryKey(source, url)]
+
+		return entry, ok
+	}
+
+	if c.dir == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(source, url))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+
+	err = json.Unmarshal(data, &entry)
+	if err != nil || entry.URL != url {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// store writes to a temporary file first, so concurrent workers never read half an entry.
+func (c *Cache) store(source CacheSource, entry *cacheEntry) error {
+	c.record(source, entry)
+
+	if c.dir == "" {
+		return nil
+	}
+
+	path := c.path(source, entry.URL)
+
+	err := os.MkdirAll(filepath.Dir(path), cacheDirPerm)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	_, err = file.Write(data)
+	closeErr := file.Close()
+
+	if err = errors.Join(err, closeErr); err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache) isFresh(source CacheSource, entry *cacheEntry) bool {
+	return c.now().Sub(entry.StoredAt) < c.ttls[source]
+}
+
+type cachingTransport struct {
+	cache  *Cache
+	source CacheSource
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// http.DefaultTransport is looked up on every request so that tests can swap it
+	base := http.DefaultTransport
+
+	url := req.URL.String()
+
+	if req.Method != http.MethodGet {
+		if t.cache.offline {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotInOfflineSnapshot, req.Method, url)
+		}
+
+		return base.RoundTrip(req) //nolint:wrapcheck // transparent transport
+	}
+
+	entry, found := t.cache.load(t.source, url)
+	if found && (t.cache.offline || t.cache.isFresh(t.source, entry)) {
+		DebugPrintln("Cache hit: " + url)
+		t.cache.record(t.source, entry)
+
+		return entry.response(req, http.Header{}), nil
+	}
+
+	if t.cache.offline {
+		return nil, fmt.Errorf("%w: %s", ErrNotInOfflineSnapshot, url)
+	}
+
+	if found && entry.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // transparent transport
+	}
+
+	switch {
+	case found && resp.StatusCode == http.StatusNotModified:
+		DebugPrintln("Cache revalidated: " + url)
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		entry.StoredAt = t.cache.now()
+		t.save(entry)
+
+		// keep the headers of the 304, they carry the current rate limit quota
+		return entry.response(req, resp.Header), nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response for URL %s: %w", url, err)
+		}
+
+		t.save(&cacheEntry{
+			URL:      url,
+			ETag:     resp.Header.Get("ETag"),
+			StoredAt: t.cache.now(),
+			Header:   keptHeaders(resp.Header),
+			Body:     body,
+		})
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		return resp, nil
+	default:
+		return resp, nil
+	}
+}
+
+// save only logs failures: a cache that cannot be written must not fail the analysis.
+func (t *cachingTransport) save(entry *cacheEntry) {
+	err := t.cache.store(t.source, entry)
+	if err != nil {
+		DebugPrintln("Not caching " + entry.URL + ": " + err.Error())
+	}
+}
+
+func keptHeaders(header http.Header) http.Header {
+	kept := http.Header{}
+
+	for _, key := range cachedHeaders {
+		if value := header.Get(key); value != "" {
+			kept.Set(key, value)
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return kept
+}
+
+func (e *cacheEntry) response(req *http.Request, header http.Header) *http.Response {
+	header = header.Clone()
+
+	for key, values := range e.Header {
+		header[key] = values
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
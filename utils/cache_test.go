@@ -0,0 +1,252 @@
+package utils_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+// etagServer serves a fixed body with an ETag and answers 304 to a matching If-None-Match.
+func etagServer(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	var requests, notModified atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"name": "cached"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests, &notModified
+}
+
+func get(t *testing.T, client *http.Client, url string) (int, string, http.Header) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body), resp.Header
+}
+
+func TestCache_FreshEntryIsServedFromDisk(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := etagServer(t)
+	cache := utils.NewCache(t.TempDir(), utils.DefaultCacheTTLs())
+
+	for range 3 {
+		status, body, _ := get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/repo")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"name": "cached"}`, body)
+	}
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCache_StaleEntryIsRevalidatedWithETag(t *testing.T) {
+	t.Parallel()
+
+	server, requests, notModified := etagServer(t)
+	dir := t.TempDir()
+
+	// a zero TTL revalidates on every request
+	ttls := map[utils.CacheSource]time.Duration{utils.CacheSourceGitHub: 0}
+	client := utils.NewCache(dir, ttls).Client(utils.CacheSourceGitHub)
+
+	get(t, client, server.URL+"/repo")
+
+	status, body, header := get(t, client, server.URL+"/repo")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"name": "cached"}`, body)
+	assert.Equal(t, "4999", header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// the entry is on disk, so another run starts from it
+	status, _, _ = get(t, utils.NewCache(dir, ttls).Client(utils.CacheSourceGitHub), server.URL+"/repo")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int32(2), notModified.Load())
+}
+
+func TestCache_SourcesAndFailuresAreKeptApart(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := etagServer(t)
+	cache := utils.NewCache(t.TempDir(), utils.DefaultCacheTTLs())
+
+	status, _, _ := get(t, cache.Client(utils.CacheSourceRubyGems), server.URL+"/missing")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _, _ = get(t, cache.Client(utils.CacheSourceRubyGems), server.URL+"/missing")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/repo")
+	get(t, cache.Client(utils.CacheSourceGoProxy), server.URL+"/repo")
+
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestCache_NilCacheDoesNotCache(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := etagServer(t)
+
+	var cache *utils.Cache
+
+	get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/repo")
+	get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/repo")
+
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestParseCacheTTLs(t *testing.T) {
+	t.Parallel()
+
+	defaults := utils.DefaultCacheTTLs()
+
+	ttls, err := utils.ParseCacheTTLs("")
+	require.NoError(t, err)
+	assert.Equal(t, defaults, ttls)
+
+	ttls, err = utils.ParseCacheTTLs("1h")
+	require.NoError(t, err)
+
+	for source := range defaults {
+		assert.Equal(t, time.Hour, ttls[source])
+	}
+
+	ttls, err = utils.ParseCacheTTLs("github=6h, rubygems=0s")
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, ttls[utils.CacheSourceGitHub])
+	assert.Equal(t, time.Duration(0), ttls[utils.CacheSourceRubyGems])
+	assert.Equal(t, defaults[utils.CacheSourceGoProxy], ttls[utils.CacheSourceGoProxy])
+
+	for _, value := range []string{"soon", "npm=1h", "github=soon"} {
+		_, err = utils.ParseCacheTTLs(value)
+		require.ErrorIs(t, err, utils.ErrInvalidCacheTTL, value)
+	}
+}
+
+func TestCache_SnapshotAnswersOffline(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := etagServer(t)
+	snapshot := filepath.Join(t.TempDir(), "snapshot.json")
+
+	// record with a fresh disk cache entry and a network answer
+	cache := utils.NewCache(t.TempDir(), utils.DefaultCacheTTLs())
+	get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/cached")
+	cache.Record()
+	get(t, cache.Client(utils.CacheSourceGitHub), server.URL+"/cached")
+	get(t, cache.Client(utils.CacheSourceRubyGems), server.URL+"/gem")
+
+	count, err := cache.WriteSnapshot(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	offline, err := utils.LoadSnapshot(snapshot)
+	require.NoError(t, err)
+	assert.True(t, offline.Offline())
+
+	before := requests.Load()
+
+	status, body, _ := get(t, offline.Client(utils.CacheSourceGitHub), server.URL+"/cached")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"name": "cached"}`, body)
+
+	// entries belong to their source
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, err := http.NewRequestWithContext(t.Context(), method, server.URL+"/gem", nil)
+		require.NoError(t, err)
+
+		_, err = offline.Client(utils.CacheSourceGitHub).Do(req) //nolint:bodyclose // no response on error
+		require.ErrorIs(t, err, utils.ErrNotInOfflineSnapshot, method)
+	}
+
+	assert.Equal(t, before, requests.Load())
+}
+
+func TestCache_OfflineCacheIgnoresTTL(t *testing.T) {
+	t.Parallel()
+
+	server, requests, _ := etagServer(t)
+	dir := t.TempDir()
+
+	ttls := map[utils.CacheSource]time.Duration{utils.CacheSourceGoProxy: 0}
+	get(t, utils.NewCache(dir, ttls).Client(utils.CacheSourceGoProxy), server.URL+"/info")
+
+	status, _, _ := get(t, utils.NewOfflineCache(dir).Client(utils.CacheSourceGoProxy), server.URL+"/info")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int32(1), requests.Load())
+
+	var nilCache *utils.Cache
+	assert.False(t, nilCache.Offline())
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	_, err := utils.LoadSnapshot(path)
+	require.ErrorIs(t, err, utils.ErrInvalidSnapshot)
+
+	_, err = utils.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestCache_KeepsPaginationHeaders(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Total", "12")
+		w.Header().Set("X-Request-Id", "abc")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	get(t, utils.NewCache(dir, utils.DefaultCacheTTLs()).Client(utils.CacheSourceGitLab), server.URL+"/mrs")
+
+	status, _, header := get(t, utils.NewOfflineCache(dir).Client(utils.CacheSourceGitLab), server.URL+"/mrs")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "12", header.Get("X-Total"))
+	assert.Empty(t, header.Get("X-Request-Id"))
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Hosts of the public forges analyzed next to the GitHub hosts.
+const (
+	PublicGitLabHost    = "gitlab.com"
+	PublicBitbucketHost = "bitbucket.org"
+	CodebergHost        = "codeberg.org" // the public Gitea instance
+	SourceHutGitHost    = "git.sr.ht"
+)
+
+// OnHost tells whether a repository URL in any form (https://, git@host:path, git+ssh://...)
+// is on host, matching whole host names like GitHubHosts.Lookup does.
+func OnHost(repoURL, host string) bool {
+	return containsHost(strings.ToLower(repoURL), strings.ToLower(host))
+}
+
+var ownerRepoHosts = struct {
+	sync.RWMutex
+
+	names []string
+}{names: []string{PublicBitbucketHost, CodebergHost, SourceHutGitHost}}
+
+// RegisterForgeHost adds a host whose repository URLs are https://<host>/<owner>/<repo>,
+// like Bitbucket, Codeberg and SourceHut, for the forges registered outside this module.
+func RegisterForgeHost(host string) {
+	host = strings.ToLower(host)
+
+	ownerRepoHosts.Lock()
+	defer ownerRepoHosts.Unlock()
+
+	if !slices.Contains(ownerRepoHosts.names, host) {
+		ownerRepoHosts.names = append(ownerRepoHosts.names, host)
+	}
+}
+
+// OwnerRepoForgeHosts returns the forge hosts other than GitHub and GitLab, whose repository
+// URLs start with https://<host>/<owner>/<repo>.
+func OwnerRepoForgeHosts() []string {
+	ownerRepoHosts.RLock()
+	defer ownerRepoHosts.RUnlock()
+
+	return slices.Clone(ownerRepoHosts.names)
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	PublicGitHubHost   = "github.com"
+	publicGitHubAPIURL = "https://api.github.com"
+	enterpriseAPIPath  = "/api/v3"
+	enterpriseGraphQL  = "/api/graphql"
+)
+
+var ErrInvalidGitHubHost = errors.New("invalid GitHub host")
+
+// GitHubHost is github.com or a GitHub Enterprise Server, with the API and token used for its repositories.
+type GitHubHost struct {
+	Name       string // host name in repository URLs, e.g. github.example.com
+	APIBaseURL string // https://api.github.com, or https://<host>/api/v3 for Enterprise Server
+	Token      string
+}
+
+// GraphQLURL is https://api.github.com/graphql for github.com and <host>/api/graphql for Enterprise Server.
+func (h GitHubHost) GraphQLURL() string {
+	if base, found := strings.CutSuffix(h.APIBaseURL, enterpriseAPIPath); found {
+		return base + enterpriseGraphQL
+	}
+
+	return h.APIBaseURL + "/graphql"
+}
+
+type hostToken struct {
+	pattern string
+	token   string
+}
+
+// GitHubHosts are the hosts whose repositories are analyzed with the GitHub API.
+// github.com is always known; Enterprise Server hosts are added with AddEnterprise.
+// A nil *GitHubHosts knows github.com only, without a token.
+type GitHubHosts struct {
+	hosts  []GitHubHost
+	tokens []hostToken
+}
+
+// NewGitHubHosts knows github.com, authenticated with token.
+func NewGitHubHosts(token string) *GitHubHosts {
+	return &GitHubHosts{
+		hosts:  []GitHubHost{{Name: PublicGitHubHost, APIBaseURL: publicGitHubAPIURL, Token: token}},
+		tokens: nil,
+	}
+}
+
+// AddEnterprise reads a --github-host value: "github.example.com", whose API is
+// https://github.example.com/api/v3, or "github.example.com=https://api.example.com/v3".
+func (h *GitHubHosts) AddEnterprise(spec string) error {
+	name, apiBaseURL, found := strings.Cut(strings.TrimSpace(spec), "=")
+	name = strings.ToLower(strings.TrimSuffix(name, "/"))
+
+	if name == "" || strings.ContainsAny(name, "/:") {
+		return fmt.Errorf("%w: %q", ErrInvalidGitHubHost, spec)
+	}
+
+	if !found {
+		apiBaseURL = "https://" + name + enterpriseAPIPath
+	}
+
+	parsed, err := url.Parse(apiBaseURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("%w: API URL of %s: %q", ErrInvalidGitHubHost, name, apiBaseURL)
+	}
+
+	h.hosts = append(h.hosts, GitHubHost{Name: name, APIBaseURL: strings.TrimSuffix(apiBaseURL, "/"), Token: ""})
+	h.applyTokens()
+
+	return nil
+}
+
+// SetTokens reads comma separated pattern=token pairs. Patterns are matched against host names
+// with path.Match, so "*.example.com=..." covers every Enterprise host under example.com.
+// The first matching pattern wins; hosts matched by none keep their token.
+func (h *GitHubHosts) SetTokens(spec string) error {
+	h.tokens = nil
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		pattern, token, found := strings.Cut(pair, "=")
+		if !found || token == "" {
+			return fmt.Errorf("%w: token mapping %q is not pattern=token", ErrInvalidGitHubHost, pattern)
+		}
+
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("%w: pattern %q: %w", ErrInvalidGitHubHost, pattern, err)
+		}
+
+		h.tokens = append(h.tokens, hostToken{pattern: strings.ToLower(pattern), token: token})
+	}
+
+	h.applyTokens()
+
+	return nil
+}
+
+// HasTokens tells whether any host has a token.
+func (h *GitHubHosts) HasTokens() bool {
+	for _, host := range h.all() {
+		if host.Token != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lookup returns the host a repository URL is on. Any URL form is accepted
+// (https://, git@host:owner/repo, git+ssh://...), like the github.com checks of the parsers.
+func (h *GitHubHosts) Lookup(repoURL string) (GitHubHost, bool) {
+	repoURL = strings.ToLower(repoURL)
+
+	// Enterprise hosts first, so that github.com does not shadow a host like github.com.example.org
+	hosts := h.all()
+	for i := len(hosts) - 1; i >= 0; i-- {
+		if containsHost(repoURL, hosts[i].Name) {
+			return hosts[i], true
+		}
+	}
+
+	return GitHubHost{}, false
+}
+
+// Names returns the host names, Enterprise hosts first like Lookup matches them.
+func (h *GitHubHosts) Names() []string {
+	hosts := h.all()
+	names := make([]string, 0, len(hosts))
+
+	for i := len(hosts) - 1; i >= 0; i-- {
+		names = append(names, hosts[i].Name)
+	}
+
+	return names
+}
+
+func (h *GitHubHosts) all() []GitHubHost {
+	if h == nil {
+		return NewGitHubHosts("").hosts
+	}
+
+	return h.hosts
+}
+
+func (h *GitHubHosts) applyTokens() {
+	for i := range h.hosts {
+		for _, mapping := range h.tokens {
+			if matched, _ := path.Match(mapping.pattern, h.hosts[i].Name); matched {
+				h.hosts[i].Token = mapping.token
+
+				break
+			}
+		}
+	}
+}
+
+// containsHost finds name as a whole host name, preceded by a scheme, a user or the start.
+func containsHost(repoURL, name string) bool {
+	for _, prefix := range []string{"", "//", "@", "."} {
+		index := strings.Index(repoURL, prefix+name)
+		if index < 0 || (prefix == "" && index > 0) {
+			continue
+		}
+
+		end := index + len(prefix) + len(name)
+		if end == len(repoURL) || strings.ContainsRune("/:", rune(repoURL[end])) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+func TestGitHubHosts_AddEnterprise(t *testing.T) {
+	t.Parallel()
+
+	hosts := utils.NewGitHubHosts("public-token")
+	require.NoError(t, hosts.AddEnterprise("GHE.example.com"))
+	require.NoError(t, hosts.AddEnterprise("code.example.org=https://api.code.example.org/v3/"))
+
+	host, ok := hosts.Lookup("https://ghe.example.com/team/lib")
+	require.True(t, ok)
+	assert.Equal(t, "ghe.example.com", host.Name)
+	assert.Equal(t, "https://ghe.example.com/api/v3", host.APIBaseURL)
+	assert.Equal(t, "https://ghe.example.com/api/graphql", host.GraphQLURL())
+	assert.Empty(t, host.Token)
+
+	host, ok = hosts.Lookup("git@code.example.org:team/lib.git")
+	require.True(t, ok)
+	assert.Equal(t, "https://api.code.example.org/v3", host.APIBaseURL)
+
+	host, ok = hosts.Lookup("https://github.com/owner/repo")
+	require.True(t, ok)
+	assert.Equal(t, "https://api.github.com", host.APIBaseURL)
+	assert.Equal(t, "https://api.github.com/graphql", host.GraphQLURL())
+	assert.Equal(t, "public-token", host.Token)
+
+	for _, spec := range []string{"", "https://ghe.example.com", "ghe.example.com=not a url"} {
+		require.ErrorIs(t, hosts.AddEnterprise(spec), utils.ErrInvalidGitHubHost, spec)
+	}
+}
+
+func TestGitHubHosts_Lookup(t *testing.T) {
+	t.Parallel()
+
+	hosts := utils.NewGitHubHosts("")
+	require.NoError(t, hosts.AddEnterprise("github.com.example.org"))
+
+	cases := []struct {
+		url  string
+		host string
+	}{
+		{url: "https://github.com/owner/repo", host: "github.com"},
+		{url: "git+ssh://git@github.com/owner/repo.git", host: "github.com"},
+		{url: "github.com/owner/repo", host: "github.com"},
+		{url: "https://www.github.com/owner/repo", host: "github.com"},
+		{url: "https://github.com.example.org/team/lib", host: "github.com.example.org"},
+		{url: "https://notgithub.com/owner/repo", host: ""},
+		{url: "https://gitlab.com/owner/repo", host: ""},
+	}
+
+	for _, testCase := range cases {
+		host, ok := hosts.Lookup(testCase.url)
+		assert.Equal(t, testCase.host != "", ok, testCase.url)
+		assert.Equal(t, testCase.host, host.Name, testCase.url)
+	}
+
+	// a nil registry still knows github.com
+	var none *utils.GitHubHosts
+
+	_, ok := none.Lookup("https://github.com/owner/repo")
+	assert.True(t, ok)
+	assert.False(t, none.HasTokens())
+}
+
+func TestGitHubHosts_SetTokens(t *testing.T) {
+	t.Parallel()
+
+	hosts := utils.NewGitHubHosts("public-token")
+	require.NoError(t, hosts.AddEnterprise("ghe.corp.example.com"))
+	require.NoError(t, hosts.AddEnterprise("ghe.partner.example.net"))
+	require.NoError(t, hosts.SetTokens("*.corp.example.com=corp-token, ghe.*.example.net=partner-token"))
+
+	for url, token := range map[string]string{
+		"https://ghe.corp.example.com/team/lib":    "corp-token",
+		"https://ghe.partner.example.net/team/lib": "partner-token",
+		"https://github.com/owner/repo":            "public-token",
+	} {
+		host, ok := hosts.Lookup(url)
+		require.True(t, ok, url)
+		assert.Equal(t, token, host.Token, url)
+	}
+
+	assert.True(t, hosts.HasTokens())
+
+	// hosts added later get the mapped token too
+	require.NoError(t, hosts.AddEnterprise("git.corp.example.com"))
+
+	host, _ := hosts.Lookup("https://git.corp.example.com/team/lib")
+	assert.Equal(t, "corp-token", host.Token)
+
+	require.ErrorIs(t, hosts.SetTokens("ghe.corp.example.com"), utils.ErrInvalidGitHubHost)
+	require.ErrorIs(t, hosts.SetTokens("[=token"), utils.ErrInvalidGitHubHost)
+}
+
+func TestOnHost(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, utils.OnHost("https://gitlab.com/group/sub/project", utils.PublicGitLabHost))
+	assert.True(t, utils.OnHost("git@GitLab.com:group/project.git", utils.PublicGitLabHost))
+	assert.False(t, utils.OnHost("https://gitlab.com.example.org/group/project", utils.PublicGitLabHost))
+	assert.False(t, utils.OnHost("https://github.com/gitlab.com/project", utils.PublicGitLabHost))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ForEachIndex calls fn for every index in [0, count) with at most `workers` calls running
+// at the same time. Each index is handled once, so fn can write to its own slot of a
+// pre-sized slice and the results keep the input order.
+// Once ctx is done no further index is started; the error of ctx is returned in that case.
+func ForEachIndex(ctx context.Context, workers, count int, fn func(index int)) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+
+	var waitGroup sync.WaitGroup
+
+	for range min(workers, count) {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+
+	err := feedIndexes(ctx, indexes, count)
+
+	close(indexes)
+	waitGroup.Wait()
+
+	return err
+}
+
+func feedIndexes(ctx context.Context, indexes chan<- int, count int) error {
+	for index := range count {
+		// select picks randomly when both cases are ready, so check ctx first
+		if ctx.Err() != nil {
+			return fmt.Errorf("stopped after %d of %d: %w", index, count, ctx.Err())
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped after %d of %d: %w", index, count, ctx.Err())
+		case indexes <- index:
+		}
+	}
+
+	return nil
+}
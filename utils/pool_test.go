@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uzumaki-inc/stay_or_go/utils"
+)
+
+func TestForEachIndex_KeepsOrderAndBoundsWorkers(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+
+	results := make([]int, 20)
+
+	err := utils.ForEachIndex(context.Background(), 3, len(results), func(index int) {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		results[index] = index * index
+	})
+	require.NoError(t, err)
+
+	for i, result := range results {
+		assert.Equal(t, i*i, result)
+	}
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestForEachIndex_StopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+
+	err := utils.ForEachIndex(ctx, 2, 100, func(index int) {
+		calls.Add(1)
+
+		if index == 3 {
+			cancel()
+		}
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, calls.Load(), int32(100))
+}
+
+func TestForEachIndex_NonPositiveWorkers(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	err := utils.ForEachIndex(context.Background(), 0, 5, func(_ int) { calls.Add(1) })
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), calls.Load())
+}